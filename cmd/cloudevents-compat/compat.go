@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	cloudevents "github.com/yafeiaa/protoc-gen-cloudevents-go/proto/cloudevents"
+)
+
+// change is a breaking change of an event between two descriptor sets
+type change struct {
+	// EventType is the event_type of the event in the old descriptor set
+	EventType string
+	// Element is the full name of the changed message, field or enum in the old descriptor set
+	Element protoreflect.FullName
+	Message string
+}
+
+func (c change) String() string {
+	return fmt.Sprintf("%s: %s: %s", c.EventType, c.Element, c.Message)
+}
+
+// wireGroups groups the kinds sharing a wire encoding: a field may change kind within its group
+var wireGroups = map[protoreflect.Kind]int{
+	protoreflect.Int32Kind:    1,
+	protoreflect.Uint32Kind:   1,
+	protoreflect.Int64Kind:    1,
+	protoreflect.Uint64Kind:   1,
+	protoreflect.BoolKind:     1,
+	protoreflect.EnumKind:     1,
+	protoreflect.Sint32Kind:   2,
+	protoreflect.Sint64Kind:   2,
+	protoreflect.Fixed32Kind:  3,
+	protoreflect.Sfixed32Kind: 3,
+	protoreflect.Fixed64Kind:  4,
+	protoreflect.Sfixed64Kind: 4,
+	protoreflect.StringKind:   5,
+	protoreflect.BytesKind:    5,
+}
+
+// jsonGroups groups the kinds sharing a proto3 JSON representation: numbers, quoted 64-bit integers and floats
+var jsonGroups = map[protoreflect.Kind]int{
+	protoreflect.Int32Kind:    1,
+	protoreflect.Sint32Kind:   1,
+	protoreflect.Sfixed32Kind: 1,
+	protoreflect.Uint32Kind:   1,
+	protoreflect.Fixed32Kind:  1,
+	protoreflect.Int64Kind:    2,
+	protoreflect.Sint64Kind:   2,
+	protoreflect.Sfixed64Kind: 2,
+	protoreflect.Uint64Kind:   2,
+	protoreflect.Fixed64Kind:  2,
+	protoreflect.FloatKind:    3,
+	protoreflect.DoubleKind:   3,
+}
+
+// checker compares the events of two descriptor sets
+type checker struct {
+	// wire and json select the encodings whose compatibility is checked
+	wire, json bool
+	changes    []change
+	// compared holds the message pairs already compared, so recursive messages terminate
+	compared map[[2]protoreflect.FullName]bool
+}
+
+// compare reports the breaking changes of the events declared in oldFiles
+func (c *checker) compare(oldFiles, newFiles *protoregistry.Files) []change {
+	c.compared = make(map[[2]protoreflect.FullName]bool)
+	oldEvents, newEvents := events(oldFiles), events(newFiles)
+
+	types := make([]string, 0, len(oldEvents))
+	for eventType := range oldEvents {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+
+	for _, eventType := range types {
+		oldMsg := oldEvents[eventType]
+		newMsg, ok := newEvents[eventType]
+		if !ok {
+			c.report(eventType, oldMsg.FullName(), removedEvent(oldMsg, newFiles))
+			continue
+		}
+		c.message(eventType, oldMsg, newMsg)
+	}
+	return c.changes
+}
+
+// removedEvent explains why the event declared by msg is missing from newFiles
+func removedEvent(msg protoreflect.MessageDescriptor, newFiles *protoregistry.Files) string {
+	desc, err := newFiles.FindDescriptorByName(msg.FullName())
+	if err != nil {
+		return "event removed"
+	}
+	newMsg, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return "event removed"
+	}
+	if meta := eventMeta(newMsg); meta != nil {
+		return fmt.Sprintf("event_type changed to %q", meta.GetEventType())
+	}
+	return "event_meta option removed"
+}
+
+// report records a breaking change, once when both the wire and JSON checks find it
+func (c *checker) report(eventType string, element protoreflect.FullName, format string, args ...any) {
+	ch := change{EventType: eventType, Element: element, Message: fmt.Sprintf(format, args...)}
+	for _, other := range c.changes {
+		if other == ch {
+			return
+		}
+	}
+	c.changes = append(c.changes, ch)
+}
+
+// message compares the fields of two versions of a message
+func (c *checker) message(eventType string, oldMsg, newMsg protoreflect.MessageDescriptor) {
+	key := [2]protoreflect.FullName{oldMsg.FullName(), newMsg.FullName()}
+	if c.compared[key] {
+		return
+	}
+	c.compared[key] = true
+
+	fields := oldMsg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if c.wire {
+			c.wireField(eventType, field, newMsg)
+		}
+		if c.json {
+			c.jsonField(eventType, field, newMsg)
+		}
+	}
+}
+
+// wireField checks that field keeps its number and a wire compatible type in newMsg
+func (c *checker) wireField(eventType string, field protoreflect.FieldDescriptor, newMsg protoreflect.MessageDescriptor) {
+	newField := newMsg.Fields().ByNumber(field.Number())
+	if newField == nil {
+		if renamed := newMsg.Fields().ByName(field.Name()); renamed != nil {
+			c.report(eventType, field.FullName(), "field renumbered from %d to %d", field.Number(), renamed.Number())
+		} else if !newMsg.ReservedRanges().Has(field.Number()) {
+			c.report(eventType, field.FullName(), "field %d removed without reserving its number", field.Number())
+		}
+		return
+	}
+
+	c.sameOneof(eventType, field, newField)
+	if !c.sameCardinality(eventType, field, newField) {
+		return
+	}
+	oldKind, newKind := valueKind(field), valueKind(newField)
+	if oldKind != newKind && (wireGroups[oldKind] == 0 || wireGroups[oldKind] != wireGroups[newKind]) {
+		c.report(eventType, field.FullName(), "field %d changed type from %s to %s (wire incompatible)",
+			field.Number(), typeName(field), typeName(newField))
+		return
+	}
+	if oldKind == protoreflect.MessageKind || oldKind == protoreflect.GroupKind {
+		c.message(eventType, valueField(field).Message(), valueField(newField).Message())
+	}
+}
+
+// jsonField checks that field keeps its JSON name and a JSON compatible type in newMsg
+func (c *checker) jsonField(eventType string, field protoreflect.FieldDescriptor, newMsg protoreflect.MessageDescriptor) {
+	newField := fieldByJSONName(newMsg, field.JSONName())
+	if newField == nil {
+		if renamed := newMsg.Fields().ByNumber(field.Number()); renamed != nil {
+			c.report(eventType, field.FullName(), "field JSON name changed from %q to %q",
+				field.JSONName(), renamed.JSONName())
+		} else if !newMsg.ReservedNames().Has(field.Name()) {
+			c.report(eventType, field.FullName(), "field %q removed without reserving its name", field.Name())
+		}
+		return
+	}
+
+	c.sameOneof(eventType, field, newField)
+	if !c.sameCardinality(eventType, field, newField) {
+		return
+	}
+	oldKind, newKind := valueKind(field), valueKind(newField)
+	if oldKind != newKind && (jsonGroups[oldKind] == 0 || jsonGroups[oldKind] != jsonGroups[newKind]) {
+		c.report(eventType, field.FullName(), "field %q changed type from %s to %s (JSON incompatible)",
+			field.JSONName(), typeName(field), typeName(newField))
+		return
+	}
+	switch oldKind {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		c.message(eventType, valueField(field).Message(), valueField(newField).Message())
+	case protoreflect.EnumKind:
+		// Enum values are encoded by name in JSON
+		oldEnum, newEnum := valueField(field).Enum(), valueField(newField).Enum()
+		values := oldEnum.Values()
+		for i := 0; i < values.Len(); i++ {
+			if v := values.Get(i); newEnum.Values().ByName(v.Name()) == nil {
+				c.report(eventType, v.FullName(), "enum value removed or renamed (JSON incompatible)")
+			}
+		}
+	}
+}
+
+// sameCardinality reports whether field and newField are both singular, repeated or maps, reporting a change otherwise
+func (c *checker) sameCardinality(eventType string, field, newField protoreflect.FieldDescriptor) bool {
+	if field.IsMap() == newField.IsMap() && field.IsList() == newField.IsList() {
+		return true
+	}
+	c.report(eventType, field.FullName(), "field changed from %s to %s", cardinality(field), cardinality(newField))
+	return false
+}
+
+// sameOneof reports fields moving into, out of or between oneofs: setting another member of the new oneof
+// clears the field, and readers of the old schema may keep several members of a oneof set
+func (c *checker) sameOneof(eventType string, field, newField protoreflect.FieldDescriptor) {
+	oldOneof, newOneof := realOneof(field), realOneof(newField)
+	switch {
+	case oldOneof == nil && newOneof == nil:
+	case oldOneof == nil:
+		c.report(eventType, field.FullName(), "field moved into oneof %s", newOneof.Name())
+	case newOneof == nil:
+		c.report(eventType, field.FullName(), "field moved out of oneof %s", oldOneof.Name())
+	case oldOneof.Name() != newOneof.Name():
+		c.report(eventType, field.FullName(), "field moved from oneof %s to oneof %s", oldOneof.Name(), newOneof.Name())
+	}
+}
+
+// events returns the messages of files carrying the event_meta option by event_type
+func events(files *protoregistry.Files) map[string]protoreflect.MessageDescriptor {
+	index := make(map[string]protoreflect.MessageDescriptor)
+	var walk func(msgs protoreflect.MessageDescriptors)
+	walk = func(msgs protoreflect.MessageDescriptors) {
+		for i := 0; i < msgs.Len(); i++ {
+			msg := msgs.Get(i)
+			if meta := eventMeta(msg); meta != nil && meta.GetEventType() != "" {
+				index[meta.GetEventType()] = msg
+			}
+			walk(msg.Messages())
+		}
+	}
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		walk(file.Messages())
+		return true
+	})
+	return index
+}
+
+// eventMeta returns the event_meta option of msg, or nil if the option is absent
+func eventMeta(msg protoreflect.MessageDescriptor) *cloudevents.EventMeta {
+	opts, ok := msg.Options().(*descriptorpb.MessageOptions)
+	if !ok || opts == nil || !proto.HasExtension(opts, cloudevents.E_EventMeta) {
+		return nil
+	}
+	meta, _ := proto.GetExtension(opts, cloudevents.E_EventMeta).(*cloudevents.EventMeta)
+	return meta
+}
+
+func fieldByJSONName(msg protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if field := fields.Get(i); field.JSONName() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// realOneof returns the oneof declaring field, or nil if field is not in a oneof or is a proto3 optional field
+func realOneof(field protoreflect.FieldDescriptor) protoreflect.OneofDescriptor {
+	if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+		return oneof
+	}
+	return nil
+}
+
+// valueField returns the map value field of map fields, field otherwise
+func valueField(field protoreflect.FieldDescriptor) protoreflect.FieldDescriptor {
+	if field.IsMap() {
+		return field.MapValue()
+	}
+	return field
+}
+
+func valueKind(field protoreflect.FieldDescriptor) protoreflect.Kind {
+	return valueField(field).Kind()
+}
+
+// typeName returns the type of a field value as written in a .proto file
+func typeName(field protoreflect.FieldDescriptor) string {
+	field = valueField(field)
+	switch field.Kind() {
+	case protoreflect.EnumKind:
+		return string(field.Enum().FullName())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return string(field.Message().FullName())
+	}
+	return field.Kind().String()
+}
+
+func cardinality(field protoreflect.FieldDescriptor) string {
+	switch {
+	case field.IsMap():
+		return "map"
+	case field.IsList():
+		return "repeated"
+	}
+	return "singular"
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	cloudevents "github.com/yafeiaa/protoc-gen-cloudevents-go/proto/cloudevents"
+)
+
+// compatMainEnv 使测试二进制作为 cloudevents-compat 进程运行
+const compatMainEnv = "CLOUDEVENTS_COMPAT_MAIN"
+
+// TestMain 在设置了 compatMainEnv 时运行 main，供 TestExitStatus 检查进程的退出码
+func TestMain(m *testing.M) {
+	if os.Getenv(compatMainEnv) != "" {
+		main()
+	}
+	os.Exit(m.Run())
+}
+
+// eventsFile 是各测试用例修改的事件 proto 描述符
+const eventsFile = `
+name: "events.proto"
+package: "test"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+message_type {
+  name: "OrderCreated"
+  options { [cloudevents.event_meta] { event_type: "test.order.created" } }
+  field { name: "order_id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field { name: "amount" number: 2 label: LABEL_OPTIONAL type: TYPE_INT64 }
+  field { name: "status" number: 3 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".test.Status" }
+  field { name: "item" number: 4 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".test.Item" }
+  field { name: "tags" number: 5 label: LABEL_REPEATED type: TYPE_STRING }
+  field { name: "card" number: 6 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 0 }
+  field { name: "iban" number: 7 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 0 }
+  field { name: "note" number: 8 label: LABEL_OPTIONAL type: TYPE_STRING }
+  oneof_decl { name: "payment" }
+}
+message_type {
+  name: "OrderCancelled"
+  options { [cloudevents.event_meta] { event_type: "test.order.cancelled" } }
+  field { name: "order_id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+}
+message_type {
+  name: "Item"
+  field { name: "sku" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING }
+  field { name: "quantity" number: 2 label: LABEL_OPTIONAL type: TYPE_INT32 }
+}
+enum_type {
+  name: "Status"
+  value { name: "STATUS_UNSPECIFIED" number: 0 }
+  value { name: "STATUS_PAID" number: 1 }
+}
+`
+
+// newEventsFile 解析 eventsFile 并应用 mutate
+func newEventsFile(t *testing.T, mutate func(*descriptorpb.FileDescriptorProto)) *descriptorpb.FileDescriptorProto {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{}
+	require.NoError(t, prototext.Unmarshal([]byte(eventsFile), file))
+	if mutate != nil {
+		mutate(file)
+	}
+	return file
+}
+
+// newDescriptorSet 返回包含 file 及其依赖的 FileDescriptorSet，与 protoc --include_imports 的输出相同
+func newDescriptorSet(file *descriptorpb.FileDescriptorProto) *descriptorpb.FileDescriptorSet {
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+		protodesc.ToFileDescriptorProto(cloudevents.File_cloudevents_event_meta_proto),
+		file,
+	}}
+}
+
+func newFiles(t *testing.T, file *descriptorpb.FileDescriptorProto) *protoregistry.Files {
+	t.Helper()
+	files, err := protodesc.NewFiles(newDescriptorSet(file))
+	require.NoError(t, err)
+	return files
+}
+
+// writeDescriptorSet 将 file 的 FileDescriptorSet 写入临时文件并返回其路径
+func writeDescriptorSet(t *testing.T, name string, file *descriptorpb.FileDescriptorProto) string {
+	t.Helper()
+	data, err := proto.Marshal(newDescriptorSet(file))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	return path
+}
+
+// message 返回 file 中名为 name 的消息
+func message(file *descriptorpb.FileDescriptorProto, name string) *descriptorpb.DescriptorProto {
+	for _, msg := range file.MessageType {
+		if msg.GetName() == name {
+			return msg
+		}
+	}
+	panic("message not found: " + name)
+}
+
+// field 返回 file 中消息 msg 名为 name 的字段
+func field(file *descriptorpb.FileDescriptorProto, msg, name string) *descriptorpb.FieldDescriptorProto {
+	for _, f := range message(file, msg).Field {
+		if f.GetName() == name {
+			return f
+		}
+	}
+	panic("field not found: " + name)
+}
+
+// removeField 删除 file 中消息 msg 名为 name 的字段
+func removeField(file *descriptorpb.FileDescriptorProto, msg, name string) {
+	m := message(file, msg)
+	for i, f := range m.Field {
+		if f.GetName() == name {
+			m.Field = append(m.Field[:i], m.Field[i+1:]...)
+			return
+		}
+	}
+	panic("field not found: " + name)
+}
+
+// TestCompare 测试各类破坏性变更在 wire、json 与 both 模式下的检测结果
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(file *descriptorpb.FileDescriptorProto)
+		// wire 与 json 是对应模式下的预期变更，both 模式下预期两者去重后的并集
+		wire, json []string
+	}{
+		{
+			name: "unchanged",
+		},
+		{
+			name: "removed field not reserved",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				removeField(file, "OrderCreated", "note")
+			},
+			wire: []string{"test.order.created: test.OrderCreated.note: field 8 removed without reserving its number"},
+			json: []string{`test.order.created: test.OrderCreated.note: field "note" removed without reserving its name`},
+		},
+		{
+			name: "removed field with reserved number and name",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				removeField(file, "OrderCreated", "note")
+				msg := message(file, "OrderCreated")
+				msg.ReservedRange = append(msg.ReservedRange,
+					&descriptorpb.DescriptorProto_ReservedRange{Start: proto.Int32(8), End: proto.Int32(9)})
+				msg.ReservedName = append(msg.ReservedName, "note")
+			},
+		},
+		{
+			name: "removed field with reserved number only",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				removeField(file, "OrderCreated", "note")
+				msg := message(file, "OrderCreated")
+				msg.ReservedRange = append(msg.ReservedRange,
+					&descriptorpb.DescriptorProto_ReservedRange{Start: proto.Int32(8), End: proto.Int32(9)})
+			},
+			json: []string{`test.order.created: test.OrderCreated.note: field "note" removed without reserving its name`},
+		},
+		{
+			name: "renumbered field",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				field(file, "OrderCreated", "order_id").Number = proto.Int32(9)
+			},
+			wire: []string{"test.order.created: test.OrderCreated.order_id: field renumbered from 1 to 9"},
+		},
+		{
+			name: "wire incompatible kind change",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				field(file, "OrderCreated", "amount").Type = descriptorpb.FieldDescriptorProto_TYPE_SINT64.Enum()
+			},
+			wire: []string{"test.order.created: test.OrderCreated.amount: field 2 changed type from int64 to sint64 (wire incompatible)"},
+		},
+		{
+			name: "JSON incompatible kind change",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				field(file, "OrderCreated", "amount").Type = descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
+			},
+			json: []string{`test.order.created: test.OrderCreated.amount: field "amount" changed type from int64 to int32 (JSON incompatible)`},
+		},
+		{
+			name: "wire and JSON incompatible kind change",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				field(file, "OrderCreated", "amount").Type = descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+			},
+			wire: []string{"test.order.created: test.OrderCreated.amount: field 2 changed type from int64 to string (wire incompatible)"},
+			json: []string{`test.order.created: test.OrderCreated.amount: field "amount" changed type from int64 to string (JSON incompatible)`},
+		},
+		{
+			name: "nested message field change",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				field(file, "Item", "quantity").Type = descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()
+			},
+			wire: []string{"test.order.created: test.Item.quantity: field 2 changed type from int32 to string (wire incompatible)"},
+			json: []string{`test.order.created: test.Item.quantity: field "quantity" changed type from int32 to string (JSON incompatible)`},
+		},
+		{
+			name: "cardinality change",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				field(file, "OrderCreated", "tags").Label = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+			},
+			wire: []string{"test.order.created: test.OrderCreated.tags: field changed from repeated to singular"},
+			json: []string{"test.order.created: test.OrderCreated.tags: field changed from repeated to singular"},
+		},
+		{
+			name: "changed event_type",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				opts := message(file, "OrderCancelled").Options
+				proto.SetExtension(opts, cloudevents.E_EventMeta, &cloudevents.EventMeta{EventType: "test.order.canceled"})
+			},
+			wire: []string{`test.order.cancelled: test.OrderCancelled: event_type changed to "test.order.canceled"`},
+			json: []string{`test.order.cancelled: test.OrderCancelled: event_type changed to "test.order.canceled"`},
+		},
+		{
+			name: "removed event_meta option",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				message(file, "OrderCancelled").Options = nil
+			},
+			wire: []string{"test.order.cancelled: test.OrderCancelled: event_meta option removed"},
+			json: []string{"test.order.cancelled: test.OrderCancelled: event_meta option removed"},
+		},
+		{
+			name: "removed event",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				file.MessageType = slices.DeleteFunc(file.MessageType, func(msg *descriptorpb.DescriptorProto) bool {
+					return msg.GetName() == "OrderCancelled"
+				})
+			},
+			wire: []string{"test.order.cancelled: test.OrderCancelled: event removed"},
+			json: []string{"test.order.cancelled: test.OrderCancelled: event removed"},
+		},
+		{
+			name: "changed JSON name",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				field(file, "OrderCreated", "order_id").JsonName = proto.String("id")
+			},
+			json: []string{`test.order.created: test.OrderCreated.order_id: field JSON name changed from "orderId" to "id"`},
+		},
+		{
+			name: "removed enum value",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				file.EnumType[0].Value = file.EnumType[0].Value[:1]
+			},
+			json: []string{"test.order.created: test.STATUS_PAID: enum value removed or renamed (JSON incompatible)"},
+		},
+		{
+			name: "field moved into oneof",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				field(file, "OrderCreated", "note").OneofIndex = proto.Int32(0)
+			},
+			wire: []string{"test.order.created: test.OrderCreated.note: field moved into oneof payment"},
+			json: []string{"test.order.created: test.OrderCreated.note: field moved into oneof payment"},
+		},
+		{
+			name: "field moved out of oneof",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				field(file, "OrderCreated", "iban").OneofIndex = nil
+			},
+			wire: []string{"test.order.created: test.OrderCreated.iban: field moved out of oneof payment"},
+			json: []string{"test.order.created: test.OrderCreated.iban: field moved out of oneof payment"},
+		},
+		{
+			name: "field moved between oneofs",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				msg := message(file, "OrderCreated")
+				msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("bank")})
+				field(file, "OrderCreated", "iban").OneofIndex = proto.Int32(1)
+			},
+			wire: []string{"test.order.created: test.OrderCreated.iban: field moved from oneof payment to oneof bank"},
+			json: []string{"test.order.created: test.OrderCreated.iban: field moved from oneof payment to oneof bank"},
+		},
+		{
+			name: "field made proto3 optional",
+			mutate: func(file *descriptorpb.FileDescriptorProto) {
+				msg := message(file, "OrderCreated")
+				msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{Name: proto.String("_note")})
+				note := field(file, "OrderCreated", "note")
+				note.OneofIndex = proto.Int32(1)
+				note.Proto3Optional = proto.Bool(true)
+			},
+		},
+	}
+
+	oldFiles := newFiles(t, newEventsFile(t, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newFiles := newFiles(t, newEventsFile(t, tt.mutate))
+			for _, mode := range []string{"wire", "json", "both"} {
+				var want []string
+				if modes[mode].wire {
+					want = append(want, tt.wire...)
+				}
+				if modes[mode].json {
+					for _, ch := range tt.json {
+						if !slices.Contains(want, ch) {
+							want = append(want, ch)
+						}
+					}
+				}
+
+				c := &checker{wire: modes[mode].wire, json: modes[mode].json}
+				var got []string
+				for _, ch := range c.compare(oldFiles, newFiles) {
+					got = append(got, ch.String())
+				}
+				assert.ElementsMatch(t, want, got, "mode %s", mode)
+			}
+		})
+	}
+}
+
+// TestRun 测试退出码：兼容为 0，存在破坏性变更为 1，用法或读取错误为 2
+func TestRun(t *testing.T) {
+	oldPath := writeDescriptorSet(t, "old.binpb", newEventsFile(t, nil))
+	compatible := writeDescriptorSet(t, "compatible.binpb", newEventsFile(t, func(file *descriptorpb.FileDescriptorProto) {
+		field(file, "OrderCreated", "order_id").Number = proto.Int32(9)
+	}))
+	breaking := writeDescriptorSet(t, "breaking.binpb", newEventsFile(t, func(file *descriptorpb.FileDescriptorProto) {
+		removeField(file, "OrderCreated", "note")
+	}))
+	invalid := filepath.Join(t.TempDir(), "invalid.binpb")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a descriptor set"), 0o644))
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{name: "compatible", args: []string{oldPath, oldPath}, code: 0},
+		{name: "compatible in json mode", args: []string{"-mode", "json", oldPath, compatible}, code: 0},
+		{name: "breaking in wire mode", args: []string{"-mode", "wire", oldPath, compatible}, code: 1,
+			stdout: "field renumbered from 1 to 9", stderr: "1 breaking change(s)"},
+		{name: "breaking in both modes", args: []string{oldPath, breaking}, code: 1,
+			stdout: "removed without reserving its name", stderr: "2 breaking change(s)"},
+		{name: "unknown mode", args: []string{"-mode", "yaml", oldPath, oldPath}, code: 2, stderr: "usage:"},
+		{name: "unknown flag", args: []string{"-strict", oldPath, oldPath}, code: 2, stderr: "usage:"},
+		{name: "missing argument", args: []string{oldPath}, code: 2, stderr: "usage:"},
+		{name: "missing file", args: []string{oldPath, filepath.Join(t.TempDir(), "missing.binpb")}, code: 2,
+			stderr: "no such file"},
+		{name: "invalid file", args: []string{invalid, oldPath}, code: 2, stderr: "parse descriptor set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tt.code, run(tt.args, &stdout, &stderr), stderr.String())
+			if tt.code == 0 {
+				assert.Empty(t, stdout.String())
+			}
+			assert.Contains(t, stdout.String(), tt.stdout)
+			assert.Contains(t, stderr.String(), tt.stderr)
+		})
+	}
+}
+
+// TestExitStatus 测试进程退出码 2 表示工具本身失败：即使新旧版本不兼容，读取失败时也不报告任何变更
+func TestExitStatus(t *testing.T) {
+	oldPath := writeDescriptorSet(t, "old.binpb", newEventsFile(t, nil))
+	breakingFile := newEventsFile(t, func(file *descriptorpb.FileDescriptorProto) {
+		removeField(file, "OrderCreated", "note")
+	})
+	breaking := writeDescriptorSet(t, "breaking.binpb", breakingFile)
+	// 未使用 --include_imports 写入的描述符集缺少 event_meta.proto
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{breakingFile}})
+	require.NoError(t, err)
+	withoutImports := filepath.Join(t.TempDir(), "without-imports.binpb")
+	require.NoError(t, os.WriteFile(withoutImports, data, 0o644))
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{name: "breaking", args: []string{oldPath, breaking}, code: 1, stderr: "2 breaking change(s)"},
+		{name: "breaking without imports", args: []string{oldPath, withoutImports}, code: 2,
+			stderr: "was it written with --include_imports?"},
+		{name: "breaking with missing old set", args: []string{filepath.Join(t.TempDir(), "missing.binpb"), breaking},
+			code: 2, stderr: "no such file"},
+		{name: "usage", args: []string{"-mode", "yaml", oldPath, breaking}, code: 2, stderr: "usage:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := exec.Command(os.Args[0], tt.args...)
+			cmd.Env = append(os.Environ(), compatMainEnv+"=1")
+			cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+			var exitErr *exec.ExitError
+			require.True(t, errors.As(cmd.Run(), &exitErr), stderr.String())
+			assert.Equal(t, tt.code, exitErr.ExitCode(), stderr.String())
+			assert.Contains(t, stderr.String(), tt.stderr)
+			if tt.code == 2 {
+				assert.Empty(t, stdout.String())
+				assert.NotContains(t, stderr.String(), "breaking change")
+			}
+		})
+	}
+}
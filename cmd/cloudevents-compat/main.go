@@ -0,0 +1,94 @@
+// cloudevents-compat compares two versions of event protos and reports the breaking changes of their events,
+// exiting with status 1 if there are any and 2 if the descriptor sets cannot be read
+//
+// Usage:
+//
+//	protoc --include_imports --descriptor_set_out=new.binpb -I . -I proto events.proto
+//	cloudevents-compat [-mode both|wire|json] old.binpb new.binpb
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// modes maps the supported mode flag values to the checked encodings
+var modes = map[string]struct{ wire, json bool }{
+	"both": {wire: true, json: true},
+	"wire": {wire: true},
+	"json": {json: true},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run compares the descriptor sets named by args and returns the exit status:
+// 0 if the events are compatible, 1 if there are breaking changes and 2 on usage or load errors
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cloudevents-compat", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	mode := flags.String("mode", "both",
+		"compatibility checked: wire (protobuf encoding), json (proto3 JSON encoding) or both")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: %s [flags] OLD NEW\n\n", flags.Name())
+		fmt.Fprintln(stderr,
+			"OLD and NEW are FileDescriptorSets written by protoc --include_imports --descriptor_set_out\n\nFlags:")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	m, ok := modes[*mode]
+	if !ok || flags.NArg() != 2 {
+		flags.Usage()
+		return 2
+	}
+
+	oldFiles, err := loadFiles(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "cloudevents-compat: %v\n", err)
+		return 2
+	}
+	newFiles, err := loadFiles(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(stderr, "cloudevents-compat: %v\n", err)
+		return 2
+	}
+
+	c := &checker{wire: m.wire, json: m.json}
+	changes := c.compare(oldFiles, newFiles)
+	for _, ch := range changes {
+		fmt.Fprintln(stdout, ch)
+	}
+	if len(changes) > 0 {
+		fmt.Fprintf(stderr, "cloudevents-compat: %d breaking change(s)\n", len(changes))
+		return 1
+	}
+	return 0
+}
+
+// loadFiles reads the FileDescriptorSet at path
+func loadFiles(path string) (*protoregistry.Files, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%s: parse descriptor set: %w", path, err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w (was it written with --include_imports?)", path, err)
+	}
+	return files, nil
+}
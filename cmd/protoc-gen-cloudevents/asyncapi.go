@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// asyncAPIVersion is the AsyncAPI specification version of generated documents
+const asyncAPIVersion = "3.0.0"
+
+// cloudEventsContentType is the content type of CloudEvents in structured JSON mode, as sent by the transports
+const cloudEventsContentType = "application/cloudevents+json"
+
+// asyncAPIActions maps the asyncapi_action parameter values to the AsyncAPI operation actions they emit
+var asyncAPIActions = map[string][]string{
+	"send":    {"send"},
+	"receive": {"receive"},
+	"both":    {"send", "receive"},
+}
+
+// generateAsyncAPI emits an AsyncAPI document describing the events declared in file
+func generateAsyncAPI(gen *protogen.Plugin, file *protogen.File, cfg *params, events eventIndex,
+	funcNames map[string]protoreflect.FullName) error {
+	var messages []*messageInfo
+	if err := collectMessages(file.Messages, cfg, events, funcNames, &messages); err != nil {
+		return err
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	title := cfg.AsyncAPITitle
+	if title == "" {
+		title = string(file.Desc.Package())
+	}
+
+	var (
+		schemas    = newSchemaBuilder("#/components/schemas/")
+		channels   = make(schema)
+		operations = make(schema)
+		components = make(schema)
+	)
+	for _, m := range messages {
+		channelName := lowerFirst(m.FuncName)
+		messageRef := "#/channels/" + channelName + "/messages/" + m.FuncName
+
+		channel := schema{
+			"address":  m.Event.EventType,
+			"messages": schema{m.FuncName: schema{"$ref": "#/components/messages/" + m.FuncName}},
+		}
+		if m.Event.Description != "" {
+			channel["description"] = m.Event.Description
+		}
+		if m.Event.Subject != nil {
+			address, parameters := asyncAPIAddress(m.Event.Subject)
+			channel["address"] = address
+			channel["parameters"] = parameters
+		}
+		channels[channelName] = channel
+
+		for _, action := range asyncAPIActions[cfg.AsyncAPIAction] {
+			name := "Publish" + m.FuncName
+			if action == "receive" {
+				name = "Subscribe" + m.FuncName
+			}
+			operations[lowerFirst(name)] = schema{
+				"action":   action,
+				"channel":  schema{"$ref": "#/channels/" + channelName},
+				"messages": []any{schema{"$ref": messageRef}},
+				"summary":  fmt.Sprintf("Generated %s function", name),
+			}
+		}
+
+		msg := schema{
+			"name":        m.Event.EventType,
+			"title":       m.FuncName,
+			"contentType": cloudEventsContentType,
+			"payload":     cloudEventSchema(m, schemas.ref(m.Message)),
+			"traits":      []any{schema{"$ref": "#/components/messageTraits/cloudEvent"}},
+		}
+		if m.Event.Description != "" {
+			msg["summary"] = m.Event.Description
+		}
+		if desc := comment(m.Message.Comments.Leading); desc != "" {
+			msg["description"] = desc
+		}
+		components[m.FuncName] = msg
+	}
+
+	doc := schema{
+		"asyncapi": asyncAPIVersion,
+		"info": schema{
+			"title":   title,
+			"version": cfg.AsyncAPIVersion,
+		},
+		"defaultContentType": cloudEventsContentType,
+		"channels":           channels,
+		"operations":         operations,
+		"components": schema{
+			"messages": components,
+			"schemas":  schemas.defs,
+			"messageTraits": schema{
+				"cloudEvent": schema{
+					"summary":     "CloudEvents 1.0 event in structured JSON mode",
+					"contentType": cloudEventsContentType,
+					"externalDocs": schema{
+						"url": "https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/formats/json-format.md",
+					},
+				},
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%s: marshal asyncapi document: %w", file.Desc.Path(), err)
+	}
+
+	g := gen.NewGeneratedFile(file.GeneratedFilenamePrefix+".asyncapi.json", "")
+	_, err = g.Write(append(data, '\n'))
+	return err
+}
+
+// asyncAPIAddress converts a subject template into a channel address and its parameters,
+// e.g. "myapp.order.created.{currency}.{customer.id}" -> "myapp.order.created.{currency}.{customer_id}"
+func asyncAPIAddress(t *subjectTemplate) (string, schema) {
+	var (
+		tokens     []string
+		parameters = make(schema)
+	)
+	for _, tok := range t.Tokens {
+		if !tok.IsField() {
+			tokens = append(tokens, tok.Literal)
+			continue
+		}
+		name := strings.ReplaceAll(tok.Path, ".", "_")
+		tokens = append(tokens, "{"+name+"}")
+
+		param := schema{}
+		field := tok.Fields[len(tok.Fields)-1]
+		if desc := comment(field.Comments.Leading); desc != "" {
+			param["description"] = desc
+		} else {
+			param["description"] = fmt.Sprintf("Value of the payload field %s", tok.Path)
+		}
+		if field.Enum != nil {
+			var values []any
+			for _, v := range field.Enum.Values {
+				values = append(values, string(v.Desc.Name()))
+			}
+			param["enum"] = values
+		}
+		parameters[name] = param
+	}
+	return strings.Join(tokens, "."), parameters
+}
+
+// cloudEventSchema returns the schema of the CloudEvents structured JSON envelope carrying payload
+func cloudEventSchema(m *messageInfo, payload schema) schema {
+	contentType := codecContentTypes[m.Codec]
+	properties := schema{
+		"specversion":     schema{"type": "string", "const": "1.0"},
+		"id":              schema{"type": "string", "minLength": 1},
+		"source":          schema{"type": "string", "format": "uri-reference", "minLength": 1},
+		"type":            schema{"type": "string", "const": m.Event.EventType},
+		"subject":         schema{"type": "string"},
+		"time":            schema{"type": "string", "format": "date-time"},
+		"datacontenttype": schema{"type": "string", "const": contentType},
+	}
+	if m.Event.DataSchema != "" {
+		properties["dataschema"] = schema{"type": "string", "format": "uri", "const": m.Event.DataSchema}
+	}
+	if m.Event.Version > 0 {
+		properties["dataversion"] = schema{"type": "string", "const": fmt.Sprint(m.Event.Version)}
+	}
+
+	if contentType == "application/protobuf" {
+		properties["data_base64"] = schema{
+			"type":             "string",
+			"contentEncoding":  "base64",
+			"contentMediaType": contentType,
+			"contentSchema":    payload,
+		}
+	} else {
+		properties["data"] = payload
+	}
+
+	return schema{
+		"type":       "object",
+		"required":   []any{"specversion", "id", "source", "type"},
+		"properties": properties,
+	}
+}
+
+// lowerFirst lowercases the first letter of s
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,13 @@
+package main
+
+import "testing"
+
+// TestGenerateAsyncAPI 测试描述符夹具生成的 AsyncAPI 文档与 golden 文件一致
+func TestGenerateAsyncAPI(t *testing.T) {
+	t.Run("send", func(t *testing.T) {
+		assertGolden(t, "mode=asyncapi", "asyncapi")
+	})
+	t.Run("both", func(t *testing.T) {
+		assertGolden(t, "mode=asyncapi,asyncapi_action=both,asyncapi_title=Shop,asyncapi_version=2.1.0", "asyncapi_both")
+	})
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// docTemplate renders the event catalog in one format
+type docTemplate struct {
+	// filename is the default catalog file name
+	filename string
+	tmpl     interface {
+		Execute(w io.Writer, data any) error
+	}
+}
+
+// docTemplates maps the supported doc_format parameter values to their template
+var docTemplates = map[string]docTemplate{
+	"markdown": {filename: "events.md", tmpl: markdownTmpl},
+	"html":     {filename: "events.html", tmpl: htmlTmpl},
+}
+
+// docEvent is the catalog entry of an event
+type docEvent struct {
+	Anchor       string
+	EventType    string
+	Description  string
+	Comment      string
+	Package      string
+	FullName     string
+	File         string
+	GoImportPath string
+	Subject      string
+	PartitionKey string
+	IDFields     []string
+	Version      uint32
+	DataSchema   string
+	ContentType  string
+	Extensions   []string
+	Publish      []string
+	Subscribe    []string
+	Fields       []docField
+}
+
+// docField is a payload field of a catalog entry
+type docField struct {
+	Name        string
+	JSONName    string
+	Type        string
+	Description string
+}
+
+// generateDoc emits a single event catalog covering the events of all files to generate
+func generateDoc(gen *protogen.Plugin, cfg *params, events eventIndex) error {
+	var (
+		entries   []*docEvent
+		funcNames = make(map[protogen.GoImportPath]map[string]protoreflect.FullName)
+	)
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
+		}
+		if funcNames[f.GoImportPath] == nil {
+			funcNames[f.GoImportPath] = make(map[string]protoreflect.FullName)
+		}
+
+		var messages []*messageInfo
+		if err := collectMessages(f.Messages, cfg, events, funcNames[f.GoImportPath], &messages); err != nil {
+			return err
+		}
+		fileExts, err := fileExtensions(f)
+		if err != nil {
+			return err
+		}
+		for _, m := range messages {
+			entries = append(entries, newDocEvent(f, m, cfg, fileExts))
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].EventType < entries[j].EventType })
+
+	format := docTemplates[cfg.DocFormat]
+	filename := cfg.DocFilename
+	if filename == "" {
+		filename = format.filename
+	}
+
+	var buf bytes.Buffer
+	if err := format.tmpl.Execute(&buf, map[string]any{"Events": entries}); err != nil {
+		return fmt.Errorf("execute %s catalog template: %w", cfg.DocFormat, err)
+	}
+
+	g := gen.NewGeneratedFile(filename, "")
+	_, err := g.Write(buf.Bytes())
+	return err
+}
+
+func newDocEvent(file *protogen.File, m *messageInfo, cfg *params, fileExts []*extensionDescriptor) *docEvent {
+	e := &docEvent{
+		Anchor:       strings.ReplaceAll(m.Event.EventType, ".", "-"),
+		EventType:    m.Event.EventType,
+		Description:  m.Event.Description,
+		Comment:      comment(m.Message.Comments.Leading),
+		Package:      string(file.Desc.Package()),
+		FullName:     string(m.Message.Desc.FullName()),
+		File:         file.Desc.Path(),
+		GoImportPath: string(file.GoImportPath),
+		Version:      m.Event.Version,
+		DataSchema:   m.Event.DataSchema,
+		ContentType:  codecContentTypes[m.Codec],
+	}
+	if m.Event.Subject != nil {
+		e.Subject = m.Event.Subject.Raw
+	}
+	if m.Event.PartitionKey != nil {
+		e.PartitionKey = m.Event.PartitionKey.Path
+	}
+	for _, field := range m.Event.IDFields {
+		e.IDFields = append(e.IDFields, field.Path)
+	}
+	e.Publish, e.Subscribe = m.goFuncs(cfg)
+
+	seen := make(map[string]bool)
+	for _, exts := range [][]*extensionDescriptor{fileExts, m.Event.Extensions} {
+		for _, ext := range exts {
+			if seen[ext.Name] {
+				continue
+			}
+			seen[ext.Name] = true
+			desc := fmt.Sprintf("%s (%s", ext.Name, ext.GoType)
+			if ext.Required {
+				desc += ", required"
+			}
+			e.Extensions = append(e.Extensions, desc+")")
+		}
+	}
+	sort.Strings(e.Extensions)
+
+	for _, field := range m.Message.Fields {
+		e.Fields = append(e.Fields, docField{
+			Name:        string(field.Desc.Name()),
+			JSONName:    field.Desc.JSONName(),
+			Type:        fieldTypeName(field),
+			Description: comment(field.Comments.Leading),
+		})
+	}
+	return e
+}
+
+// goFuncs returns the names of the generated Go functions publishing and subscribing to the event
+func (m *messageInfo) goFuncs(cfg *params) (publish, subscribe []string) {
+	publish = []string{"Publish" + m.FuncName}
+	if m.Event.Subject != nil {
+		publish = append(publish, m.FuncName+"Subject")
+	}
+	if m.Event.PartitionKey != nil {
+		publish = append(publish, m.FuncName+"PartitionKey")
+	}
+	if len(m.Event.IDFields) > 0 {
+		publish = append(publish, m.FuncName+"ID")
+	}
+	if m.Event.Reply != nil {
+		publish = append(publish, "Request"+m.FuncName)
+	}
+
+	subscribe = []string{"Subscribe" + m.FuncName}
+	if m.Event.Subject != nil {
+		subscribe = append(subscribe, "Subscribe"+m.FuncName+"Filtered")
+	}
+	subscribe = append(subscribe, "Subscribe"+m.FuncName+"Envelope")
+	if m.Event.Reply != nil {
+		subscribe = append(subscribe, "Handle"+m.FuncName)
+	}
+	if cfg.EmitGroupSubscribers {
+		subscribe = append(subscribe, "Subscribe"+m.FuncName+"WithGroup")
+		if m.Event.Subject != nil {
+			subscribe = append(subscribe, "Subscribe"+m.FuncName+"FilteredWithGroup")
+		}
+		subscribe = append(subscribe, "Subscribe"+m.FuncName+"EnvelopeWithGroup")
+	}
+	return publish, subscribe
+}
+
+// fieldTypeName returns the proto type of field as written in a .proto file
+func fieldTypeName(field *protogen.Field) string {
+	if field.Desc.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", fieldTypeName(field.Message.Fields[0]), fieldTypeName(field.Message.Fields[1]))
+	}
+
+	var name string
+	switch field.Desc.Kind() {
+	case protoreflect.EnumKind:
+		name = string(field.Enum.Desc.FullName())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		name = string(field.Message.Desc.FullName())
+	default:
+		name = field.Desc.Kind().String()
+	}
+
+	switch {
+	case field.Desc.IsList():
+		return "repeated " + name
+	case field.Desc.HasOptionalKeyword():
+		return "optional " + name
+	}
+	return name
+}
+
+var docFuncs = template.FuncMap{
+	// cell escapes a value for use in a Markdown table cell
+	"cell": func(s string) string {
+		s = strings.ReplaceAll(s, "|", `\|`)
+		return strings.ReplaceAll(s, "\n", "<br>")
+	},
+	"code": func(names []string) string {
+		return "`" + strings.Join(names, "`, `") + "`"
+	},
+}
+
+var markdownTmpl = template.Must(template.New("markdown").Funcs(docFuncs).Parse(`<!-- Code generated by protoc-gen-cloudevents. DO NOT EDIT. -->
+
+# Event Catalog
+
+| Event Type | Description | Proto Package | Version |
+|------------|-------------|---------------|---------|
+{{- range .Events }}
+| [` + "`{{ .EventType }}`" + `](#{{ .Anchor }}) | {{ cell .Description }} | ` + "`{{ .Package }}`" + ` | {{ if .Version }}{{ .Version }}{{ else }}-{{ end }} |
+{{- end }}
+{{- range .Events }}
+
+<a id="{{ .Anchor }}"></a>
+
+## {{ .EventType }}
+{{- if .Description }}
+
+{{ .Description }}
+{{- end }}
+{{- if .Comment }}
+
+{{ .Comment }}
+{{- end }}
+
+| Attribute | Value |
+|-----------|-------|
+| Payload | ` + "`{{ .FullName }}`" + ` |
+| Proto File | ` + "`{{ .File }}`" + ` |
+| Proto Package | ` + "`{{ .Package }}`" + ` |
+| Go Package | ` + "`{{ .GoImportPath }}`" + ` |
+| Subject | ` + "`{{ if .Subject }}{{ .Subject }}{{ else }}{{ .EventType }}{{ end }}`" + ` |
+| Partition Key | {{ if .PartitionKey }}` + "`{{ .PartitionKey }}`" + `{{ else }}-{{ end }} |
+| ID Fields | {{ if .IDFields }}{{ code .IDFields }}{{ else }}-{{ end }} |
+| Version | {{ if .Version }}{{ .Version }}{{ else }}-{{ end }} |
+| Data Schema | {{ if .DataSchema }}{{ .DataSchema }}{{ else }}-{{ end }} |
+| Content Type | ` + "`{{ .ContentType }}`" + ` |
+| Extensions | {{ if .Extensions }}{{ code .Extensions }}{{ else }}-{{ end }} |
+| Publish | {{ code .Publish }} |
+| Subscribe | {{ code .Subscribe }} |
+
+### Payload Fields
+{{ if .Fields }}
+| Field | JSON Name | Type | Description |
+|-------|-----------|------|-------------|
+{{- range .Fields }}
+| ` + "`{{ .Name }}`" + ` | ` + "`{{ .JSONName }}`" + ` | ` + "`{{ .Type }}`" + ` | {{ cell .Description }} |
+{{- end }}
+{{- else }}
+This event has no payload fields.
+{{- end }}
+{{- end }}
+`))
+
+var htmlTmpl = htmltemplate.Must(htmltemplate.New("html").Funcs(htmltemplate.FuncMap{
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<!-- Code generated by protoc-gen-cloudevents. DO NOT EDIT. -->
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Event Catalog</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 72rem; padding: 0 1rem; color: #1f2328; }
+table { border-collapse: collapse; margin: 1rem 0; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: .4rem .75rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: .9em; }
+section { border-top: 1px solid #d0d7de; margin-top: 2rem; }
+.comment { white-space: pre-line; }
+</style>
+</head>
+<body>
+<h1>Event Catalog</h1>
+<table>
+<tr><th>Event Type</th><th>Description</th><th>Proto Package</th><th>Version</th></tr>
+{{- range .Events }}
+<tr><td><a href="#{{ .Anchor }}"><code>{{ .EventType }}</code></a></td><td>{{ .Description }}</td><td><code>{{ .Package }}</code></td><td>{{ if .Version }}{{ .Version }}{{ else }}-{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- range .Events }}
+<section id="{{ .Anchor }}">
+<h2>{{ .EventType }}</h2>
+{{- if .Description }}
+<p>{{ .Description }}</p>
+{{- end }}
+{{- if .Comment }}
+<p class="comment">{{ .Comment }}</p>
+{{- end }}
+<table>
+<tr><th>Payload</th><td><code>{{ .FullName }}</code></td></tr>
+<tr><th>Proto File</th><td><code>{{ .File }}</code></td></tr>
+<tr><th>Proto Package</th><td><code>{{ .Package }}</code></td></tr>
+<tr><th>Go Package</th><td><code>{{ .GoImportPath }}</code></td></tr>
+<tr><th>Subject</th><td><code>{{ if .Subject }}{{ .Subject }}{{ else }}{{ .EventType }}{{ end }}</code></td></tr>
+<tr><th>Partition Key</th><td>{{ if .PartitionKey }}<code>{{ .PartitionKey }}</code>{{ else }}-{{ end }}</td></tr>
+<tr><th>ID Fields</th><td>{{ if .IDFields }}<code>{{ join .IDFields ", " }}</code>{{ else }}-{{ end }}</td></tr>
+<tr><th>Version</th><td>{{ if .Version }}{{ .Version }}{{ else }}-{{ end }}</td></tr>
+<tr><th>Data Schema</th><td>{{ if .DataSchema }}<a href="{{ .DataSchema }}">{{ .DataSchema }}</a>{{ else }}-{{ end }}</td></tr>
+<tr><th>Content Type</th><td><code>{{ .ContentType }}</code></td></tr>
+<tr><th>Extensions</th><td>{{ if .Extensions }}<code>{{ join .Extensions ", " }}</code>{{ else }}-{{ end }}</td></tr>
+<tr><th>Publish</th><td><code>{{ join .Publish ", " }}</code></td></tr>
+<tr><th>Subscribe</th><td><code>{{ join .Subscribe ", " }}</code></td></tr>
+</table>
+<h3>Payload Fields</h3>
+{{- if .Fields }}
+<table>
+<tr><th>Field</th><th>JSON Name</th><th>Type</th><th>Description</th></tr>
+{{- range .Fields }}
+<tr><td><code>{{ .Name }}</code></td><td><code>{{ .JSONName }}</code></td><td><code>{{ .Type }}</code></td><td class="comment">{{ .Description }}</td></tr>
+{{- end }}
+</table>
+{{- else }}
+<p>This event has no payload fields.</p>
+{{- end }}
+</section>
+{{- end }}
+</body>
+</html>
+`))
@@ -0,0 +1,13 @@
+package main
+
+import "testing"
+
+// TestGenerateDoc 测试描述符夹具生成的事件目录与 golden 文件一致
+func TestGenerateDoc(t *testing.T) {
+	t.Run("markdown", func(t *testing.T) {
+		assertGolden(t, "mode=doc", "markdown")
+	})
+	t.Run("html", func(t *testing.T) {
+		assertGolden(t, "mode=doc,doc_format=html", "html")
+	})
+}
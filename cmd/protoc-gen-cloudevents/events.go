@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	cloudevents "github.com/yafeiaa/protoc-gen-cloudevents-go/proto/cloudevents"
+)
+
+// eventTypePattern is the documented {domain}.{resource}.{action} event_type convention:
+// at least three dot-separated lowercase segments
+var eventTypePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*(\.[a-z0-9][a-z0-9_-]*){2,}$`)
+
+type eventDescriptor struct {
+	EventType   string
+	Description string
+	Encoding    string
+	// Subject is the parsed subject_template, nil if the event is published to its event_type
+	Subject *subjectTemplate
+	// Version is the current payload schema version, 0 if the event is unversioned
+	Version uint32
+	// DataSchema is the CloudEvents dataschema URI of the current version
+	DataSchema string
+	// Extensions are the CloudEvents extensions declared by the event
+	Extensions []*extensionDescriptor
+	// ReplyName is the reply message name as written in EventMeta, empty if the event is not a request
+	ReplyName string
+	// Reply is the resolved reply message, which carries the event_meta option
+	Reply *protogen.Message
+	// PartitionKey is the payload field the partition key is read from, nil if the event has none
+	PartitionKey *subjectToken
+	// IDFields are the payload fields the event id is derived from, empty for random ids
+	IDFields []*subjectToken
+}
+
+// eventIndex maps the full name of every message carrying the event_meta option to its validated metadata
+type eventIndex map[protoreflect.FullName]*eventDescriptor
+
+// indexEvents extracts and validates the event metadata of all messages in the request,
+// including files that are not generated, so event_type values are unique across the whole request.
+// Events without a dataschema get one below dataSchemaBase, unless it is empty
+func indexEvents(gen *protogen.Plugin, dataSchemaBase string) (eventIndex, error) {
+	var (
+		index    = make(eventIndex)
+		owners   = make(map[string]protoreflect.FullName) // event_type -> message
+		messages = make(map[protoreflect.FullName]*protogen.Message)
+		requests []*protogen.Message
+		errs     []error
+	)
+
+	var walk func(msgs []*protogen.Message)
+	walk = func(msgs []*protogen.Message) {
+		for _, msg := range msgs {
+			messages[msg.Desc.FullName()] = msg
+			walk(msg.Messages)
+
+			eventMeta, err := extractEventMeta(msg)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if eventMeta == nil {
+				continue
+			}
+
+			if other, ok := owners[eventMeta.EventType]; ok {
+				errs = append(errs, errorf(msg.Desc, "duplicate event_type %q, already used by %s",
+					eventMeta.EventType, other))
+				continue
+			}
+			if eventMeta.DataSchema == "" && dataSchemaBase != "" {
+				eventMeta.DataSchema = defaultDataSchema(dataSchemaBase, eventMeta)
+			}
+			owners[eventMeta.EventType] = msg.Desc.FullName()
+			index[msg.Desc.FullName()] = eventMeta
+			if eventMeta.ReplyName != "" {
+				requests = append(requests, msg)
+			}
+		}
+	}
+	for _, f := range gen.Files {
+		walk(f.Messages)
+	}
+
+	// Replies are resolved once all messages are known, as they may be declared in any file
+	for _, msg := range requests {
+		eventMeta := index[msg.Desc.FullName()]
+		reply := resolveMessage(msg.Desc.FullName(), eventMeta.ReplyName, messages)
+		switch {
+		case reply == nil:
+			errs = append(errs, errorf(msg.Desc, "event_meta.reply %q: message not found", eventMeta.ReplyName))
+		case index[reply.Desc.FullName()] == nil:
+			errs = append(errs, errorf(msg.Desc, "event_meta.reply %s is not an event: it has no event_meta option",
+				reply.Desc.FullName()))
+		default:
+			eventMeta.Reply = reply
+		}
+	}
+
+	return index, errors.Join(errs...)
+}
+
+// extractEventMeta returns the validated event_meta option of msg, or nil if the option is absent
+func extractEventMeta(msg *protogen.Message) (*eventDescriptor, error) {
+	desc := msg.Desc
+	opts, ok := desc.Options().(*descriptorpb.MessageOptions)
+	if !ok || opts == nil {
+		return nil, nil
+	}
+
+	if !proto.HasExtension(opts, cloudevents.E_EventMeta) {
+		return nil, nil
+	}
+
+	ext := proto.GetExtension(opts, cloudevents.E_EventMeta)
+	eventMeta, ok := ext.(*cloudevents.EventMeta)
+	if !ok {
+		return nil, errorf(desc, "unexpected event_meta option type %T", ext)
+	}
+
+	eventType := eventMeta.GetEventType()
+	if eventType == "" {
+		return nil, errorf(desc, "event_meta.event_type is required")
+	}
+	if !eventTypePattern.MatchString(eventType) {
+		return nil, errorf(desc, "event_meta.event_type %q does not match {domain}.{resource}.{action}", eventType)
+	}
+
+	var subject *subjectTemplate
+	if raw := eventMeta.GetSubjectTemplate(); raw != "" {
+		var err error
+		if subject, err = parseSubjectTemplate(msg, raw); err != nil {
+			return nil, errorf(desc, "%v", err)
+		}
+	}
+
+	var partitionKey *subjectToken
+	if path := eventMeta.GetPartitionKey(); path != "" {
+		var err error
+		if partitionKey, err = resolveKeyField(msg, path); err != nil {
+			return nil, errorf(desc, "event_meta.partition_key: %v", err)
+		}
+	}
+
+	var idFields []*subjectToken
+	for _, path := range eventMeta.GetIdFields() {
+		field, err := resolveKeyField(msg, path)
+		if err != nil {
+			return nil, errorf(desc, "event_meta.id_fields: %v", err)
+		}
+		for _, other := range idFields {
+			if other.Path == path {
+				return nil, errorf(desc, "event_meta.id_fields: field %s is listed more than once", path)
+			}
+		}
+		idFields = append(idFields, field)
+	}
+
+	dataSchema := eventMeta.GetDataschema()
+	if dataSchema != "" {
+		if u, err := url.Parse(dataSchema); err != nil || !u.IsAbs() {
+			return nil, errorf(desc, "event_meta.dataschema %q is not an absolute URI", dataSchema)
+		}
+	}
+
+	extensions, err := parseExtensions(desc, eventMeta.GetExtensions())
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventDescriptor{
+		EventType:    eventType,
+		Description:  eventMeta.GetDescription(),
+		Encoding:     eventEncodings[eventMeta.GetEncoding()],
+		Subject:      subject,
+		Version:      eventMeta.GetVersion(),
+		DataSchema:   dataSchema,
+		Extensions:   extensions,
+		ReplyName:    eventMeta.GetReply(),
+		PartitionKey: partitionKey,
+		IDFields:     idFields,
+	}, nil
+}
+
+// resolveKeyField resolves a field path whose value identifies events, such as the partition key,
+// which must lead to a singular scalar field
+func resolveKeyField(msg *protogen.Message, path string) (*subjectToken, error) {
+	fields, err := resolveFieldPath(msg, path)
+	if err != nil {
+		return nil, err
+	}
+	if kind := fields[len(fields)-1].Desc.Kind(); !isSubjectKind(kind) {
+		return nil, fmt.Errorf("field %s has type %s, which cannot be used as a key", path, kind)
+	}
+	return &subjectToken{Path: path, Fields: fields}, nil
+}
+
+// resolveMessage resolves a message name the way protoc resolves type names: relative to the scopes
+// enclosing scope, from innermost to outermost, or fully qualified if it starts with a dot
+func resolveMessage(scope protoreflect.FullName, name string,
+	messages map[protoreflect.FullName]*protogen.Message) *protogen.Message {
+	if fullName, ok := strings.CutPrefix(name, "."); ok {
+		return messages[protoreflect.FullName(fullName)]
+	}
+	for ; ; scope = scope.Parent() {
+		fullName := protoreflect.FullName(name)
+		if scope != "" {
+			fullName = scope + "." + fullName
+		}
+		if msg, ok := messages[fullName]; ok {
+			return msg
+		}
+		if scope == "" {
+			return nil
+		}
+	}
+}
+
+// defaultDataSchema returns the dataschema URI of an event below base:
+// {base}/{event_type}.json, or {base}/{event_type}/v{version}.json for versioned events
+func defaultDataSchema(base string, event *eventDescriptor) string {
+	base = strings.TrimSuffix(base, "/")
+	if event.Version > 0 {
+		return fmt.Sprintf("%s/%s/v%d.json", base, event.EventType, event.Version)
+	}
+	return fmt.Sprintf("%s/%s.json", base, event.EventType)
+}
+
+// errorf returns an error prefixed with the proto source location and full name of desc
+func errorf(desc protoreflect.Descriptor, format string, args ...any) error {
+	file := desc.ParentFile()
+	loc := file.SourceLocations().ByDescriptor(desc)
+	return fmt.Errorf("%s:%d:%d: %s: %s", file.Path(), loc.StartLine+1, loc.StartColumn+1,
+		desc.FullName(), fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// TestIndexEvents 测试描述符夹具的事件索引、响应解析与默认 dataschema
+func TestIndexEvents(t *testing.T) {
+	gen, _ := newPlugin(t, "", loadFixture(t))
+	events, err := indexEvents(gen, "https://schemas.example.com/")
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	created := events["shop.events.OrderCreatedPayload"]
+	require.NotNil(t, created)
+	assert.Equal(t, "shop.order.created", created.EventType)
+	assert.Equal(t, "Order placed by a customer", created.Description)
+	assert.Equal(t, uint32(2), created.Version)
+	assert.Equal(t, "https://schemas.example.com/shop.order.created/v2.json", created.DataSchema)
+	assert.Equal(t, "shop.order.created.{currency}.{customer.id}", created.Subject.Raw)
+	assert.Equal(t, "order_id", created.PartitionKey.Path)
+	require.Len(t, created.Extensions, 2)
+	assert.Equal(t, "Prio", created.Extensions[1].GoName)
+
+	request := events["shop.events.GetOrderStatusPayload"]
+	require.NotNil(t, request)
+	require.NotNil(t, request.Reply)
+	assert.Equal(t, "shop.events.OrderStatusPayload", string(request.Reply.Desc.FullName()))
+	require.Len(t, request.IDFields, 1)
+	assert.Equal(t, "order_id", request.IDFields[0].Path)
+
+	assert.Equal(t, "protobuf", events["shop.events.OrderStatusPayload"].Encoding)
+	assert.Equal(t, "https://schemas.example.com/shop.order.shipped.json",
+		events["shop.events.Order.ShippedPayload"].DataSchema)
+	assert.Equal(t, "https://schemas.example.com/shop.order.status_reported.json",
+		events["shop.events.OrderStatusPayload"].DataSchema)
+
+	events, err = indexEvents(gen, "")
+	require.NoError(t, err)
+	assert.Empty(t, events["shop.events.Order.ShippedPayload"].DataSchema)
+}
+
+// TestDefaultDataSchema 测试版本化与非版本化事件的默认 dataschema
+func TestDefaultDataSchema(t *testing.T) {
+	assert.Equal(t, "https://schemas.example.com/shop.order.created.json",
+		defaultDataSchema("https://schemas.example.com", &eventDescriptor{EventType: "shop.order.created"}))
+	assert.Equal(t, "https://schemas.example.com/shop.order.created/v3.json",
+		defaultDataSchema("https://schemas.example.com/", &eventDescriptor{EventType: "shop.order.created", Version: 3}))
+}
+
+// invalidEventsFile 的每个消息都有一处 event_meta 错误
+const invalidEventsFile = `
+name: "invalid.proto"
+package: "invalid"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+options { go_package: "example.com/invalid;invalid" }
+message_type {
+  name: "Valid"
+  options { [cloudevents.event_meta] { event_type: "invalid.order.created" } }
+  field { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "id" }
+}
+message_type {
+  name: "Plain"
+  field { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "id" }
+}
+message_type {
+  name: "MissingType"
+  options { [cloudevents.event_meta] { description: "no type" } }
+}
+message_type {
+  name: "BadType"
+  options { [cloudevents.event_meta] { event_type: "Order.Created" } }
+}
+message_type {
+  name: "TwoSegments"
+  options { [cloudevents.event_meta] { event_type: "order.created" } }
+}
+message_type {
+  name: "Duplicate"
+  options { [cloudevents.event_meta] { event_type: "invalid.order.created" } }
+}
+message_type {
+  name: "BadSubject"
+  options { [cloudevents.event_meta] { event_type: "invalid.subject.created" subject_template: "invalid.{missing}" } }
+  field { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "id" }
+}
+message_type {
+  name: "BadPartitionKey"
+  options { [cloudevents.event_meta] { event_type: "invalid.partition.created" partition_key: "tags" } }
+  field { name: "tags" number: 1 label: LABEL_REPEATED type: TYPE_STRING json_name: "tags" }
+}
+message_type {
+  name: "BadKeyType"
+  options { [cloudevents.event_meta] { event_type: "invalid.key.created" id_fields: "score" } }
+  field { name: "score" number: 1 label: LABEL_OPTIONAL type: TYPE_DOUBLE json_name: "score" }
+}
+message_type {
+  name: "DuplicateIDField"
+  options { [cloudevents.event_meta] { event_type: "invalid.id.created" id_fields: ["id", "id"] } }
+  field { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "id" }
+}
+message_type {
+  name: "RelativeDataSchema"
+  options { [cloudevents.event_meta] { event_type: "invalid.schema.created" dataschema: "schemas/v1.json" } }
+}
+message_type {
+  name: "ReservedExtension"
+  options { [cloudevents.event_meta] { event_type: "invalid.extension.created" extensions { name: "time" } } }
+}
+message_type {
+  name: "MissingReply"
+  options { [cloudevents.event_meta] { event_type: "invalid.reply.requested" reply: "Missing" } }
+}
+message_type {
+  name: "PlainReply"
+  options { [cloudevents.event_meta] { event_type: "invalid.plain.requested" reply: "Plain" } }
+}
+`
+
+// TestIndexEvents_Diagnostics 测试 indexEvents 一次报告所有消息的 event_meta 错误
+func TestIndexEvents_Diagnostics(t *testing.T) {
+	gen, _ := newPlugin(t, "", parseFile(t, invalidEventsFile))
+	events, err := indexEvents(gen, "")
+	require.Error(t, err)
+	assert.Contains(t, events, protoreflect.FullName("invalid.Valid"))
+
+	want := []string{
+		`invalid.MissingType: event_meta.event_type is required`,
+		`invalid.BadType: event_meta.event_type "Order.Created" does not match {domain}.{resource}.{action}`,
+		`invalid.TwoSegments: event_meta.event_type "order.created" does not match {domain}.{resource}.{action}`,
+		`invalid.Duplicate: duplicate event_type "invalid.order.created", already used by invalid.Valid`,
+		`invalid.BadSubject: subject_template "invalid.{missing}": field path missing: message invalid.BadSubject has no field "missing"`,
+		`invalid.BadPartitionKey: event_meta.partition_key: field path tags: field invalid.BadPartitionKey.tags is repeated`,
+		`invalid.BadKeyType: event_meta.id_fields: field score has type double, which cannot be used as a key`,
+		`invalid.DuplicateIDField: event_meta.id_fields: field id is listed more than once`,
+		`invalid.RelativeDataSchema: event_meta.dataschema "schemas/v1.json" is not an absolute URI`,
+		`invalid.ReservedExtension: extension name "time" is reserved`,
+		`invalid.MissingReply: event_meta.reply "Missing": message not found`,
+		`invalid.PlainReply: event_meta.reply invalid.Plain is not an event: it has no event_meta option`,
+	}
+	lines := strings.Split(err.Error(), "\n")
+	require.Len(t, lines, len(want), err.Error())
+	for i, line := range lines {
+		assert.True(t, strings.HasPrefix(line, "invalid.proto:"), line)
+		assert.True(t, strings.HasSuffix(line, want[i]), "got %q, want suffix %q", line, want[i])
+	}
+
+	// 生成时同样报告这些错误
+	gen, cfg := newPlugin(t, "", parseFile(t, invalidEventsFile))
+	assert.EqualError(t, generate(gen, cfg), err.Error())
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	cloudevents "github.com/yafeiaa/protoc-gen-cloudevents-go/proto/cloudevents"
+)
+
+var (
+	// extensionNamePattern is the CloudEvents extension naming rule: lowercase letters or digits, at most 20
+	extensionNamePattern = regexp.MustCompile(`^[a-z0-9]{1,20}$`)
+	// goNamePattern matches exported Go identifiers
+	goNamePattern = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+)
+
+// reservedExtensions are attribute names declared extensions cannot use:
+// the CloudEvents context attributes and the extensions set by the runtime
+var reservedExtensions = map[string]bool{
+	"specversion":     true,
+	"id":              true,
+	"source":          true,
+	"type":            true,
+	"subject":         true,
+	"time":            true,
+	"datacontenttype": true,
+	"dataschema":      true,
+	"data":            true,
+	"dataversion":     true,
+	"replyerror":      true,
+	"partitionkey":    true,
+	"traceparent":     true,
+	"tracestate":      true,
+}
+
+// extensionTypes maps extension types to their Go type and the runtime getter reading them
+var extensionTypes = map[cloudevents.ExtensionType]struct{ GoType, Getter string }{
+	cloudevents.ExtensionType_EXTENSION_TYPE_UNSPECIFIED: {"string", "ExtensionString"},
+	cloudevents.ExtensionType_EXTENSION_TYPE_STRING:      {"string", "ExtensionString"},
+	cloudevents.ExtensionType_EXTENSION_TYPE_INTEGER:     {"int32", "ExtensionInt"},
+	cloudevents.ExtensionType_EXTENSION_TYPE_BOOLEAN:     {"bool", "ExtensionBool"},
+	cloudevents.ExtensionType_EXTENSION_TYPE_TIMESTAMP:   {"time.Time", "ExtensionTime"},
+}
+
+// extensionDescriptor is a validated CloudEvents extension declaration
+type extensionDescriptor struct {
+	Name        string
+	GoName      string
+	GoType      string
+	Getter      string
+	Description string
+	Required    bool
+}
+
+// same reports whether e and other generate the same code
+func (e *extensionDescriptor) same(other *extensionDescriptor) bool {
+	return e.Name == other.Name && e.GoName == other.GoName && e.GoType == other.GoType
+}
+
+// parseExtensions validates the extension declarations of desc
+func parseExtensions(desc protoreflect.Descriptor, exts []*cloudevents.Extension) ([]*extensionDescriptor, error) {
+	var (
+		parsed []*extensionDescriptor
+		names  = make(map[string]bool)
+	)
+	for _, ext := range exts {
+		name := ext.GetName()
+		if !extensionNamePattern.MatchString(name) {
+			return nil, errorf(desc, "extension name %q must consist of 1 to 20 lowercase letters or digits", name)
+		}
+		if reservedExtensions[name] {
+			return nil, errorf(desc, "extension name %q is reserved", name)
+		}
+		if names[name] {
+			return nil, errorf(desc, "extension %q declared more than once", name)
+		}
+		names[name] = true
+
+		goName := ext.GetGoName()
+		if goName == "" {
+			goName = strings.ToUpper(name[:1]) + name[1:]
+		}
+		if !goNamePattern.MatchString(goName) {
+			return nil, errorf(desc, "extension %q go_name %q is not an exported Go identifier", name, goName)
+		}
+
+		typ, ok := extensionTypes[ext.GetType()]
+		if !ok {
+			return nil, errorf(desc, "extension %q has unknown type %v", name, ext.GetType())
+		}
+
+		parsed = append(parsed, &extensionDescriptor{
+			Name:        name,
+			GoName:      goName,
+			GoType:      typ.GoType,
+			Getter:      typ.Getter,
+			Description: ext.GetDescription(),
+			Required:    ext.GetRequired(),
+		})
+	}
+	return parsed, nil
+}
+
+// fileExtensions returns the validated extensions declared with the extensions file option
+func fileExtensions(file *protogen.File) ([]*extensionDescriptor, error) {
+	opts, ok := file.Desc.Options().(*descriptorpb.FileOptions)
+	if !ok || opts == nil || !proto.HasExtension(opts, cloudevents.E_Extensions) {
+		return nil, nil
+	}
+	exts, _ := proto.GetExtension(opts, cloudevents.E_Extensions).([]*cloudevents.Extension)
+	return parseExtensions(file.Desc, exts)
+}
+
+// collectExtensions returns the extensions generated for file: those of the file option and of its events,
+// sorted by name. The same extension may be declared several times if the declarations agree
+func collectExtensions(file *protogen.File, fileExts []*extensionDescriptor, messages []*messageInfo) ([]*extensionDescriptor, error) {
+	byName := make(map[string]*extensionDescriptor)
+	add := func(desc protoreflect.Descriptor, ext *extensionDescriptor) error {
+		if other, ok := byName[ext.Name]; ok {
+			if !other.same(ext) {
+				return errorf(desc, "extension %q conflicts with another declaration in %s", ext.Name, file.Desc.Path())
+			}
+			return nil
+		}
+		byName[ext.Name] = ext
+		return nil
+	}
+
+	for _, ext := range fileExts {
+		if err := add(file.Desc, ext); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range messages {
+		for _, ext := range m.Event.Extensions {
+			if err := add(m.Message.Desc, ext); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	exts := make([]*extensionDescriptor, 0, len(byName))
+	for _, ext := range byName {
+		exts = append(exts, ext)
+	}
+	sort.Slice(exts, func(i, j int) bool { return exts[i].Name < exts[j].Name })
+	return exts, nil
+}
+
+// requiredExtensions returns the extensions every event of m must carry, sorted by name
+func requiredExtensions(fileExts []*extensionDescriptor, m *messageInfo) []*extensionDescriptor {
+	var required []*extensionDescriptor
+	seen := make(map[string]bool)
+	for _, exts := range [][]*extensionDescriptor{fileExts, m.Event.Extensions} {
+		for _, ext := range exts {
+			if ext.Required && !seen[ext.Name] {
+				seen[ext.Name] = true
+				required = append(required, ext)
+			}
+		}
+	}
+	sort.Slice(required, func(i, j int) bool { return required[i].Name < required[j].Name })
+	return required
+}
+
+// extensionIdents returns the Go identifiers generated for ext
+func extensionIdents(ext *extensionDescriptor) []string {
+	return []string{"Extension" + ext.GoName, "With" + ext.GoName, ext.GoName + "FromContext"}
+}
+
+// reserveExtensionIdents records the identifiers generated for exts in funcNames,
+// failing if they collide with identifiers generated from other files of the same Go package
+func reserveExtensionIdents(file *protogen.File, exts []*extensionDescriptor,
+	funcNames map[string]protoreflect.FullName) error {
+	owner := protoreflect.FullName(file.Desc.Path())
+	for _, ext := range exts {
+		for _, ident := range extensionIdents(ext) {
+			if other, ok := funcNames[ident]; ok {
+				return fmt.Errorf("%s: extension %q: identifier %s collides with %s", file.Desc.Path(), ext.Name, ident, other)
+			}
+			funcNames[ident] = owner
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloudevents "github.com/yafeiaa/protoc-gen-cloudevents-go/proto/cloudevents"
+)
+
+// TestParseExtensions 测试扩展声明的默认值与 Go 类型
+func TestParseExtensions(t *testing.T) {
+	gen, _ := newPlugin(t, "", loadFixture(t))
+	desc := findMessage(t, gen, "shop.events.OrderCreatedPayload").Desc
+
+	exts, err := parseExtensions(desc, []*cloudevents.Extension{
+		{Name: "tenant", Description: "Tenant", Required: true},
+		{Name: "region", Type: cloudevents.ExtensionType_EXTENSION_TYPE_STRING, GoName: "RegionCode"},
+		{Name: "priority", Type: cloudevents.ExtensionType_EXTENSION_TYPE_INTEGER},
+		{Name: "test", Type: cloudevents.ExtensionType_EXTENSION_TYPE_BOOLEAN},
+		{Name: "deadline", Type: cloudevents.ExtensionType_EXTENSION_TYPE_TIMESTAMP},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*extensionDescriptor{
+		{Name: "tenant", GoName: "Tenant", GoType: "string", Getter: "ExtensionString", Description: "Tenant", Required: true},
+		{Name: "region", GoName: "RegionCode", GoType: "string", Getter: "ExtensionString"},
+		{Name: "priority", GoName: "Priority", GoType: "int32", Getter: "ExtensionInt"},
+		{Name: "test", GoName: "Test", GoType: "bool", Getter: "ExtensionBool"},
+		{Name: "deadline", GoName: "Deadline", GoType: "time.Time", Getter: "ExtensionTime"},
+	}, exts)
+
+	exts, err = parseExtensions(desc, nil)
+	require.NoError(t, err)
+	assert.Empty(t, exts)
+}
+
+// TestParseExtensions_Errors 测试无效、保留和重复的扩展声明
+func TestParseExtensions_Errors(t *testing.T) {
+	gen, _ := newPlugin(t, "", loadFixture(t))
+	desc := findMessage(t, gen, "shop.events.OrderCreatedPayload").Desc
+
+	tests := []struct {
+		name string
+		exts []*cloudevents.Extension
+		err  string
+	}{
+		{name: "empty name", exts: []*cloudevents.Extension{{}},
+			err: `extension name "" must consist of 1 to 20 lowercase letters or digits`},
+		{name: "uppercase name", exts: []*cloudevents.Extension{{Name: "Tenant"}},
+			err: `extension name "Tenant" must consist of 1 to 20 lowercase letters or digits`},
+		{name: "underscore", exts: []*cloudevents.Extension{{Name: "tenant_id"}},
+			err: `extension name "tenant_id" must consist`},
+		{name: "too long", exts: []*cloudevents.Extension{{Name: strings.Repeat("a", 21)}},
+			err: "must consist of 1 to 20 lowercase letters or digits"},
+		{name: "context attribute", exts: []*cloudevents.Extension{{Name: "source"}},
+			err: `extension name "source" is reserved`},
+		{name: "runtime extension", exts: []*cloudevents.Extension{{Name: "dataversion"}},
+			err: `extension name "dataversion" is reserved`},
+		{name: "tracing extension", exts: []*cloudevents.Extension{{Name: "traceparent"}},
+			err: `extension name "traceparent" is reserved`},
+		{name: "duplicate", exts: []*cloudevents.Extension{{Name: "tenant"}, {Name: "tenant"}},
+			err: `extension "tenant" declared more than once`},
+		{name: "unexported go_name", exts: []*cloudevents.Extension{{Name: "tenant", GoName: "tenantID"}},
+			err: `extension "tenant" go_name "tenantID" is not an exported Go identifier`},
+		{name: "invalid go_name", exts: []*cloudevents.Extension{{Name: "tenant", GoName: "Tenant-ID"}},
+			err: `go_name "Tenant-ID" is not an exported Go identifier`},
+		{name: "unknown type", exts: []*cloudevents.Extension{{Name: "tenant", Type: cloudevents.ExtensionType(99)}},
+			err: `extension "tenant" has unknown type 99`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseExtensions(desc, tt.exts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Contains(t, err.Error(), "shop.events.OrderCreatedPayload")
+		})
+	}
+}
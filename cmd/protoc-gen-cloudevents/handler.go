@@ -0,0 +1,167 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	cloudevents "github.com/yafeiaa/protoc-gen-cloudevents-go/proto/cloudevents"
+)
+
+// handlerService is a proto service with event_handler methods,
+// generated as a XxxEventHandler interface and a RegisterXxxEventHandlers function
+type handlerService struct {
+	Service *protogen.Service
+	GoName  string
+	Methods []*handlerMethod
+}
+
+// handlerMethod is a service method handling the events of its request message
+type handlerMethod struct {
+	*eventRef
+	Method *protogen.Method
+	GoName string
+	// Envelope passes the handler a runtime.Envelope instead of the payload
+	Envelope bool
+}
+
+// eventRef refers to the generated code of an event from a generated file of any Go package
+type eventRef struct {
+	Event *eventDescriptor
+	// Payload, Descriptor and Subject are Go expressions qualified for the generated file
+	Payload    string
+	Descriptor string
+	// Subject is the subject pattern matching all events of the type
+	Subject string
+}
+
+// newEventRef returns the reference to the event declared by msg
+func newEventRef(gen *protogen.Plugin, cfg *params, imports *goImports, msg *protogen.Message,
+	event *eventDescriptor) *eventRef {
+	path := msg.GoIdent.GoImportPath
+	pkgName := gen.FilesByPath[msg.Desc.ParentFile().Path()].GoPackageName
+	funcName := toFuncName(msg.GoIdent.GoName, cfg.PayloadSuffix)
+
+	ref := &eventRef{
+		Event:      event,
+		Payload:    imports.qualify(path, pkgName, msg.GoIdent.GoName),
+		Descriptor: imports.qualify(path, pkgName, "Event"+funcName),
+	}
+	if event.Subject != nil {
+		ref.Subject = imports.qualify(path, pkgName, funcName+"SubjectFilter") + "{}.Subject()"
+	} else {
+		ref.Subject = imports.qualify(path, pkgName, "EventType"+funcName)
+	}
+	return ref
+}
+
+// goImport is a Go package imported by generated code
+type goImport struct {
+	Name string
+	Path protogen.GoImportPath
+}
+
+// goImports assigns package names to the Go packages referenced by a generated file
+type goImports struct {
+	self  protogen.GoImportPath
+	names map[protogen.GoImportPath]string
+	used  map[string]bool
+}
+
+func newGoImports(self protogen.GoImportPath) *goImports {
+	return &goImports{
+		self:  self,
+		names: make(map[protogen.GoImportPath]string),
+		// Package names imported by the templates themselves
+		used: map[string]bool{
+			"context": true, "errors": true, "regexp": true, "testing": true, "time": true, "runtime": true,
+		},
+	}
+}
+
+// qualify returns the Go expression referring to name in the package path named pkgName
+func (im *goImports) qualify(path protogen.GoImportPath, pkgName protogen.GoPackageName, name string) string {
+	if path == im.self {
+		return name
+	}
+	alias, ok := im.names[path]
+	if !ok {
+		alias = string(pkgName)
+		for i := 1; im.used[alias]; i++ {
+			alias = string(pkgName) + strconv.Itoa(i)
+		}
+		im.names[path] = alias
+		im.used[alias] = true
+	}
+	return alias + "." + name
+}
+
+// Imports returns the imported packages sorted by path
+func (im *goImports) Imports() []goImport {
+	imports := make([]goImport, 0, len(im.names))
+	for path, name := range im.names {
+		imports = append(imports, goImport{Name: name, Path: path})
+	}
+	sort.Slice(imports, func(i, j int) bool { return imports[i].Path < imports[j].Path })
+	return imports
+}
+
+// collectHandlers returns the services of file declaring event_handler methods.
+// Services without such methods, such as gRPC services, are ignored
+func collectHandlers(gen *protogen.Plugin, file *protogen.File, cfg *params, events eventIndex,
+	funcNames map[string]protoreflect.FullName, imports *goImports) ([]*handlerService, error) {
+	var services []*handlerService
+	for _, svc := range file.Services {
+		s := &handlerService{Service: svc, GoName: svc.GoName}
+		for _, method := range svc.Methods {
+			handler := eventHandlerOption(method)
+			if handler == nil {
+				continue
+			}
+			if method.Desc.IsStreamingClient() || method.Desc.IsStreamingServer() {
+				return nil, errorf(method.Desc, "event handler methods cannot be streaming")
+			}
+			event := events[method.Input.Desc.FullName()]
+			if event == nil {
+				return nil, errorf(method.Desc, "request type %s is not an event: it has no event_meta option",
+					method.Input.Desc.FullName())
+			}
+
+			s.Methods = append(s.Methods, &handlerMethod{
+				eventRef: newEventRef(gen, cfg, imports, method.Input, event),
+				Method:   method,
+				GoName:   method.GoName,
+				Envelope: handler.GetEnvelope(),
+			})
+		}
+		if len(s.Methods) == 0 {
+			continue
+		}
+
+		for _, ident := range []string{s.GoName + "EventHandler", "Register" + s.GoName + "EventHandlers"} {
+			if other, ok := funcNames[ident]; ok {
+				return nil, errorf(svc.Desc, "identifier %s collides with %s", ident, other)
+			}
+			funcNames[ident] = svc.Desc.FullName()
+		}
+		services = append(services, s)
+	}
+	return services, nil
+}
+
+// eventHandlerOption returns the event_handler option of method, or nil if the option is absent
+func eventHandlerOption(method *protogen.Method) *cloudevents.EventHandler {
+	opts, ok := method.Desc.Options().(*descriptorpb.MethodOptions)
+	if !ok || opts == nil || !proto.HasExtension(opts, cloudevents.E_EventHandler) {
+		return nil
+	}
+	handler, _ := proto.GetExtension(opts, cloudevents.E_EventHandler).(*cloudevents.EventHandler)
+	if handler == nil {
+		return &cloudevents.EventHandler{}
+	}
+	return handler
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// handlerFiles 中 shop/handlers.proto 的服务处理 shop/events.proto 中另一个 Go 包的事件
+var handlerFiles = []string{`
+name: "shop/events.proto"
+package: "shop"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+options { go_package: "example.com/shop/events;events" }
+message_type {
+  name: "OrderCreatedPayload"
+  options { [cloudevents.event_meta] { event_type: "shop.order.created" } }
+  field { name: "order_id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "orderId" }
+}
+message_type { name: "Ack" }
+`, `
+name: "shop/handlers.proto"
+package: "shop"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+dependency: "shop/events.proto"
+options { go_package: "example.com/shop/handlers;handlers" }
+service {
+  name: "Billing"
+  method {
+    name: "OnOrderCreated"
+    input_type: ".shop.OrderCreatedPayload"
+    output_type: ".shop.Ack"
+    options { [cloudevents.event_handler] {} }
+  }
+  method {
+    name: "AuditOrderCreated"
+    input_type: ".shop.OrderCreatedPayload"
+    output_type: ".shop.Ack"
+    options { [cloudevents.event_handler] { envelope: true } }
+  }
+  method { name: "GetInvoice" input_type: ".shop.Ack" output_type: ".shop.Ack" }
+}
+service {
+  name: "Orders"
+  method { name: "CreateOrder" input_type: ".shop.OrderCreatedPayload" output_type: ".shop.Ack" }
+}
+`}
+
+// collectHandlerFile 对 files 中的 shop/handlers.proto 运行 collectHandlers
+func collectHandlerFile(t *testing.T, files []*descriptorpb.FileDescriptorProto,
+	funcNames map[string]protoreflect.FullName) ([]*handlerService, *goImports, error) {
+	t.Helper()
+	gen, cfg := newPlugin(t, "", files, "shop/handlers.proto")
+	events, err := indexEvents(gen, "")
+	require.NoError(t, err)
+	file := gen.FilesByPath["shop/handlers.proto"]
+	imports := newGoImports(file.GoImportPath)
+	services, err := collectHandlers(gen, file, cfg, events, funcNames, imports)
+	return services, imports, err
+}
+
+// TestCollectHandlers 测试收集事件处理服务：忽略没有 event_handler 方法的服务与方法，并引用其他 Go 包的事件
+func TestCollectHandlers(t *testing.T) {
+	funcNames := make(map[string]protoreflect.FullName)
+	services, imports, err := collectHandlerFile(t, parseFile(t, handlerFiles...), funcNames)
+	require.NoError(t, err)
+
+	require.Len(t, services, 1)
+	svc := services[0]
+	assert.Equal(t, "Billing", svc.GoName)
+	require.Len(t, svc.Methods, 2)
+	for i, want := range []struct {
+		goName   string
+		envelope bool
+	}{{"OnOrderCreated", false}, {"AuditOrderCreated", true}} {
+		method := svc.Methods[i]
+		assert.Equal(t, want.goName, method.GoName)
+		assert.Equal(t, want.envelope, method.Envelope, want.goName)
+		assert.Equal(t, "events.OrderCreatedPayload", method.Payload)
+		assert.Equal(t, "events.EventOrderCreated", method.Descriptor)
+		assert.Equal(t, "events.EventTypeOrderCreated", method.Subject)
+	}
+
+	assert.Equal(t, []goImport{{Name: "events", Path: "example.com/shop/events"}}, imports.Imports())
+	assert.Equal(t, map[string]protoreflect.FullName{
+		"BillingEventHandler":          "shop.Billing",
+		"RegisterBillingEventHandlers": "shop.Billing",
+	}, funcNames)
+}
+
+// TestCollectHandlers_Errors 测试非事件请求类型、流式方法与标识符冲突，包括 envelope 处理方法
+func TestCollectHandlers_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    int
+		edit      func(*descriptorpb.MethodDescriptorProto)
+		funcNames map[string]protoreflect.FullName
+		err       string
+	}{
+		{
+			name: "non-event input",
+			edit: func(m *descriptorpb.MethodDescriptorProto) { m.InputType = proto.String(".shop.Ack") },
+			err:  "shop.Billing.OnOrderCreated: request type shop.Ack is not an event: it has no event_meta option",
+		},
+		{
+			name:   "envelope with non-event input",
+			method: 1,
+			edit:   func(m *descriptorpb.MethodDescriptorProto) { m.InputType = proto.String(".shop.Ack") },
+			err:    "shop.Billing.AuditOrderCreated: request type shop.Ack is not an event: it has no event_meta option",
+		},
+		{
+			name: "client streaming",
+			edit: func(m *descriptorpb.MethodDescriptorProto) { m.ClientStreaming = proto.Bool(true) },
+			err:  "shop.Billing.OnOrderCreated: event handler methods cannot be streaming",
+		},
+		{
+			name:   "envelope with server streaming",
+			method: 1,
+			edit:   func(m *descriptorpb.MethodDescriptorProto) { m.ServerStreaming = proto.Bool(true) },
+			err:    "shop.Billing.AuditOrderCreated: event handler methods cannot be streaming",
+		},
+		{
+			name:      "interface collision",
+			funcNames: map[string]protoreflect.FullName{"BillingEventHandler": "shop.BillingEventHandler"},
+			err:       "shop.Billing: identifier BillingEventHandler collides with shop.BillingEventHandler",
+		},
+		{
+			name:      "register function collision",
+			funcNames: map[string]protoreflect.FullName{"RegisterBillingEventHandlers": "shop.Other"},
+			err:       "shop.Billing: identifier RegisterBillingEventHandlers collides with shop.Other",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := parseFile(t, handlerFiles...)
+			if tt.edit != nil {
+				tt.edit(files[len(files)-1].Service[0].Method[tt.method])
+			}
+			funcNames := tt.funcNames
+			if funcNames == nil {
+				funcNames = make(map[string]protoreflect.FullName)
+			}
+			_, _, err := collectHandlerFile(t, files, funcNames)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "shop/handlers.proto:")
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+// TestGenerate_Handlers 测试事件处理服务与其引用的另一个 Go 包的事件一起生成的代码可以编译
+func TestGenerate_Handlers(t *testing.T) {
+	files := parseFile(t, handlerFiles...)
+	names := []string{"shop/events.proto", "shop/handlers.proto"}
+	generated := protocGenGo(t, files, names...)
+	gen, cfg := newPlugin(t, "", files, names...)
+	require.NoError(t, generate(gen, cfg))
+	resp := gen.Response()
+	require.Empty(t, resp.GetError())
+
+	require.Len(t, resp.File, 2)
+	content := resp.File[1].GetContent()
+	assert.Contains(t, content, "type BillingEventHandler interface {")
+	assert.Contains(t, content, "func RegisterBillingEventHandlers(")
+	assert.NotContains(t, content, "Orders")
+	compileGenerated(t, append(generated, resp.File...)...)
+}
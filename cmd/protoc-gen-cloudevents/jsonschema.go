@@ -0,0 +1,323 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// jsonSchemaDialect is the JSON Schema version of generated schemas
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// generateJSONSchemas emits one JSON Schema document per event declared in file,
+// named {event_type}.schema.json next to the file's generated Go code
+func generateJSONSchemas(gen *protogen.Plugin, file *protogen.File, cfg *params, events eventIndex,
+	funcNames map[string]protoreflect.FullName) error {
+	var messages []*messageInfo
+	if err := collectMessages(file.Messages, cfg, events, funcNames, &messages); err != nil {
+		return err
+	}
+
+	dir := path.Dir(file.GeneratedFilenamePrefix)
+	for _, m := range messages {
+		schemas := newSchemaBuilder("#/$defs/")
+		doc := schema{
+			"$schema": jsonSchemaDialect,
+			"title":   m.FuncName,
+			"$ref":    schemas.ref(m.Message)["$ref"],
+			"$defs":   schemas.defs,
+		}
+		if m.Event.DataSchema != "" {
+			doc["$id"] = m.Event.DataSchema
+		}
+		if m.Event.Description != "" {
+			doc["description"] = m.Event.Description
+		}
+
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("%s: marshal json schema of %s: %w", file.Desc.Path(), m.Message.Desc.FullName(), err)
+		}
+
+		g := gen.NewGeneratedFile(path.Join(dir, m.Event.EventType+".schema.json"), "")
+		if _, err := g.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schema is a JSON Schema object, marshalled with sorted keys
+type schema = map[string]any
+
+// schemaBuilder derives JSON Schemas from proto messages following the proto3 JSON mapping.
+// Messages are emitted once into defs and referenced through refPrefix + full name
+type schemaBuilder struct {
+	refPrefix string
+	defs      map[string]schema
+}
+
+func newSchemaBuilder(refPrefix string) *schemaBuilder {
+	return &schemaBuilder{refPrefix: refPrefix, defs: make(map[string]schema)}
+}
+
+// ref returns a reference to the schema of msg, adding it and the messages it uses to defs
+func (b *schemaBuilder) ref(msg *protogen.Message) schema {
+	if wkt := wellKnownSchema(msg.Desc.FullName()); wkt != nil {
+		return wkt
+	}
+
+	name := string(msg.Desc.FullName())
+	if _, ok := b.defs[name]; !ok {
+		// Reserve the name first so recursive messages terminate
+		b.defs[name] = nil
+		b.defs[name] = b.message(msg)
+	}
+	return schema{"$ref": b.refPrefix + name}
+}
+
+// message returns the object schema of msg
+func (b *schemaBuilder) message(msg *protogen.Message) schema {
+	properties := make(schema)
+	var (
+		oneOfs   []any
+		required []any
+	)
+	for _, field := range msg.Fields {
+		if fieldRules(field.Desc).GetRequired() {
+			required = append(required, field.Desc.JSONName())
+		}
+	}
+
+	for _, field := range msg.Fields {
+		if oneof := field.Oneof; oneof != nil && !oneof.Desc.IsSynthetic() {
+			continue
+		}
+		properties[field.Desc.JSONName()] = b.field(field)
+	}
+
+	// Fields of a oneof are mutually exclusive: at most one of them may be present
+	for _, oneof := range msg.Oneofs {
+		if oneof.Desc.IsSynthetic() {
+			continue
+		}
+		var names []any
+		for _, field := range oneof.Fields {
+			properties[field.Desc.JSONName()] = b.field(field)
+			names = append(names, field.Desc.JSONName())
+		}
+		oneOfs = append(oneOfs, schema{
+			"oneOf": []any{
+				schema{"not": schema{"anyOf": requiredEach(names)}},
+				schema{"oneOf": requiredEach(names)},
+			},
+		})
+	}
+
+	// Unknown properties are allowed so schemas of older versions accept payloads with new fields
+	s := schema{
+		"type":       "object",
+		"title":      msg.GoIdent.GoName,
+		"properties": properties,
+	}
+	if desc := comment(msg.Comments.Leading); desc != "" {
+		s["description"] = desc
+	}
+	if required != nil {
+		s["required"] = required
+	}
+	switch len(oneOfs) {
+	case 0:
+	case 1:
+		s["oneOf"] = oneOfs[0].(schema)["oneOf"]
+	default:
+		s["allOf"] = oneOfs
+	}
+	return s
+}
+
+// requiredEach returns one {"required": [name]} schema per name
+func requiredEach(names []any) []any {
+	schemas := make([]any, len(names))
+	for i, name := range names {
+		schemas[i] = schema{"required": []any{name}}
+	}
+	return schemas
+}
+
+// field returns the schema of a field value, including repeated and map fields
+func (b *schemaBuilder) field(field *protogen.Field) schema {
+	var s schema
+	switch {
+	case field.Desc.IsMap():
+		s = schema{
+			"type":                 "object",
+			"additionalProperties": b.singular(field.Message.Fields[1]),
+		}
+	case field.Desc.IsList():
+		s = schema{"type": "array", "items": withRules(b.singular(field), field)}
+	default:
+		s = withRules(b.singular(field), field)
+	}
+
+	// Rules on the number of elements, see FieldRules
+	if rules := fieldRules(field.Desc); rules != nil {
+		minKey, maxKey := "minItems", "maxItems"
+		if field.Desc.IsMap() {
+			minKey, maxKey = "minProperties", "maxProperties"
+		}
+		if n := rules.GetMinItems(); n > 0 {
+			s[minKey] = n
+		}
+		if n := rules.GetMaxItems(); n > 0 {
+			s[maxKey] = n
+		}
+	}
+
+	if desc := comment(field.Comments.Leading); desc != "" {
+		if _, isRef := s["$ref"]; isRef {
+			// Sibling keywords of $ref are allowed since draft 2019-09
+			s = schema{"$ref": s["$ref"], "description": desc}
+		} else {
+			s["description"] = desc
+		}
+	}
+	return s
+}
+
+// withRules adds the pattern and range rules of field to the schema s of a single value.
+// Range rules of 64-bit integers, which are JSON strings, are not expressible and left out
+func withRules(s schema, field *protogen.Field) schema {
+	rules := fieldRules(field.Desc)
+	if rules == nil {
+		return s
+	}
+	if pattern := rules.GetPattern(); pattern != "" {
+		s["pattern"] = pattern
+	}
+	if rules.Min != nil || rules.Max != nil {
+		if kind := field.Desc.Kind(); kind == protoreflect.FloatKind || kind == protoreflect.DoubleKind {
+			// NaN and infinities are out of any range
+			s = schema{"type": "number"}
+		}
+	}
+	if s["type"] == "integer" || s["type"] == "number" {
+		if rules.Min != nil {
+			s["minimum"] = rules.GetMin()
+		}
+		if rules.Max != nil {
+			s["maximum"] = rules.GetMax()
+		}
+	}
+	return s
+}
+
+// singular returns the schema of a single value of field
+func (b *schemaBuilder) singular(field *protogen.Field) schema {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return schema{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return schema{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return schema{"type": "integer", "format": "uint32", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return schema{"type": "string", "format": "int64", "pattern": `^-?[0-9]+$`}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return schema{"type": "string", "format": "uint64", "pattern": `^[0-9]+$`}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return floatSchema()
+	case protoreflect.StringKind:
+		return schema{"type": "string"}
+	case protoreflect.BytesKind:
+		return schema{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.EnumKind:
+		return enumSchema(field.Enum)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return b.ref(field.Message)
+	}
+	return schema{}
+}
+
+// floatSchema accepts numbers and the special values protojson writes as strings
+func floatSchema() schema {
+	return schema{
+		"oneOf": []any{
+			schema{"type": "number"},
+			schema{"type": "string", "enum": []any{"NaN", "Infinity", "-Infinity"}},
+		},
+	}
+}
+
+// enumSchema lists the value names of enum, which protojson writes as strings
+func enumSchema(enum *protogen.Enum) schema {
+	if enum.Desc.FullName() == "google.protobuf.NullValue" {
+		return schema{"type": "null"}
+	}
+
+	var names []any
+	for _, v := range enum.Values {
+		names = append(names, string(v.Desc.Name()))
+	}
+	s := schema{"type": "string", "title": enum.GoIdent.GoName, "enum": names}
+	if desc := comment(enum.Comments.Leading); desc != "" {
+		s["description"] = desc
+	}
+	return s
+}
+
+// wellKnownSchema returns the schema of the well-known types with a special JSON mapping, nil for other messages
+func wellKnownSchema(name protoreflect.FullName) schema {
+	switch name {
+	case "google.protobuf.Timestamp":
+		return schema{"type": "string", "format": "date-time"}
+	case "google.protobuf.Duration":
+		return schema{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]{1,9})?s$`}
+	case "google.protobuf.FieldMask":
+		return schema{"type": "string"}
+	case "google.protobuf.Struct":
+		return schema{"type": "object"}
+	case "google.protobuf.ListValue":
+		return schema{"type": "array"}
+	case "google.protobuf.Value":
+		return schema{}
+	case "google.protobuf.Empty":
+		return schema{"type": "object", "additionalProperties": false}
+	case "google.protobuf.Any":
+		return schema{
+			"type":       "object",
+			"properties": schema{"@type": schema{"type": "string"}},
+			"required":   []any{"@type"},
+		}
+	case "google.protobuf.BoolValue":
+		return schema{"type": "boolean"}
+	case "google.protobuf.StringValue":
+		return schema{"type": "string"}
+	case "google.protobuf.BytesValue":
+		return schema{"type": "string", "contentEncoding": "base64"}
+	case "google.protobuf.Int32Value":
+		return schema{"type": "integer", "format": "int32"}
+	case "google.protobuf.UInt32Value":
+		return schema{"type": "integer", "format": "uint32", "minimum": 0}
+	case "google.protobuf.Int64Value":
+		return schema{"type": "string", "format": "int64", "pattern": `^-?[0-9]+$`}
+	case "google.protobuf.UInt64Value":
+		return schema{"type": "string", "format": "uint64", "pattern": `^[0-9]+$`}
+	case "google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+		return floatSchema()
+	}
+	return nil
+}
+
+// comment returns a proto comment with the whitespace around every line trimmed
+func comment(c protogen.Comments) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(c)), "\n") {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
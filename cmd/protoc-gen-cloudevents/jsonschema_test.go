@@ -0,0 +1,8 @@
+package main
+
+import "testing"
+
+// TestGenerateJSONSchema 测试描述符夹具生成的 JSON Schema 与 golden 文件一致
+func TestGenerateJSONSchema(t *testing.T) {
+	assertGolden(t, "mode=jsonschema,dataschema_base_url=https://schemas.example.com", "jsonschema")
+}
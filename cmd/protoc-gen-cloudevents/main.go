@@ -3,331 +3,403 @@ package main
 
 import (
 	"bytes"
+	"flag"
+	"fmt"
 	"go/format"
-	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
-	"text/template"
+	"unicode"
+	"unicode/utf8"
 
 	"google.golang.org/protobuf/compiler/protogen"
-	"google.golang.org/protobuf/proto"
-	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
 	cloudevents "github.com/yafeiaa/protoc-gen-cloudevents-go/proto/cloudevents"
 )
 
-func main() {
-	protogen.Options{}.Run(func(gen *protogen.Plugin) error {
-		for _, f := range gen.Files {
-			if !f.Generate {
-				continue
-			}
-			generateFile(gen, f)
-		}
-		return nil
-	})
-}
-
-func generateFile(gen *protogen.Plugin, file *protogen.File) {
-	// Collect all messages with event_meta option
-	var messages []*messageInfo
+// defaultRuntimeImportPath is the runtime package generated code imports by default
+const defaultRuntimeImportPath = "github.com/yafeiaa/protoc-gen-cloudevents-go/runtime"
 
-	for _, msg := range file.Messages {
-		opts, ok := msg.Desc.Options().(*descriptorpb.MessageOptions)
-		if !ok {
-			continue
-		}
-		eventMeta := extractEventMeta(opts)
-		if eventMeta == nil {
-			continue
-		}
+// encodingCodecs maps the supported encoding parameter values to runtime codecs
+var encodingCodecs = map[string]string{
+	"protojson": "ProtoJSON",
+	"protobuf":  "Protobuf",
+	"json":      "JSON",
+}
 
-		messages = append(messages, &messageInfo{
-			Name:  msg.GoIdent.GoName,
-			Event: eventMeta,
-		})
-	}
+// codecContentTypes maps runtime codecs to the datacontenttype of the events they encode
+var codecContentTypes = map[string]string{
+	"ProtoJSON": "application/json",
+	"Protobuf":  "application/protobuf",
+	"JSON":      "application/json",
+}
 
-	if len(messages) == 0 {
-		return
-	}
+// generator generates the output of one mode for a whole CodeGeneratorRequest
+type generator func(gen *protogen.Plugin, cfg *params, events eventIndex) error
 
-	// Generate file
-	filename := file.GeneratedFilenamePrefix + "_events.pb.go"
-	g := gen.NewGeneratedFile(filename, file.GoImportPath)
+// fileGenerator generates the output of one mode for a single proto file
+type fileGenerator func(gen *protogen.Plugin, file *protogen.File, cfg *params, events eventIndex,
+	funcNames map[string]protoreflect.FullName) error
 
-	// Execute template
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, map[string]any{
-		"Package":  file.GoPackageName,
-		"Messages": messages,
-	}); err != nil {
-		log.Fatalf("execute template: %v", err)
-	}
+// generators maps the supported mode parameter values to their generator
+var generators = map[string]generator{
+	"go":         perFile(generateFile),
+	"asyncapi":   perFile(generateAsyncAPI),
+	"jsonschema": perFile(generateJSONSchemas),
+	"doc":        generateDoc,
+}
 
-	// Format code
-	formatted, err := format.Source(buf.Bytes())
-	if err != nil {
-		log.Fatalf("format code: %v", err)
-	}
+// binaryPrefix is the plugin binary name; protoc-gen-cloudevents-{mode} binaries default to that mode
+const binaryPrefix = "protoc-gen-cloudevents"
 
-	g.P(string(formatted))
+// eventEncodings maps the EventMeta encoding values to encoding parameter values
+var eventEncodings = map[cloudevents.Encoding]string{
+	cloudevents.Encoding_ENCODING_PROTOJSON: "protojson",
+	cloudevents.Encoding_ENCODING_PROTOBUF:  "protobuf",
+	cloudevents.Encoding_ENCODING_JSON:      "json",
 }
 
-type eventDescriptor struct {
-	EventType   string
-	Description string
+// params holds the plugin parameters passed through --cloudevents_opt
+type params struct {
+	// Mode selects what is generated: Go code, AsyncAPI documents, JSON Schemas or an event catalog
+	Mode string
+	// PayloadSuffix is trimmed from message names to derive function names
+	PayloadSuffix string
+	// EmitGroupSubscribers controls generation of SubscribeXxxWithGroup functions
+	EmitGroupSubscribers bool
+	// EmitRegistration controls registration of generated events into runtime.DefaultRegistry
+	EmitRegistration bool
+	// EmitTestRecorder controls generation of a test package with typed assertions on a runtime.Recorder
+	EmitTestRecorder bool
+	// FilenameSuffix is appended to the proto file name to form the output file name
+	FilenameSuffix string
+	// RuntimeImportPath is the import path of the runtime package used by generated code
+	RuntimeImportPath string
+	// Encoding selects how event payloads are encoded into CloudEvents data,
+	// unless overridden per event by EventMeta.encoding
+	Encoding string
+	// DataSchemaBaseURL is the base URI of the dataschema of events that do not declare one
+	DataSchemaBaseURL string
+	// AsyncAPITitle is the info.title of AsyncAPI documents, the proto package if empty
+	AsyncAPITitle string
+	// AsyncAPIVersion is the info.version of AsyncAPI documents
+	AsyncAPIVersion string
+	// AsyncAPIAction selects which operations AsyncAPI documents describe: send, receive or both
+	AsyncAPIAction string
+	// DocFormat is the format of the event catalog: markdown or html
+	DocFormat string
+	// DocFilename is the name of the event catalog file, events.md or events.html if empty
+	DocFilename string
 }
 
-type messageInfo struct {
-	Name  string
-	Event *eventDescriptor
+func (p *params) register(flags *flag.FlagSet) {
+	flags.StringVar(&p.Mode, "mode", "go",
+		"what to generate: go, asyncapi, jsonschema or doc")
+	flags.StringVar(&p.PayloadSuffix, "payload_suffix", "Payload",
+		"suffix trimmed from message names to derive function names")
+	flags.BoolVar(&p.EmitGroupSubscribers, "emit_group_subscribers", true,
+		"generate SubscribeXxxWithGroup functions")
+	flags.BoolVar(&p.EmitRegistration, "emit_registration", true,
+		"register generated events into runtime.DefaultRegistry")
+	flags.BoolVar(&p.EmitTestRecorder, "emit_test_recorder", false,
+		"generate a test package with typed assertions on a runtime.Recorder")
+	flags.StringVar(&p.FilenameSuffix, "filename_suffix", "_events.pb.go",
+		"suffix of generated file names")
+	flags.StringVar(&p.RuntimeImportPath, "runtime_import_path", defaultRuntimeImportPath,
+		"import path of the runtime package")
+	flags.StringVar(&p.Encoding, "encoding", "protojson",
+		"default payload encoding: protojson, protobuf or json")
+	flags.StringVar(&p.DataSchemaBaseURL, "dataschema_base_url", "",
+		"base URI of the dataschema of events that do not declare one")
+	flags.StringVar(&p.AsyncAPITitle, "asyncapi_title", "",
+		"info.title of AsyncAPI documents (defaults to the proto package)")
+	flags.StringVar(&p.AsyncAPIVersion, "asyncapi_version", "1.0.0",
+		"info.version of AsyncAPI documents")
+	flags.StringVar(&p.AsyncAPIAction, "asyncapi_action", "send",
+		"operations described by AsyncAPI documents: send, receive or both")
+	flags.StringVar(&p.DocFormat, "doc_format", "markdown",
+		"format of the event catalog: markdown or html")
+	flags.StringVar(&p.DocFilename, "doc_filename", "",
+		"name of the event catalog file (defaults to events.md or events.html)")
 }
 
-func extractEventMeta(opts *descriptorpb.MessageOptions) *eventDescriptor {
-	if opts == nil {
-		return nil
+// modeFromBinary returns the default mode of a plugin binary named protoc-gen-cloudevents-{mode},
+// so e.g. protoc-gen-cloudevents-doc can be used through --cloudevents-doc_out
+func modeFromBinary(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), ".exe")
+	if mode, ok := strings.CutPrefix(name, binaryPrefix+"-"); ok {
+		if _, ok := generators[mode]; ok {
+			return mode
+		}
 	}
+	return "go"
+}
 
-	if !proto.HasExtension(opts, cloudevents.E_EventMeta) {
-		return nil
+func (p *params) validate() error {
+	if _, ok := generators[p.Mode]; !ok {
+		return fmt.Errorf("invalid mode %q", p.Mode)
 	}
-
-	ext := proto.GetExtension(opts, cloudevents.E_EventMeta)
-	eventMeta, ok := ext.(*cloudevents.EventMeta)
-	if !ok {
-		return nil
+	if _, ok := docTemplates[p.DocFormat]; !ok {
+		return fmt.Errorf("invalid doc_format %q", p.DocFormat)
 	}
-
-	if eventMeta.GetEventType() == "" {
-		return nil
+	if _, ok := asyncAPIActions[p.AsyncAPIAction]; !ok {
+		return fmt.Errorf("invalid asyncapi_action %q", p.AsyncAPIAction)
 	}
-
-	return &eventDescriptor{
-		EventType:   eventMeta.GetEventType(),
-		Description: eventMeta.GetDescription(),
+	if _, ok := encodingCodecs[p.Encoding]; !ok {
+		return fmt.Errorf("invalid encoding %q", p.Encoding)
 	}
+	if !strings.HasSuffix(p.FilenameSuffix, ".go") {
+		return fmt.Errorf("invalid filename_suffix %q: must end with .go", p.FilenameSuffix)
+	}
+	if p.DataSchemaBaseURL != "" {
+		if u, err := url.Parse(p.DataSchemaBaseURL); err != nil || !u.IsAbs() {
+			return fmt.Errorf("invalid dataschema_base_url %q: must be an absolute URI", p.DataSchemaBaseURL)
+		}
+	}
+	if p.RuntimeImportPath == "" {
+		return fmt.Errorf("runtime_import_path must not be empty")
+	}
+	return nil
 }
 
-func toFuncName(name string) string {
-	// UserRegisteredPayload -> UserRegistered
-	return strings.TrimSuffix(name, "Payload")
-}
-
-var tmpl = template.Must(template.New("events").Funcs(template.FuncMap{
-	"toFuncName": toFuncName,
-}).Parse(`// Code generated by protoc-gen-cloudevents. DO NOT EDIT.
-
-package {{ .Package }}
-
-import (
-	"context"
-	"errors"
-	"fmt"
-	"time"
-
-	cloudevents "github.com/cloudevents/sdk-go/v2"
-	"github.com/google/uuid"
-)
-
-// ============================================================
-// Event Type Definitions
-// ============================================================
-
-const (
-{{- range .Messages }}
-	// EventType{{ toFuncName .Name }} {{ .Event.Description }}
-	EventType{{ toFuncName .Name }} = "{{ .Event.EventType }}"
-{{- end }}
-)
-
-// ============================================================
-// Interface Definitions
-// ============================================================
-
-// Publisher is the interface for publishing events
-type Publisher interface {
-	Publish(ctx context.Context, subject string, event *cloudevents.Event) error
+func main() {
+	var (
+		flags flag.FlagSet
+		cfg   params
+	)
+	cfg.register(&flags)
+	cfg.Mode = modeFromBinary(os.Args[0])
+
+	protogen.Options{
+		ParamFunc: flags.Set,
+	}.Run(func(gen *protogen.Plugin) error {
+		return generate(gen, &cfg)
+	})
 }
 
-// Subscriber is the interface for subscribing to events (broadcast mode)
-type Subscriber interface {
-	Subscribe(ctx context.Context, subject string, handler EventHandler) error
+// generate validates cfg and the events of the request, then runs the generator of cfg.Mode
+func generate(gen *protogen.Plugin, cfg *params) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	events, err := indexEvents(gen, cfg.DataSchemaBaseURL)
+	if err != nil {
+		return err
+	}
+	return generators[cfg.Mode](gen, cfg, events)
 }
 
-// HandlerGroupSubscriber is the interface for subscribing to events (handler group mode)
-type HandlerGroupSubscriber interface {
-	SubscribeWithHandlerGroup(ctx context.Context, subject, group string, handler EventHandler) error
+// perFile returns a generator running generate for every file to generate
+func perFile(generate fileGenerator) generator {
+	return func(gen *protogen.Plugin, cfg *params, events eventIndex) error {
+		// Function names generated so far per Go package, used to detect collisions
+		funcNames := make(map[protogen.GoImportPath]map[string]protoreflect.FullName)
+		for _, f := range gen.Files {
+			if !f.Generate {
+				continue
+			}
+			if funcNames[f.GoImportPath] == nil {
+				funcNames[f.GoImportPath] = make(map[string]protoreflect.FullName)
+			}
+			if err := generate(gen, f, cfg, events, funcNames[f.GoImportPath]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 }
 
-// EventHandler is the function signature for event handlers
-type EventHandler func(context.Context, *cloudevents.Event) error
-
-// ============================================================
-// Publish Options
-// ============================================================
-
-// PublishOption is a functional option for publishing events
-type PublishOption func(*publishOptions)
+func generateFile(gen *protogen.Plugin, file *protogen.File, cfg *params, events eventIndex,
+	funcNames map[string]protoreflect.FullName) error {
+	// Collect all messages with event_meta option, including nested ones
+	var messages []*messageInfo
+	if err := collectMessages(file.Messages, cfg, events, funcNames, &messages); err != nil {
+		return err
+	}
 
-type publishOptions struct {
-	source     string
-	subject    string
-	extensions map[string]interface{}
-}
+	imports := newGoImports(file.GoImportPath)
+	handlers, err := collectHandlers(gen, file, cfg, events, funcNames, imports)
+	if err != nil {
+		return err
+	}
 
-// WithSource sets the event source (required)
-// Examples: "myapp/api-server", "myapp/controller/pod-abc"
-func WithSource(source string) PublishOption {
-	return func(o *publishOptions) {
-		o.source = source
+	validation, err := collectValidation(file, make(validationIndex))
+	if err != nil {
+		return err
 	}
-}
 
-// WithSubject overrides the NATS subject (optional)
-// Defaults to event_type if not specified
-func WithSubject(subject string) PublishOption {
-	return func(o *publishOptions) {
-		o.subject = subject
+	if len(messages) == 0 && len(handlers) == 0 && len(validation.Messages) == 0 {
+		return nil
 	}
-}
 
-// WithExtension adds CloudEvents extension attributes
-func WithExtension(key string, value interface{}) PublishOption {
-	return func(o *publishOptions) {
-		if key != "" {
-			o.extensions[key] = value
+	fileExts, err := fileExtensions(file)
+	if err != nil {
+		return err
+	}
+	extensions, err := collectExtensions(file, fileExts, messages)
+	if err != nil {
+		return err
+	}
+	if err := reserveExtensionIdents(file, extensions, funcNames); err != nil {
+		return err
+	}
+	// Each file registers its own events, since files of one Go package may be generated by separate protoc runs
+	registerFunc := "Register" + fileGoName(file) + "Events"
+	if len(messages) > 0 {
+		if other, ok := funcNames[registerFunc]; ok {
+			return fmt.Errorf("%s: function name %q collides with %s", file.Desc.Path(), registerFunc, other)
 		}
+		funcNames[registerFunc] = protoreflect.FullName(file.Desc.Path())
 	}
-}
-
-// ============================================================
-// Internal Helper Functions
-// ============================================================
-
-func buildEvent(eventType string, payload interface{}, opts []PublishOption) (*cloudevents.Event, string, error) {
-	options := &publishOptions{
-		extensions: make(map[string]interface{}),
+	importTime := false
+	for _, ext := range extensions {
+		importTime = importTime || ext.GoType == "time.Time"
 	}
-	for _, opt := range opts {
-		if opt != nil {
-			opt(options)
+	for _, m := range messages {
+		m.RequiredExtensions = requiredExtensions(fileExts, m)
+		if reply := m.Event.Reply; reply != nil {
+			m.Reply = newEventRef(gen, cfg, imports, reply, events[reply.Desc.FullName()])
 		}
 	}
 
-	if options.source == "" {
-		return nil, "", fmt.Errorf("events: source is required, use WithSource() option")
-	}
+	// Generate file
+	filename := file.GeneratedFilenamePrefix + cfg.FilenameSuffix
+	g := gen.NewGeneratedFile(filename, file.GoImportPath)
 
-	subject := options.subject
-	if subject == "" {
-		subject = eventType
+	hasSubjects, hasPartitionKeys, hasIDs, hasVersions, hasReplies := false, false, false, false, false
+	for _, m := range messages {
+		hasSubjects = hasSubjects || m.Event.Subject != nil
+		hasPartitionKeys = hasPartitionKeys || m.Event.PartitionKey != nil
+		hasIDs = hasIDs || len(m.Event.IDFields) > 0
+		hasVersions = hasVersions || m.Event.Version > 1
+		hasReplies = hasReplies || m.Reply != nil
 	}
 
-	ce := cloudevents.NewEvent()
-	ce.SetID(uuid.New().String())
-	ce.SetSpecVersion(cloudevents.VersionV1)
-	ce.SetTime(time.Now())
-	ce.SetType(eventType)
-	ce.SetSource(options.source)
-	ce.SetSubject(subject)
-
-	for k, v := range options.extensions {
-		ce.SetExtension(k, v)
+	// Execute template
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]any{
+		"Package":              file.GoPackageName,
+		"RuntimeImportPath":    cfg.RuntimeImportPath,
+		"Imports":              imports.Imports(),
+		"EmitGroupSubscribers": cfg.EmitGroupSubscribers,
+		"EmitRegistration":     cfg.EmitRegistration,
+		"HasSubjects":          hasSubjects,
+		"HasPartitionKeys":     hasPartitionKeys,
+		"HasIDs":               hasIDs,
+		"HasVersions":          hasVersions,
+		"HasReplies":           hasReplies,
+		"ImportTime":           importTime,
+		"ImportRegexp":         len(validation.Patterns) > 0,
+		"Extensions":           extensions,
+		"Messages":             messages,
+		"RegisterFunc":         registerFunc,
+		"Handlers":             handlers,
+		"Validation":           validation,
+	}); err != nil {
+		return fmt.Errorf("%s: execute template: %w", file.Desc.Path(), err)
 	}
 
-	if payload != nil {
-		if err := ce.SetData(cloudevents.ApplicationJSON, payload); err != nil {
-			return nil, "", fmt.Errorf("events: set data for type %s: %w", eventType, err)
-		}
+	// Format code
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s: format generated code: %w", file.Desc.Path(), err)
 	}
 
-	return &ce, subject, nil
-}
+	g.P(string(formatted))
 
-func subscribeEvent[T any](ctx context.Context, bus Subscriber, eventType string,
-	handler func(context.Context, *T) error) error {
-	if handler == nil {
-		return errors.New("events: handler is required")
+	if cfg.EmitTestRecorder && len(messages) > 0 {
+		return generateRecorder(gen, file, cfg, messages, funcNames)
 	}
-
-	return bus.Subscribe(ctx, eventType, func(eventCtx context.Context, event *cloudevents.Event) error {
-		var payload T
-		if data := event.Data(); len(data) > 0 {
-			if err := event.DataAs(&payload); err != nil {
-				return fmt.Errorf("events: decode payload for %s: %w", eventType, err)
-			}
-		}
-		return handler(eventCtx, &payload)
-	})
+	return nil
 }
 
-func subscribeEventWithGroup[T any](ctx context.Context, bus HandlerGroupSubscriber,
-	eventType string, group string, handler func(context.Context, *T) error) error {
-	if handler == nil {
-		return errors.New("events: handler is required")
+// fileGoName returns the exported Go name derived from the base name of file,
+// e.g. OrderEvents for shop/order_events.proto
+func fileGoName(file *protogen.File) string {
+	base := strings.TrimSuffix(path.Base(file.Desc.Path()), ".proto")
+	var name strings.Builder
+	for _, part := range strings.FieldsFunc(base, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		first, size := utf8.DecodeRuneInString(part)
+		name.WriteRune(unicode.ToUpper(first))
+		name.WriteString(part[size:])
 	}
-	if group == "" {
-		return errors.New("events: group is required")
-	}
-
-	return bus.SubscribeWithHandlerGroup(ctx, eventType, group,
-		func(eventCtx context.Context, event *cloudevents.Event) error {
-			var payload T
-			if data := event.Data(); len(data) > 0 {
-				if err := event.DataAs(&payload); err != nil {
-					return fmt.Errorf("events: decode payload for %s: %w", eventType, err)
-				}
-			}
-			return handler(eventCtx, &payload)
-		})
+	return name.String()
 }
 
-// ============================================================
-// Publish Functions
-// ============================================================
+// collectMessages walks msgs and their nested messages, appending those with the event_meta option
+func collectMessages(msgs []*protogen.Message, cfg *params, events eventIndex,
+	funcNames map[string]protoreflect.FullName, messages *[]*messageInfo) error {
+	for _, msg := range msgs {
+		if msg.Desc.IsMapEntry() {
+			continue
+		}
 
-{{- range .Messages }}
+		if eventMeta := events[msg.Desc.FullName()]; eventMeta != nil {
+			encoding := cfg.Encoding
+			if eventMeta.Encoding != "" {
+				encoding = eventMeta.Encoding
+			}
 
-// Publish{{ toFuncName .Name }} publishes {{ .Event.Description }} event
-// The event source must be specified using WithSource() option
-func Publish{{ toFuncName .Name }}(ctx context.Context, bus Publisher,
-	payload *{{ .Name }}, opts ...PublishOption) error {
-	if payload == nil {
-		return errors.New("events: payload is required")
-	}
-	event, subject, err := buildEvent(EventType{{ toFuncName .Name }}, payload, opts)
-	if err != nil {
-		return err
+			funcName := toFuncName(msg.GoIdent.GoName, cfg.PayloadSuffix)
+			if other, ok := funcNames[funcName]; ok {
+				return errorf(msg.Desc, "function name %q collides with message %s", funcName, other)
+			}
+			funcNames[funcName] = msg.Desc.FullName()
+
+			*messages = append(*messages, &messageInfo{
+				Message:  msg,
+				Name:     msg.GoIdent.GoName,
+				FuncName: funcName,
+				Codec:    encodingCodecs[encoding],
+				Event:    eventMeta,
+			})
+		}
+
+		if err := collectMessages(msg.Messages, cfg, events, funcNames, messages); err != nil {
+			return err
+		}
 	}
-	return bus.Publish(ctx, subject, event)
+	return nil
 }
-{{- end }}
-
-// ============================================================
-// Subscribe Functions (Broadcast Mode)
-// ============================================================
-
-{{- range .Messages }}
 
-// Subscribe{{ toFuncName .Name }} subscribes to {{ .Event.Description }} events (broadcast mode)
-// All subscribers will receive the event
-func Subscribe{{ toFuncName .Name }}(ctx context.Context, bus Subscriber,
-	handler func(context.Context, *{{ .Name }}) error) error {
-	return subscribeEvent(ctx, bus, EventType{{ toFuncName .Name }}, handler)
+type messageInfo struct {
+	Message  *protogen.Message
+	Name     string
+	FuncName string
+	Codec    string
+	Event    *eventDescriptor
+	// RequiredExtensions are the extensions every published event must carry
+	RequiredExtensions []*extensionDescriptor
+	// Reply is the reply event of a request event, nil for other events
+	Reply *eventRef
 }
-{{- end }}
-
-// ============================================================
-// Subscribe Functions (Handler Group Mode)
-// ============================================================
 
-{{- range .Messages }}
+// DefaultOptions returns the publish options derived from the payload, which options passed by the caller override
+func (m *messageInfo) DefaultOptions() []string {
+	var opts []string
+	if m.Event.Subject != nil {
+		opts = append(opts, "runtime.WithSubject("+m.FuncName+"Subject(payload))")
+	}
+	if m.Event.PartitionKey != nil {
+		opts = append(opts, "runtime.WithPartitionKey("+m.FuncName+"PartitionKey(payload))")
+	}
+	if len(m.Event.IDFields) > 0 {
+		opts = append(opts, "runtime.WithID("+m.FuncName+"ID(payload))")
+	}
+	return opts
+}
 
-// Subscribe{{ toFuncName .Name }}WithGroup subscribes to {{ .Event.Description }} events (handler group mode)
-// Subscribers in the same group will compete for message consumption (load balancing)
-func Subscribe{{ toFuncName .Name }}WithGroup(ctx context.Context, bus HandlerGroupSubscriber,
-	group string, handler func(context.Context, *{{ .Name }}) error) error {
-	return subscribeEventWithGroup(ctx, bus, EventType{{ toFuncName .Name }}, group, handler)
+func toFuncName(goName, suffix string) string {
+	// UserRegisteredPayload -> UserRegistered
+	// Order_CreatedPayload (nested Order.CreatedPayload) -> OrderCreated
+	name := goName
+	if trimmed := strings.TrimSuffix(name, suffix); trimmed != "" {
+		name = trimmed
+	}
+	return strings.ReplaceAll(name, "_", "")
 }
-{{- end }}
-`))
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"flag"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	cloudevents "github.com/yafeiaa/protoc-gen-cloudevents-go/proto/cloudevents"
+)
+
+// update 重新生成 testdata/golden 下的期望输出: go test ./cmd/protoc-gen-cloudevents -update
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// fixturePackage 是描述符夹具的 go_package，golden 文件以相对它的路径保存
+const fixturePackage = "example.com/shop/events"
+
+// goModule 是 compileGenerated 编译生成代码所用的模块，生成代码的 go_package 都在其下
+const goModule = "example.com"
+
+// loadFixture 读取 testdata/events.binpb，即 testdata/events.proto 的 FileDescriptorSet，由以下命令生成:
+//
+//	protoc -I testdata -I ../../proto --include_imports --include_source_info \
+//	  --descriptor_set_out=testdata/events.binpb events.proto
+func loadFixture(t *testing.T) []*descriptorpb.FileDescriptorProto {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "events.binpb"))
+	require.NoError(t, err)
+	var set descriptorpb.FileDescriptorSet
+	require.NoError(t, proto.Unmarshal(data, &set))
+	return set.File
+}
+
+// parseFile 解析文本格式的 FileDescriptorProto，并在前面加上其可能依赖的 descriptor.proto 与 event_meta.proto
+func parseFile(t *testing.T, texts ...string) []*descriptorpb.FileDescriptorProto {
+	t.Helper()
+	files := []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+		protodesc.ToFileDescriptorProto(cloudevents.File_cloudevents_event_meta_proto),
+	}
+	for _, text := range texts {
+		file := &descriptorpb.FileDescriptorProto{}
+		require.NoError(t, prototext.Unmarshal([]byte(text), file))
+		files = append(files, file)
+	}
+	return files
+}
+
+// newPlugin 创建生成 files 中名为 generate 的文件的插件，generate 为空时生成最后一个文件，
+// param 为 --cloudevents_opt 参数
+func newPlugin(t *testing.T, param string, files []*descriptorpb.FileDescriptorProto,
+	generate ...string) (*protogen.Plugin, *params) {
+	t.Helper()
+	var (
+		flags flag.FlagSet
+		cfg   params
+	)
+	cfg.register(&flags)
+	if len(generate) == 0 {
+		generate = []string{files[len(files)-1].GetName()}
+	}
+	gen, err := protogen.Options{ParamFunc: flags.Set}.New(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: generate,
+		Parameter:      proto.String(param),
+		ProtoFile:      files,
+	})
+	require.NoError(t, err)
+	return gen, &cfg
+}
+
+// findMessage 返回插件中全名为 name 的消息
+func findMessage(t *testing.T, gen *protogen.Plugin, name protoreflect.FullName) *protogen.Message {
+	t.Helper()
+	var find func(msgs []*protogen.Message) *protogen.Message
+	find = func(msgs []*protogen.Message) *protogen.Message {
+		for _, msg := range msgs {
+			if msg.Desc.FullName() == name {
+				return msg
+			}
+			if found := find(msg.Messages); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	for _, f := range gen.Files {
+		if msg := find(f.Messages); msg != nil {
+			return msg
+		}
+	}
+	t.Fatalf("message %s not found", name)
+	return nil
+}
+
+// findField 返回消息 msg 名为 name 的字段
+func findField(t *testing.T, msg *protogen.Message, name protoreflect.Name) *protogen.Field {
+	t.Helper()
+	for _, field := range msg.Fields {
+		if field.Desc.Name() == name {
+			return field
+		}
+	}
+	t.Fatalf("field %s not found in %s", name, msg.Desc.FullName())
+	return nil
+}
+
+// assertGolden 以参数 param 对描述符夹具运行插件，将生成的文件与 testdata/golden/dir 下的同名文件比较，
+// 并返回生成的文件
+func assertGolden(t *testing.T, param, dir string) []*pluginpb.CodeGeneratorResponse_File {
+	t.Helper()
+	gen, cfg := newPlugin(t, param, loadFixture(t))
+	require.NoError(t, generate(gen, cfg))
+	resp := gen.Response()
+	require.Empty(t, resp.GetError())
+
+	goldenDir := filepath.Join("testdata", "golden", dir)
+	if *update {
+		require.NoError(t, os.RemoveAll(goldenDir))
+	}
+
+	var names []string
+	for _, f := range resp.File {
+		name := strings.TrimPrefix(f.GetName(), fixturePackage+"/")
+		names = append(names, name)
+		golden := filepath.Join(goldenDir, filepath.FromSlash(name))
+		if *update {
+			require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+			require.NoError(t, os.WriteFile(golden, []byte(f.GetContent()), 0o644))
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		require.NoError(t, err, "run go test with -update to create the golden file")
+		assert.Equal(t, string(want), f.GetContent(), "%s differs from %s, run go test with -update to accept the changes",
+			f.GetName(), golden)
+	}
+
+	var goldens []string
+	require.NoError(t, filepath.WalkDir(goldenDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(goldenDir, path)
+		goldens = append(goldens, filepath.ToSlash(rel))
+		return err
+	}))
+	assert.ElementsMatch(t, goldens, names, "generated files differ from %s", goldenDir)
+	return resp.File
+}
+
+// protocGenGo 返回 protoc-gen-go 为 files 中要生成的文件生成的消息代码，generate 的含义同 newPlugin
+func protocGenGo(t *testing.T, files []*descriptorpb.FileDescriptorProto,
+	generate ...string) []*pluginpb.CodeGeneratorResponse_File {
+	t.Helper()
+	gen, _ := newPlugin(t, "", files, generate...)
+	for _, f := range gen.Files {
+		if f.Generate {
+			gengo.GenerateFile(gen, f)
+		}
+	}
+	resp := gen.Response()
+	require.Empty(t, resp.GetError())
+	return resp.File
+}
+
+// compileGenerated 将生成的文件写入一个依赖当前工作区的临时模块 goModule，并用 go vet 编译检查
+func compileGenerated(t *testing.T, files ...*pluginpb.CodeGeneratorResponse_File) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping compilation of generated code in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	require.NoError(t, err)
+	modFile, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	require.NoError(t, err)
+	sumFile, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	require.NoError(t, err)
+
+	// 临时模块沿用本仓库的依赖版本，并将本仓库替换为当前工作区
+	const repoModule = "github.com/yafeiaa/protoc-gen-cloudevents-go"
+	mod := strings.Replace(string(modFile), "module "+repoModule, "module "+goModule, 1) +
+		"\nrequire " + repoModule + " v0.0.0\n\nreplace " + repoModule + " => " + root + "\n"
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), sumFile, 0o644))
+
+	for _, f := range files {
+		name, ok := strings.CutPrefix(f.GetName(), goModule+"/")
+		require.True(t, ok, "%s is not in module %s", f.GetName(), goModule)
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(f.GetContent()), 0o644))
+	}
+
+	cmd := exec.Command(goBin, "vet", "-mod=mod", "./...")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "generated code does not compile:\n%s", out)
+}
+
+// TestGenerate_Go 测试描述符夹具以默认参数和非默认参数生成的 Go 代码与 golden 文件一致且可以编译
+func TestGenerate_Go(t *testing.T) {
+	tests := []struct {
+		name  string
+		param string
+	}{
+		{name: "default"},
+		{name: "custom", param: "payload_suffix=,emit_group_subscribers=false,emit_registration=false," +
+			"emit_test_recorder=true,encoding=json,filename_suffix=.events.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := assertGolden(t, tt.param, filepath.Join("go", tt.name))
+			compileGenerated(t, append(protocGenGo(t, loadFixture(t)), files...)...)
+		})
+	}
+}
+
+// TestGenerate_NestedMessages 测试嵌套事件消息的函数名去掉下划线与载荷后缀
+func TestGenerate_NestedMessages(t *testing.T) {
+	gen, cfg := newPlugin(t, "", loadFixture(t))
+	require.NoError(t, generate(gen, cfg))
+	content := gen.Response().File[0].GetContent()
+	assert.Contains(t, content, `EventTypeOrderShipped = "shop.order.shipped"`)
+	assert.Contains(t, content, "EventOrderShipped = &runtime.Event[Order_ShippedPayload]{")
+	assert.Contains(t, content, "func PublishOrderShipped(ctx context.Context, bus runtime.Publisher,\n\tpayload *Order_ShippedPayload,")
+	assert.Contains(t, content, "func SubscribeOrderShipped(")
+}
+
+// collidingFiles 中 a.proto 的 OrderCreatedPayload 与 b.proto 的嵌套消息 Order.CreatedPayload 生成相同的函数名
+var collidingFiles = []string{`
+name: "a.proto"
+package: "shop"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+options { go_package: "example.com/shop;shop" }
+message_type {
+  name: "OrderCreatedPayload"
+  options { [cloudevents.event_meta] { event_type: "shop.order.created" } }
+}
+`, `
+name: "b.proto"
+package: "shop"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+options { go_package: "example.com/shop;shop" }
+message_type {
+  name: "Order"
+  nested_type {
+    name: "CreatedPayload"
+    options { [cloudevents.event_meta] { event_type: "shop.order.placed" } }
+  }
+}
+`}
+
+// TestGenerate_FuncNameCollision 测试同一 Go 包的不同文件中生成相同函数名的事件会报错
+func TestGenerate_FuncNameCollision(t *testing.T) {
+	gen, cfg := newPlugin(t, "", parseFile(t, collidingFiles...), "a.proto", "b.proto")
+	err := generate(gen, cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "b.proto:")
+	assert.Contains(t, err.Error(), `shop.Order.CreatedPayload: function name "OrderCreated" collides with message shop.OrderCreatedPayload`)
+
+	// 不同 Go 包中的同名函数互不影响
+	files := parseFile(t, collidingFiles...)
+	files[len(files)-1].Options.GoPackage = proto.String("example.com/shop/placed;placed")
+	gen, cfg = newPlugin(t, "", files, "a.proto", "b.proto")
+	assert.NoError(t, generate(gen, cfg))
+
+	// 文件名映射到同一个注册函数
+	files = parseFile(t, packageFiles...)
+	files[len(files)-1].Name = proto.String("shop/orders-.proto")
+	gen, cfg = newPlugin(t, "", files, "shop/orders.proto", "shop/orders-.proto")
+	assert.EqualError(t, generate(gen, cfg),
+		`shop/orders-.proto: function name "RegisterOrdersEvents" collides with shop/orders.proto`)
+}
+
+// packageFiles 是同一 Go 包的两个文件，各自声明一个事件
+var packageFiles = []string{`
+name: "shop/orders.proto"
+package: "shop"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+options { go_package: "example.com/shop;shop" }
+message_type {
+  name: "OrderCreatedPayload"
+  options { [cloudevents.event_meta] { event_type: "shop.order.created" } }
+  field { name: "order_id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "orderId" }
+}
+`, `
+name: "shop/user-events.proto"
+package: "shop"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+options { go_package: "example.com/shop;shop" }
+message_type {
+  name: "UserRegisteredPayload"
+  options { [cloudevents.event_meta] { event_type: "shop.user.registered" } }
+  field { name: "user_id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "userId" }
+}
+`}
+
+// TestGenerate_SeparateRuns 测试同一 Go 包的文件由不同的 protoc 调用生成时，合在一起仍可以编译，包括测试包
+func TestGenerate_SeparateRuns(t *testing.T) {
+	files := parseFile(t, packageFiles...)
+	generated := protocGenGo(t, files, "shop/orders.proto", "shop/user-events.proto")
+	for _, name := range []string{"shop/orders.proto", "shop/user-events.proto"} {
+		gen, cfg := newPlugin(t, "emit_test_recorder=true", files, name)
+		require.NoError(t, generate(gen, cfg))
+		resp := gen.Response()
+		require.Empty(t, resp.GetError())
+		generated = append(generated, resp.File...)
+	}
+
+	var names, contents []string
+	for _, f := range generated {
+		names = append(names, f.GetName())
+		contents = append(contents, f.GetContent())
+	}
+	assert.Subset(t, names, []string{
+		"example.com/shop/orders_events.pb.go", "example.com/shop/user-events_events.pb.go",
+		"example.com/shop/shoptest/orders_events.pb.go", "example.com/shop/shoptest/user-events_events.pb.go",
+	})
+	all := strings.Join(contents, "\n")
+	assert.Contains(t, all, "func RegisterOrdersEvents(r *runtime.Registry) error {")
+	assert.Contains(t, all, "func RegisterUserEventsEvents(r *runtime.Registry) error {")
+	assert.Contains(t, all, "func AssertPublishedOrderCreated(t testing.TB, r *runtime.Recorder,")
+	assert.Contains(t, all, "func AssertPublishedUserRegistered(t testing.TB, r *runtime.Recorder,")
+	compileGenerated(t, generated...)
+}
+
+// TestGenerate_RecorderFuncCollision 测试测试包中按事件生成的函数名冲突会报错
+func TestGenerate_RecorderFuncCollision(t *testing.T) {
+	files := parseFile(t, `
+name: "shop/orders.proto"
+package: "shop"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+options { go_package: "example.com/shop;shop" }
+message_type {
+  name: "OrderPayload"
+  options { [cloudevents.event_meta] { event_type: "shop.order.placed" } }
+}
+message_type {
+  name: "OrderEnvelopesPayload"
+  options { [cloudevents.event_meta] { event_type: "shop.order.enveloped" } }
+}
+`)
+	gen, cfg := newPlugin(t, "", files)
+	require.NoError(t, generate(gen, cfg))
+
+	gen, cfg = newPlugin(t, "emit_test_recorder=true", files)
+	err := generate(gen, cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"shop.OrderEnvelopesPayload: recorder function OrderEnvelopes collides with shop.OrderPayload")
+}
+
+// TestGenerate_EnumKeys 测试分区键与确定性 id 使用枚举字段的数值而不是名称
+func TestGenerate_EnumKeys(t *testing.T) {
+	files := parseFile(t, `
+name: "shop/orders.proto"
+package: "shop"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+options { go_package: "example.com/shop;shop" }
+message_type {
+  name: "OrderUpdatedPayload"
+  options { [cloudevents.event_meta] {
+    event_type: "shop.order.updated" partition_key: "status" id_fields: ["order_id", "status"]
+  } }
+  field { name: "order_id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "orderId" }
+  field { name: "status" number: 2 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".shop.Status" json_name: "status" }
+}
+enum_type {
+  name: "Status"
+  value { name: "STATUS_UNSPECIFIED" number: 0 }
+  value { name: "STATUS_PAID" number: 1 }
+}
+`)
+	gen, cfg := newPlugin(t, "", files)
+	require.NoError(t, generate(gen, cfg))
+	resp := gen.Response()
+	require.Empty(t, resp.GetError())
+	content := resp.File[0].GetContent()
+	assert.Contains(t, content, "return runtime.PartitionKeyValue(int32(payload.GetStatus()))")
+	assert.Contains(t, content,
+		"return runtime.DeterministicID(EventTypeOrderUpdated, payload.GetOrderId(), int32(payload.GetStatus()))")
+	compileGenerated(t, append(protocGenGo(t, files), resp.File...)...)
+}
+
+// TestGenerate_InvalidParams 测试无效的插件参数
+func TestGenerate_InvalidParams(t *testing.T) {
+	for _, param := range []string{
+		"mode=yaml",
+		"doc_format=pdf",
+		"asyncapi_action=publish",
+		"encoding=xml",
+		"filename_suffix=.txt",
+		"dataschema_base_url=schemas",
+		"runtime_import_path=",
+	} {
+		gen, cfg := newPlugin(t, param, loadFixture(t))
+		assert.Error(t, generate(gen, cfg), param)
+	}
+}
+
+// TestGenerate_Upcasters 测试只为版本大于 1 的事件生成 upcaster 注册函数
+func TestGenerate_Upcasters(t *testing.T) {
+	gen, cfg := newPlugin(t, "", loadFixture(t))
+	require.NoError(t, generate(gen, cfg))
+	content := gen.Response().File[0].GetContent()
+	assert.Contains(t, content, "func RegisterOrderCreatedUpcaster(")
+	assert.NotContains(t, content, "func RegisterOrderShippedUpcaster(")
+
+	gen, cfg = newPlugin(t, "", parseFile(t, `
+name: "v1.proto"
+package: "v1"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+options { go_package: "example.com/v1;v1" }
+message_type {
+  name: "CreatedPayload"
+  options { [cloudevents.event_meta] { event_type: "v1.order.created" version: 1 } }
+}
+`))
+	require.NoError(t, generate(gen, cfg))
+	content = gen.Response().File[0].GetContent()
+	assert.Contains(t, content, "Version: 1,")
+	assert.NotContains(t, content, "Upcaster")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"text/template"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// recordedEvent is an event with typed accessors and assertions on a runtime.Recorder
+type recordedEvent struct {
+	*eventRef
+	FuncName string
+}
+
+// generateRecorder generates the test package of file: typed accessors and assertions on a runtime.Recorder
+// for the events of the file. The package is named after the Go package of file with a test suffix and lives
+// in a subdirectory of it. It only declares per-event functions, so that files of one Go package can be
+// generated by separate protoc runs
+func generateRecorder(gen *protogen.Plugin, file *protogen.File, cfg *params, messages []*messageInfo,
+	funcNames map[string]protoreflect.FullName) error {
+	pkgName := string(file.GoPackageName) + "test"
+	importPath := protogen.GoImportPath(path.Join(string(file.GoImportPath), pkgName))
+	imports := newGoImports(importPath)
+
+	var events []*recordedEvent
+	for _, m := range messages {
+		funcs := []string{m.FuncName, m.FuncName + "Envelopes", "AssertPublished" + m.FuncName,
+			"AssertNotPublished" + m.FuncName}
+		for _, name := range funcs {
+			if other, ok := funcNames[pkgName+"."+name]; ok {
+				return errorf(m.Message.Desc, "recorder function %s collides with %s", name, other)
+			}
+			funcNames[pkgName+"."+name] = m.Message.Desc.FullName()
+		}
+		events = append(events, &recordedEvent{
+			eventRef: newEventRef(gen, cfg, imports, m.Message, m.Event),
+			FuncName: m.FuncName,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := recorderTmpl.Execute(&buf, map[string]any{
+		"Package":           pkgName,
+		"RuntimeImportPath": cfg.RuntimeImportPath,
+		"Imports":           imports.Imports(),
+		"Events":            events,
+	}); err != nil {
+		return fmt.Errorf("%s: execute recorder template: %w", file.Desc.Path(), err)
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s: format generated recorder: %w", file.Desc.Path(), err)
+	}
+
+	prefix := file.GeneratedFilenamePrefix
+	filename := path.Join(path.Dir(prefix), pkgName, path.Base(prefix)+cfg.FilenameSuffix)
+	gen.NewGeneratedFile(filename, importPath).P(string(formatted))
+	return nil
+}
+
+var recorderTmpl = template.Must(template.New("recorder").Parse(`// Code generated by protoc-gen-cloudevents. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"testing"
+
+	runtime "{{ .RuntimeImportPath }}"
+{{- range .Imports }}
+	{{ .Name }} {{ .Path }}
+{{- end }}
+)
+{{- range .Events }}
+
+// {{ .FuncName }} returns the payloads of the {{ .Event.EventType }} events recorded by r, in publish order
+func {{ .FuncName }}(t testing.TB, r *runtime.Recorder) []*{{ .Payload }} {
+	t.Helper()
+	return runtime.RecordedPayloads(t, r, {{ .Descriptor }})
+}
+
+// {{ .FuncName }}Envelopes returns the {{ .Event.EventType }} events recorded by r with their CloudEvents
+// attributes, in publish order
+func {{ .FuncName }}Envelopes(t testing.TB, r *runtime.Recorder) []*runtime.Envelope[{{ .Payload }}] {
+	t.Helper()
+	return runtime.RecordedEnvelopes(t, r, {{ .Descriptor }})
+}
+
+// AssertPublished{{ .FuncName }} fails the test unless r recorded a {{ .Event.EventType }} event whose payload
+// satisfies all of match, and returns the first such payload
+func AssertPublished{{ .FuncName }}(t testing.TB, r *runtime.Recorder,
+	match ...func(*{{ .Payload }}) bool) *{{ .Payload }} {
+	t.Helper()
+	return runtime.AssertPublished(t, r, {{ .Descriptor }}, match...)
+}
+
+// AssertNotPublished{{ .FuncName }} fails the test if r recorded a {{ .Event.EventType }} event whose payload
+// satisfies all of match
+func AssertNotPublished{{ .FuncName }}(t testing.TB, r *runtime.Recorder, match ...func(*{{ .Payload }}) bool) {
+	t.Helper()
+	runtime.AssertNotPublished(t, r, {{ .Descriptor }}, match...)
+}
+{{- end }}
+`))
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// subjectTemplate is a parsed EventMeta.subject_template such as "myapp.order.created.{currency}.{user_id}"
+type subjectTemplate struct {
+	Raw    string
+	Tokens []*subjectToken
+}
+
+// subjectToken is one dot-separated token of a subject template,
+// either a literal or a placeholder bound to a payload field path
+type subjectToken struct {
+	Literal string
+	Path    string
+	Fields  []*protogen.Field
+}
+
+// IsField reports whether the token is a placeholder
+func (t *subjectToken) IsField() bool {
+	return t.Fields != nil
+}
+
+// GoName is the name of the filter struct field for a placeholder token, e.g. CustomerId for {customer.id}
+func (t *subjectToken) GoName() string {
+	var name string
+	for _, f := range t.Fields {
+		name += f.GoName
+	}
+	return name
+}
+
+// Getter is the nil-safe getter chain reading the placeholder value from payload
+func (t *subjectToken) Getter() string {
+	expr := "payload"
+	for _, f := range t.Fields {
+		expr += ".Get" + f.GoName + "()"
+	}
+	return expr
+}
+
+// Value is the Getter expression converted for partition keys and deterministic ids: enums are read as their
+// numeric value, which unlike the value name stays stable when the enum value is renamed
+func (t *subjectToken) Value() string {
+	if t.Fields[len(t.Fields)-1].Desc.Kind() == protoreflect.EnumKind {
+		return "int32(" + t.Getter() + ")"
+	}
+	return t.Getter()
+}
+
+// Fields returns the placeholder tokens of the template
+func (t *subjectTemplate) Fields() []*subjectToken {
+	var fields []*subjectToken
+	for _, tok := range t.Tokens {
+		if tok.IsField() {
+			fields = append(fields, tok)
+		}
+	}
+	return fields
+}
+
+// SubjectExpr is the Go expression building the subject from payload
+func (t *subjectTemplate) SubjectExpr() string {
+	return t.expr(func(tok *subjectToken) string {
+		return "runtime.SubjectToken(" + tok.Getter() + ")"
+	})
+}
+
+// FilterExpr is the Go expression building the subject pattern from a filter value f
+func (t *subjectTemplate) FilterExpr() string {
+	return t.expr(func(tok *subjectToken) string {
+		return "runtime.SubjectFilterToken(f." + tok.GoName() + ")"
+	})
+}
+
+// expr concatenates the tokens, merging adjacent literals into a single string constant
+func (t *subjectTemplate) expr(field func(*subjectToken) string) string {
+	var (
+		parts   []string
+		literal strings.Builder
+	)
+	for i, tok := range t.Tokens {
+		if i > 0 {
+			literal.WriteString(".")
+		}
+		if !tok.IsField() {
+			literal.WriteString(tok.Literal)
+			continue
+		}
+		if literal.Len() > 0 {
+			parts = append(parts, strconv.Quote(literal.String()))
+			literal.Reset()
+		}
+		parts = append(parts, field(tok))
+	}
+	if literal.Len() > 0 {
+		parts = append(parts, strconv.Quote(literal.String()))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// parseSubjectTemplate parses raw against the fields of msg. Every dot-separated token must be
+// either a literal or a single {field.path} placeholder referring to a singular scalar field
+func parseSubjectTemplate(msg *protogen.Message, raw string) (*subjectTemplate, error) {
+	parts, err := splitSubjectTemplate(raw)
+	if err != nil {
+		return nil, fmt.Errorf("subject_template %q: %w", raw, err)
+	}
+
+	tmpl := &subjectTemplate{Raw: raw}
+	seen := make(map[string]bool)
+	// Placeholder paths by filter field name, which concatenates the Go names of the fields on the path
+	goNames := make(map[string]string)
+	for _, part := range parts {
+		if !strings.HasPrefix(part, "{") {
+			if part == "" || strings.ContainsAny(part, "{}*> \t\r\n") {
+				return nil, fmt.Errorf("subject_template %q: invalid token %q", raw, part)
+			}
+			tmpl.Tokens = append(tmpl.Tokens, &subjectToken{Literal: part})
+			continue
+		}
+
+		path := part[1 : len(part)-1]
+		if seen[path] {
+			return nil, fmt.Errorf("subject_template %q: placeholder %s is used more than once", raw, part)
+		}
+		seen[path] = true
+
+		fields, err := resolveFieldPath(msg, path)
+		if err != nil {
+			return nil, fmt.Errorf("subject_template %q: %w", raw, err)
+		}
+		if kind := fields[len(fields)-1].Desc.Kind(); !isSubjectKind(kind) {
+			return nil, fmt.Errorf("subject_template %q: field %s has type %s, which cannot be used in a subject",
+				raw, path, kind)
+		}
+		tok := &subjectToken{Path: path, Fields: fields}
+		if other, ok := goNames[tok.GoName()]; ok {
+			return nil, fmt.Errorf("subject_template %q: placeholders {%s} and %s both map to the filter field %s",
+				raw, other, part, tok.GoName())
+		}
+		goNames[tok.GoName()] = path
+		tmpl.Tokens = append(tmpl.Tokens, tok)
+	}
+
+	if len(tmpl.Fields()) == 0 {
+		return nil, fmt.Errorf("subject_template %q has no {field} placeholder", raw)
+	}
+	return tmpl, nil
+}
+
+// splitSubjectTemplate splits raw on the dots outside of placeholders.
+// A placeholder must make up a whole token
+func splitSubjectTemplate(raw string) ([]string, error) {
+	var (
+		parts []string
+		start int
+		open  = -1
+	)
+	for i, c := range raw {
+		switch c {
+		case '{':
+			if open >= 0 || i != start {
+				return nil, fmt.Errorf("placeholder at offset %d must make up a whole token", i)
+			}
+			open = i
+		case '}':
+			switch {
+			case open < 0:
+				return nil, fmt.Errorf("unexpected '}' at offset %d", i)
+			case i == open+1:
+				return nil, fmt.Errorf("empty placeholder at offset %d", open)
+			case i+1 < len(raw) && raw[i+1] != '.':
+				return nil, fmt.Errorf("placeholder at offset %d must make up a whole token", open)
+			}
+			open = -1
+		case '.':
+			if open < 0 {
+				parts = append(parts, raw[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if open >= 0 {
+		return nil, fmt.Errorf("unterminated placeholder at offset %d", open)
+	}
+	return append(parts, raw[start:]), nil
+}
+
+// resolveFieldPath resolves a dotted proto field path such as "customer.id" against msg.
+// Every field on the path must be singular, and all but the last must be messages
+func resolveFieldPath(msg *protogen.Message, path string) ([]*protogen.Field, error) {
+	var fields []*protogen.Field
+	current := msg
+	for i, name := range strings.Split(path, ".") {
+		if current == nil {
+			return nil, fmt.Errorf("field path %s: %s is not a message", path, strings.Join(strings.Split(path, ".")[:i], "."))
+		}
+		var field *protogen.Field
+		for _, f := range current.Fields {
+			if string(f.Desc.Name()) == name {
+				field = f
+				break
+			}
+		}
+		if field == nil {
+			return nil, fmt.Errorf("field path %s: message %s has no field %q", path, current.Desc.FullName(), name)
+		}
+		if field.Desc.IsList() || field.Desc.IsMap() {
+			return nil, fmt.Errorf("field path %s: field %s is repeated", path, field.Desc.FullName())
+		}
+		fields = append(fields, field)
+		current = field.Message
+	}
+	return fields, nil
+}
+
+func isSubjectKind(kind protoreflect.Kind) bool {
+	switch kind {
+	case protoreflect.MessageKind, protoreflect.GroupKind, protoreflect.BytesKind,
+		protoreflect.FloatKind, protoreflect.DoubleKind:
+		return false
+	default:
+		return true
+	}
+}
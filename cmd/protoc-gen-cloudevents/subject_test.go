@@ -0,0 +1,110 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSplitSubjectTemplate 测试按占位符外的点拆分 subject 模板
+func TestSplitSubjectTemplate(t *testing.T) {
+	tests := []struct {
+		raw   string
+		parts []string
+		err   string
+	}{
+		{raw: "shop.order.created", parts: []string{"shop", "order", "created"}},
+		{raw: "shop.{currency}.{customer.id}", parts: []string{"shop", "{currency}", "{customer.id}"}},
+		{raw: "{order_id}", parts: []string{"{order_id}"}},
+		{raw: "shop..created", parts: []string{"shop", "", "created"}},
+		{raw: "shop.order{id}", err: "placeholder at offset 10 must make up a whole token"},
+		{raw: "shop.{id}x", err: "placeholder at offset 5 must make up a whole token"},
+		{raw: "shop.{a{b}}", err: "placeholder at offset 7 must make up a whole token"},
+		{raw: "shop.{}", err: "empty placeholder at offset 5"},
+		{raw: "shop.}", err: "unexpected '}' at offset 5"},
+		{raw: "shop.{id", err: "unterminated placeholder at offset 5"},
+	}
+	for _, tt := range tests {
+		parts, err := splitSubjectTemplate(tt.raw)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err, tt.raw)
+			continue
+		}
+		require.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.parts, parts, tt.raw)
+	}
+}
+
+// TestParseSubjectTemplate 测试解析 subject 模板并生成 subject 与过滤表达式
+func TestParseSubjectTemplate(t *testing.T) {
+	gen, _ := newPlugin(t, "", loadFixture(t))
+	msg := findMessage(t, gen, "shop.events.OrderCreatedPayload")
+
+	tmpl, err := parseSubjectTemplate(msg, "shop.order.created.{currency}.{customer.id}.eu")
+	require.NoError(t, err)
+	require.Len(t, tmpl.Tokens, 6)
+	fields := tmpl.Fields()
+	require.Len(t, fields, 2)
+	assert.Equal(t, "customer.id", fields[1].Path)
+	assert.Equal(t, "CustomerId", fields[1].GoName())
+	assert.Equal(t, "payload.GetCustomer().GetId()", fields[1].Getter())
+	assert.Equal(t, `"shop.order.created." + runtime.SubjectToken(payload.GetCurrency()) + "." + `+
+		`runtime.SubjectToken(payload.GetCustomer().GetId()) + ".eu"`, tmpl.SubjectExpr())
+	assert.Equal(t, `"shop.order.created." + runtime.SubjectFilterToken(f.Currency) + "." + `+
+		`runtime.SubjectFilterToken(f.CustomerId) + ".eu"`, tmpl.FilterExpr())
+
+	tests := []struct {
+		raw string
+		err string
+	}{
+		{raw: "shop.order.created", err: "has no {field} placeholder"},
+		{raw: "shop..{order_id}", err: `invalid token ""`},
+		{raw: "shop.*.{order_id}", err: `invalid token "*"`},
+		{raw: "shop.>.{order_id}", err: `invalid token ">"`},
+		{raw: "shop.{order_id}.{order_id}", err: "placeholder {order_id} is used more than once"},
+		{raw: "shop.{missing}", err: `message shop.events.OrderCreatedPayload has no field "missing"`},
+		{raw: "shop.{customer.missing}", err: `message shop.events.Customer has no field "missing"`},
+		{raw: "shop.{order_id.value}", err: "field path order_id.value: order_id is not a message"},
+		{raw: "shop.{items}", err: "field shop.events.OrderCreatedPayload.items is repeated"},
+		{raw: "shop.{labels}", err: "field shop.events.OrderCreatedPayload.labels is repeated"},
+		{raw: "shop.{customer}", err: "field customer has type message, which cannot be used in a subject"},
+		{raw: "shop.{signature}", err: "field signature has type bytes, which cannot be used in a subject"},
+		{raw: "shop.{total}", err: "field total has type double, which cannot be used in a subject"},
+		{raw: "shop.{order_id", err: "unterminated placeholder"},
+	}
+	for _, tt := range tests {
+		_, err := parseSubjectTemplate(msg, tt.raw)
+		require.Error(t, err, tt.raw)
+		assert.Contains(t, err.Error(), tt.err, tt.raw)
+		assert.Contains(t, err.Error(), `subject_template "`+tt.raw+`"`, tt.raw)
+	}
+}
+
+// TestParseSubjectTemplate_FilterFieldCollision 测试映射到同一过滤字段名的占位符在消息位置报错
+func TestParseSubjectTemplate_FilterFieldCollision(t *testing.T) {
+	gen, _ := newPlugin(t, "", parseFile(t, `
+name: "collision.proto"
+package: "shop"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+options { go_package: "example.com/shop;shop" }
+message_type {
+  name: "Customer"
+  field { name: "id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "id" }
+}
+message_type {
+  name: "OrderCreatedPayload"
+  options { [cloudevents.event_meta] {
+    event_type: "shop.order.created"
+    subject_template: "shop.order.created.{customer.id}.{customer_id}"
+  } }
+  field { name: "customer" number: 1 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".shop.Customer" json_name: "customer" }
+  field { name: "customer_id" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "customerId" }
+}
+`))
+	_, err := indexEvents(gen, "")
+	require.Error(t, err)
+	assert.Regexp(t, `^collision.proto:\d+:\d+: shop.OrderCreatedPayload: subject_template `, err.Error())
+	assert.Contains(t, err.Error(), "placeholders {customer.id} and {customer_id} both map to the filter field CustomerId")
+}
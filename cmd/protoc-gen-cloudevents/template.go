@@ -0,0 +1,435 @@
+package main
+
+import "text/template"
+
+var tmpl = template.Must(template.New("events").Parse(`// Code generated by protoc-gen-cloudevents. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- if or .Messages .Handlers .Extensions }}
+	"context"
+{{- end }}
+{{- if or .Messages .Handlers }}
+	"errors"
+{{- end }}
+{{- if .ImportRegexp }}
+	"regexp"
+{{- end }}
+{{- if .ImportTime }}
+	"time"
+{{- end }}
+
+	runtime "{{ .RuntimeImportPath }}"
+{{- range .Imports }}
+	{{ .Name }} {{ .Path }}
+{{- end }}
+)
+{{- if .Messages }}
+
+// ============================================================
+// Event Type Definitions
+// ============================================================
+
+const (
+{{- range .Messages }}
+	// EventType{{ .FuncName }} {{ .Event.Description }}
+	EventType{{ .FuncName }} = "{{ .Event.EventType }}"
+{{- end }}
+)
+
+var (
+{{- range .Messages }}
+	// Event{{ .FuncName }} describes the {{ .Event.EventType }} event
+	Event{{ .FuncName }} = &runtime.Event[{{ .Name }}]{
+		Type:  EventType{{ .FuncName }},
+		Codec: runtime.{{ .Codec }},
+{{- with .Event.Description }}
+		Description: {{ printf "%q" . }},
+{{- end }}
+{{- if .Event.Version }}
+		Version: {{ .Event.Version }},
+{{- end }}
+{{- if .Event.DataSchema }}
+		DataSchema: {{ printf "%q" .Event.DataSchema }},
+{{- end }}
+{{- with .RequiredExtensions }}
+		RequiredExtensions: []string{ {{- range $i, $e := . }}{{ if $i }}, {{ end }}Extension{{ $e.GoName }}{{ end -}} },
+{{- end }}
+	}
+{{- end }}
+)
+{{- if .EmitRegistration }}
+
+func init() {
+{{- range .Messages }}
+	runtime.MustRegisterEvent(Event{{ .FuncName }})
+{{- end }}
+}
+{{- end }}
+
+// {{ .RegisterFunc }} registers the events declared in this file into r, e.g. a registry created with
+// runtime.NewRegistry. Events already registered into r are skipped; it fails if r holds another message
+// for one of their types
+func {{ .RegisterFunc }}(r *runtime.Registry) error {
+	return errors.Join(
+{{- range .Messages }}
+		runtime.RegisterEvent(r, Event{{ .FuncName }}),
+{{- end }}
+	)
+}
+{{- end }}
+{{- if .HasSubjects }}
+
+// ============================================================
+// Subject Functions
+// ============================================================
+{{- range $m := .Messages }}
+{{- with $m.Event.Subject }}
+
+// {{ $m.FuncName }}Subject returns the subject {{ $m.Event.Description }} events are published to,
+// derived from the payload using the template "{{ .Raw }}"
+func {{ $m.FuncName }}Subject(payload *{{ $m.Name }}) string {
+	return {{ .SubjectExpr }}
+}
+
+// {{ $m.FuncName }}SubjectFilter selects {{ $m.Event.Description }} events by subject tokens
+// Fields left empty match any value
+type {{ $m.FuncName }}SubjectFilter struct {
+{{- range .Fields }}
+	// {{ .GoName }} matches the {{ "{" }}{{ .Path }}{{ "}" }} token
+	{{ .GoName }} string
+{{- end }}
+}
+
+// Subject returns the subject pattern matching the filter
+func (f {{ $m.FuncName }}SubjectFilter) Subject() string {
+	return {{ .FilterExpr }}
+}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .HasPartitionKeys }}
+
+// ============================================================
+// Partition Key Functions
+// ============================================================
+{{- range $m := .Messages }}
+{{- with $m.Event.PartitionKey }}
+
+// {{ $m.FuncName }}PartitionKey returns the partition key of {{ $m.Event.Description }} events,
+// read from the payload field {{ .Path }}
+func {{ $m.FuncName }}PartitionKey(payload *{{ $m.Name }}) string {
+	return runtime.PartitionKeyValue({{ .Value }})
+}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .HasIDs }}
+
+// ============================================================
+// Event ID Functions
+// ============================================================
+{{- range $m := .Messages }}
+{{- with $m.Event.IDFields }}
+
+// {{ $m.FuncName }}ID returns the deterministic id of {{ $m.Event.Description }} events,
+// derived from the event type and the payload fields {{ range $i, $f := . }}{{ if $i }}, {{ end }}{{ $f.Path }}{{ end }}
+func {{ $m.FuncName }}ID(payload *{{ $m.Name }}) string {
+	return runtime.DeterministicID(EventType{{ $m.FuncName }}{{ range . }}, {{ .Value }}{{ end }})
+}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .Extensions }}
+
+// ============================================================
+// Extensions
+// ============================================================
+
+const (
+{{- range .Extensions }}
+	// Extension{{ .GoName }} is the name of the {{ .Name }} CloudEvents extension{{ with .Description }}: {{ . }}{{ end }}
+	Extension{{ .GoName }} = "{{ .Name }}"
+{{- end }}
+)
+{{- range .Extensions }}
+
+// With{{ .GoName }} sets the {{ .Name }} extension of the published event
+func With{{ .GoName }}(value {{ .GoType }}) runtime.PublishOption {
+	return runtime.WithExtension(Extension{{ .GoName }}, value)
+}
+
+// {{ .GoName }}FromContext returns the {{ .Name }} extension of the event handled with ctx
+// It reports false if the event does not carry the extension or it is not a valid {{ .GoType }}
+func {{ .GoName }}FromContext(ctx context.Context) ({{ .GoType }}, bool) {
+	return runtime.{{ .Getter }}(ctx, Extension{{ .GoName }})
+}
+{{- end }}
+{{- end }}
+{{- with .Validation.Messages }}
+
+// ============================================================
+// Payload Validation
+// ============================================================
+{{- with $.Validation.Patterns }}
+
+var (
+{{- range . }}
+	{{ .Name }} = regexp.MustCompile({{ .Pattern }})
+{{- end }}
+)
+{{- end }}
+{{- range . }}
+
+// Validate checks {{ .GoName }} against the field rules declared in proto
+// It returns a *runtime.ValidationError listing every violation
+func (x *{{ .GoName }}) Validate() error {
+	if x == nil {
+		return nil
+	}
+	var violations runtime.Violations
+{{- range .Checks }}
+	{{ . }}
+{{- end }}
+	return violations.Err()
+}
+{{- end }}
+{{- end }}
+{{- if .HasVersions }}
+
+// ============================================================
+// Upcaster Registration
+// ============================================================
+{{- range .Messages }}
+{{- if gt .Event.Version 1 }}
+
+// Register{{ .FuncName }}Upcaster registers fn to convert {{ .Event.Description }} events published with
+// payload version from (older than {{ .Event.Version }}) into the current payload type
+func Register{{ .FuncName }}Upcaster(from uint32, fn runtime.Upcaster[{{ .Name }}]) {
+	Event{{ .FuncName }}.RegisterUpcaster(from, fn)
+}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .Messages }}
+
+// ============================================================
+// Publish Functions
+// ============================================================
+
+{{- range .Messages }}
+
+// Publish{{ .FuncName }} publishes {{ .Event.Description }} event
+// The event source must be specified using WithSource() option
+{{- if .Event.Subject }}
+// The subject defaults to {{ .FuncName }}Subject(payload) and can be overridden using WithSubject() option
+{{- end }}
+{{- if .Event.PartitionKey }}
+// The partition key defaults to {{ .FuncName }}PartitionKey(payload) and can be overridden using WithPartitionKey() option
+{{- end }}
+{{- if .Event.IDFields }}
+// The event id defaults to {{ .FuncName }}ID(payload) and can be overridden using WithID() option
+{{- end }}
+func Publish{{ .FuncName }}(ctx context.Context, bus runtime.Publisher,
+	payload *{{ .Name }}, opts ...runtime.PublishOption) error {
+	if payload == nil {
+		return errors.New("events: payload is required")
+	}
+{{- with .DefaultOptions }}
+{{- if eq (len .) 1 }}
+	opts = append([]runtime.PublishOption{ {{- index . 0 -}} }, opts...)
+{{- else }}
+	opts = append([]runtime.PublishOption{
+{{- range . }}
+		{{ . }},
+{{- end }}
+	}, opts...)
+{{- end }}
+{{- end }}
+	event, subject, err := runtime.BuildEvent(Event{{ .FuncName }}, payload, opts)
+	if err != nil {
+		return err
+	}
+	return bus.Publish(ctx, subject, event)
+}
+{{- end }}
+{{- if .HasReplies }}
+
+// ============================================================
+// Request/Reply Functions
+// ============================================================
+{{- range $m := .Messages }}
+{{- with .Reply }}
+
+// Request{{ $m.FuncName }} sends {{ $m.Event.Description }} request and waits for its {{ .Event.EventType }} reply until ctx is done
+// The event source must be specified using WithSource() option
+// Errors returned by the responder are reported as *runtime.ReplyError
+func Request{{ $m.FuncName }}(ctx context.Context, bus runtime.Requester,
+	payload *{{ $m.Name }}, opts ...runtime.PublishOption) (*{{ .Payload }}, error) {
+	if payload == nil {
+		return nil, errors.New("events: payload is required")
+	}
+{{- with $m.DefaultOptions }}
+{{- if eq (len .) 1 }}
+	opts = append([]runtime.PublishOption{ {{- index . 0 -}} }, opts...)
+{{- else }}
+	opts = append([]runtime.PublishOption{
+{{- range . }}
+		{{ . }},
+{{- end }}
+	}, opts...)
+{{- end }}
+{{- end }}
+	return runtime.Request(ctx, bus, Event{{ $m.FuncName }}, {{ .Descriptor }}, payload, opts)
+}
+
+// Handle{{ $m.FuncName }} answers {{ $m.Event.Description }} requests with the {{ .Event.EventType }} replies returned by handler
+// Responders in the same group compete for requests; with an empty group every responder receives them
+// opts apply to every reply; the reply source defaults to the request source
+// The responder stops when the returned subscription is unsubscribed or ctx is done
+func Handle{{ $m.FuncName }}(ctx context.Context, bus runtime.Responder, group string,
+	handler func(context.Context, *{{ $m.Name }}) (*{{ .Payload }}, error),
+	opts ...runtime.PublishOption) (runtime.Subscription, error) {
+{{- if $m.Event.Subject }}
+	return runtime.Respond(ctx, bus, Event{{ $m.FuncName }}, {{ .Descriptor }}, {{ $m.FuncName }}SubjectFilter{}.Subject(), group, handler, opts)
+{{- else }}
+	return runtime.Respond(ctx, bus, Event{{ $m.FuncName }}, {{ .Descriptor }}, EventType{{ $m.FuncName }}, group, handler, opts)
+{{- end }}
+}
+{{- end }}
+{{- end }}
+{{- end }}
+
+// ============================================================
+// Subscribe Functions (Broadcast Mode)
+// ============================================================
+
+{{- range .Messages }}
+
+// Subscribe{{ .FuncName }} subscribes to {{ .Event.Description }} events (broadcast mode)
+// All subscribers will receive the event
+// Events published to custom subjects are consumed with runtime.WithSubscribeSubject or runtime.WithSubjectPrefix
+// Consumption stops when the returned subscription is unsubscribed or ctx is done
+func Subscribe{{ .FuncName }}(ctx context.Context, bus runtime.Subscriber,
+	handler func(context.Context, *{{ .Name }}) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+{{- if .Event.Subject }}
+	return Subscribe{{ .FuncName }}Filtered(ctx, bus, {{ .FuncName }}SubjectFilter{}, handler, opts...)
+{{- else }}
+	return runtime.Subscribe(ctx, bus, Event{{ .FuncName }}, EventType{{ .FuncName }}, handler, opts...)
+{{- end }}
+}
+{{- if .Event.Subject }}
+
+// Subscribe{{ .FuncName }}Filtered subscribes to {{ .Event.Description }} events matching filter (broadcast mode)
+func Subscribe{{ .FuncName }}Filtered(ctx context.Context, bus runtime.Subscriber,
+	filter {{ .FuncName }}SubjectFilter, handler func(context.Context, *{{ .Name }}) error,
+	opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.Subscribe(ctx, bus, Event{{ .FuncName }}, filter.Subject(), handler, opts...)
+}
+{{- end }}
+
+// Subscribe{{ .FuncName }}Envelope subscribes to {{ .Event.Description }} events (broadcast mode),
+// passing handler the payload together with the event's CloudEvents attributes and extensions
+func Subscribe{{ .FuncName }}Envelope(ctx context.Context, bus runtime.Subscriber,
+	handler func(context.Context, *runtime.Envelope[{{ .Name }}]) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+{{- if .Event.Subject }}
+	return runtime.SubscribeEnvelope(ctx, bus, Event{{ .FuncName }}, {{ .FuncName }}SubjectFilter{}.Subject(), handler, opts...)
+{{- else }}
+	return runtime.SubscribeEnvelope(ctx, bus, Event{{ .FuncName }}, EventType{{ .FuncName }}, handler, opts...)
+{{- end }}
+}
+{{- end }}
+{{- if .EmitGroupSubscribers }}
+
+// ============================================================
+// Subscribe Functions (Handler Group Mode)
+// ============================================================
+
+{{- range .Messages }}
+
+// Subscribe{{ .FuncName }}WithGroup subscribes to {{ .Event.Description }} events (handler group mode)
+// Subscribers in the same group will compete for message consumption (load balancing)
+// Events published to custom subjects are consumed with runtime.WithSubscribeSubject or runtime.WithSubjectPrefix
+// Consumption stops when the returned subscription is unsubscribed or ctx is done
+func Subscribe{{ .FuncName }}WithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	group string, handler func(context.Context, *{{ .Name }}) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+{{- if .Event.Subject }}
+	return Subscribe{{ .FuncName }}FilteredWithGroup(ctx, bus, {{ .FuncName }}SubjectFilter{}, group, handler, opts...)
+{{- else }}
+	return runtime.SubscribeWithGroup(ctx, bus, Event{{ .FuncName }}, EventType{{ .FuncName }}, group, handler, opts...)
+{{- end }}
+}
+{{- if .Event.Subject }}
+
+// Subscribe{{ .FuncName }}FilteredWithGroup subscribes to {{ .Event.Description }} events matching filter (handler group mode)
+func Subscribe{{ .FuncName }}FilteredWithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	filter {{ .FuncName }}SubjectFilter, group string, handler func(context.Context, *{{ .Name }}) error,
+	opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeWithGroup(ctx, bus, Event{{ .FuncName }}, filter.Subject(), group, handler, opts...)
+}
+{{- end }}
+
+// Subscribe{{ .FuncName }}EnvelopeWithGroup subscribes to {{ .Event.Description }} events (handler group mode),
+// passing handler the payload together with the event's CloudEvents attributes and extensions
+func Subscribe{{ .FuncName }}EnvelopeWithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	group string, handler func(context.Context, *runtime.Envelope[{{ .Name }}]) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+{{- if .Event.Subject }}
+	return runtime.SubscribeEnvelopeWithGroup(ctx, bus, Event{{ .FuncName }}, {{ .FuncName }}SubjectFilter{}.Subject(), group, handler, opts...)
+{{- else }}
+	return runtime.SubscribeEnvelopeWithGroup(ctx, bus, Event{{ .FuncName }}, EventType{{ .FuncName }}, group, handler, opts...)
+{{- end }}
+}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .Handlers }}
+
+// ============================================================
+// Event Handlers
+// ============================================================
+{{- range .Handlers }}
+
+// {{ .GoName }}EventHandler handles the events consumed by the {{ .Service.Desc.FullName }} service
+type {{ .GoName }}EventHandler interface {
+{{- range .Methods }}
+	// {{ .GoName }} handles {{ .Event.Description }} events ({{ .Event.EventType }})
+{{- if .Envelope }}
+	{{ .GoName }}(ctx context.Context, envelope *runtime.Envelope[{{ .Payload }}]) error
+{{- else }}
+	{{ .GoName }}(ctx context.Context, payload *{{ .Payload }}) error
+{{- end }}
+{{- end }}
+}
+
+// Register{{ .GoName }}EventHandlers subscribes every method of handler to its events (handler group mode)
+// Subscribers in the same group will compete for message consumption (load balancing)
+// Consumption stops when the returned subscriptions are unsubscribed or ctx is done
+// opts apply to every subscription, e.g. runtime.WithHandlerMiddleware
+func Register{{ .GoName }}EventHandlers(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	handler {{ .GoName }}EventHandler, group string, opts ...runtime.SubscribeOption) (runtime.Subscriptions, error) {
+	if handler == nil {
+		return nil, errors.New("events: handler is required")
+	}
+	var (
+		subs runtime.Subscriptions
+		sub  runtime.Subscription
+		err  error
+	)
+{{- range .Methods }}
+{{- if .Envelope }}
+	sub, err = runtime.SubscribeEnvelopeWithGroup(ctx, bus, {{ .Descriptor }}, {{ .Subject }}, group, handler.{{ .GoName }}, opts...)
+{{- else }}
+	sub, err = runtime.SubscribeWithGroup(ctx, bus, {{ .Descriptor }}, {{ .Subject }}, group, handler.{{ .GoName }}, opts...)
+{{- end }}
+	if err != nil {
+		_ = subs.Unsubscribe()
+		return nil, err
+	}
+	subs = append(subs, sub)
+{{- end }}
+	return subs, nil
+}
+{{- end }}
+{{- end }}
+`))
@@ -0,0 +1,122 @@
+// Code generated by protoc-gen-cloudevents. DO NOT EDIT.
+
+package eventstest
+
+import (
+	"testing"
+
+	events "example.com/shop/events"
+	runtime "github.com/yafeiaa/protoc-gen-cloudevents-go/runtime"
+)
+
+// OrderCreatedPayload returns the payloads of the shop.order.created events recorded by r, in publish order
+func OrderCreatedPayload(t testing.TB, r *runtime.Recorder) []*events.OrderCreatedPayload {
+	t.Helper()
+	return runtime.RecordedPayloads(t, r, events.EventOrderCreatedPayload)
+}
+
+// OrderCreatedPayloadEnvelopes returns the shop.order.created events recorded by r with their CloudEvents
+// attributes, in publish order
+func OrderCreatedPayloadEnvelopes(t testing.TB, r *runtime.Recorder) []*runtime.Envelope[events.OrderCreatedPayload] {
+	t.Helper()
+	return runtime.RecordedEnvelopes(t, r, events.EventOrderCreatedPayload)
+}
+
+// AssertPublishedOrderCreatedPayload fails the test unless r recorded a shop.order.created event whose payload
+// satisfies all of match, and returns the first such payload
+func AssertPublishedOrderCreatedPayload(t testing.TB, r *runtime.Recorder,
+	match ...func(*events.OrderCreatedPayload) bool) *events.OrderCreatedPayload {
+	t.Helper()
+	return runtime.AssertPublished(t, r, events.EventOrderCreatedPayload, match...)
+}
+
+// AssertNotPublishedOrderCreatedPayload fails the test if r recorded a shop.order.created event whose payload
+// satisfies all of match
+func AssertNotPublishedOrderCreatedPayload(t testing.TB, r *runtime.Recorder, match ...func(*events.OrderCreatedPayload) bool) {
+	t.Helper()
+	runtime.AssertNotPublished(t, r, events.EventOrderCreatedPayload, match...)
+}
+
+// GetOrderStatusPayload returns the payloads of the shop.order.status_requested events recorded by r, in publish order
+func GetOrderStatusPayload(t testing.TB, r *runtime.Recorder) []*events.GetOrderStatusPayload {
+	t.Helper()
+	return runtime.RecordedPayloads(t, r, events.EventGetOrderStatusPayload)
+}
+
+// GetOrderStatusPayloadEnvelopes returns the shop.order.status_requested events recorded by r with their CloudEvents
+// attributes, in publish order
+func GetOrderStatusPayloadEnvelopes(t testing.TB, r *runtime.Recorder) []*runtime.Envelope[events.GetOrderStatusPayload] {
+	t.Helper()
+	return runtime.RecordedEnvelopes(t, r, events.EventGetOrderStatusPayload)
+}
+
+// AssertPublishedGetOrderStatusPayload fails the test unless r recorded a shop.order.status_requested event whose payload
+// satisfies all of match, and returns the first such payload
+func AssertPublishedGetOrderStatusPayload(t testing.TB, r *runtime.Recorder,
+	match ...func(*events.GetOrderStatusPayload) bool) *events.GetOrderStatusPayload {
+	t.Helper()
+	return runtime.AssertPublished(t, r, events.EventGetOrderStatusPayload, match...)
+}
+
+// AssertNotPublishedGetOrderStatusPayload fails the test if r recorded a shop.order.status_requested event whose payload
+// satisfies all of match
+func AssertNotPublishedGetOrderStatusPayload(t testing.TB, r *runtime.Recorder, match ...func(*events.GetOrderStatusPayload) bool) {
+	t.Helper()
+	runtime.AssertNotPublished(t, r, events.EventGetOrderStatusPayload, match...)
+}
+
+// OrderStatusPayload returns the payloads of the shop.order.status_reported events recorded by r, in publish order
+func OrderStatusPayload(t testing.TB, r *runtime.Recorder) []*events.OrderStatusPayload {
+	t.Helper()
+	return runtime.RecordedPayloads(t, r, events.EventOrderStatusPayload)
+}
+
+// OrderStatusPayloadEnvelopes returns the shop.order.status_reported events recorded by r with their CloudEvents
+// attributes, in publish order
+func OrderStatusPayloadEnvelopes(t testing.TB, r *runtime.Recorder) []*runtime.Envelope[events.OrderStatusPayload] {
+	t.Helper()
+	return runtime.RecordedEnvelopes(t, r, events.EventOrderStatusPayload)
+}
+
+// AssertPublishedOrderStatusPayload fails the test unless r recorded a shop.order.status_reported event whose payload
+// satisfies all of match, and returns the first such payload
+func AssertPublishedOrderStatusPayload(t testing.TB, r *runtime.Recorder,
+	match ...func(*events.OrderStatusPayload) bool) *events.OrderStatusPayload {
+	t.Helper()
+	return runtime.AssertPublished(t, r, events.EventOrderStatusPayload, match...)
+}
+
+// AssertNotPublishedOrderStatusPayload fails the test if r recorded a shop.order.status_reported event whose payload
+// satisfies all of match
+func AssertNotPublishedOrderStatusPayload(t testing.TB, r *runtime.Recorder, match ...func(*events.OrderStatusPayload) bool) {
+	t.Helper()
+	runtime.AssertNotPublished(t, r, events.EventOrderStatusPayload, match...)
+}
+
+// OrderShippedPayload returns the payloads of the shop.order.shipped events recorded by r, in publish order
+func OrderShippedPayload(t testing.TB, r *runtime.Recorder) []*events.Order_ShippedPayload {
+	t.Helper()
+	return runtime.RecordedPayloads(t, r, events.EventOrderShippedPayload)
+}
+
+// OrderShippedPayloadEnvelopes returns the shop.order.shipped events recorded by r with their CloudEvents
+// attributes, in publish order
+func OrderShippedPayloadEnvelopes(t testing.TB, r *runtime.Recorder) []*runtime.Envelope[events.Order_ShippedPayload] {
+	t.Helper()
+	return runtime.RecordedEnvelopes(t, r, events.EventOrderShippedPayload)
+}
+
+// AssertPublishedOrderShippedPayload fails the test unless r recorded a shop.order.shipped event whose payload
+// satisfies all of match, and returns the first such payload
+func AssertPublishedOrderShippedPayload(t testing.TB, r *runtime.Recorder,
+	match ...func(*events.Order_ShippedPayload) bool) *events.Order_ShippedPayload {
+	t.Helper()
+	return runtime.AssertPublished(t, r, events.EventOrderShippedPayload, match...)
+}
+
+// AssertNotPublishedOrderShippedPayload fails the test if r recorded a shop.order.shipped event whose payload
+// satisfies all of match
+func AssertNotPublishedOrderShippedPayload(t testing.TB, r *runtime.Recorder, match ...func(*events.Order_ShippedPayload) bool) {
+	t.Helper()
+	runtime.AssertNotPublished(t, r, events.EventOrderShippedPayload, match...)
+}
@@ -0,0 +1,489 @@
+// Code generated by protoc-gen-cloudevents. DO NOT EDIT.
+
+package events
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	runtime "github.com/yafeiaa/protoc-gen-cloudevents-go/runtime"
+)
+
+// ============================================================
+// Event Type Definitions
+// ============================================================
+
+const (
+	// EventTypeOrderCreated Order placed by a customer
+	EventTypeOrderCreated = "shop.order.created"
+	// EventTypeGetOrderStatus Order status request
+	EventTypeGetOrderStatus = "shop.order.status_requested"
+	// EventTypeOrderStatus
+	EventTypeOrderStatus = "shop.order.status_reported"
+	// EventTypeOrderShipped
+	EventTypeOrderShipped = "shop.order.shipped"
+)
+
+var (
+	// EventOrderCreated describes the shop.order.created event
+	EventOrderCreated = &runtime.Event[OrderCreatedPayload]{
+		Type:               EventTypeOrderCreated,
+		Codec:              runtime.ProtoJSON,
+		Description:        "Order placed by a customer",
+		Version:            2,
+		DataSchema:         "https://schemas.example.com/shop.order.created/v2.json",
+		RequiredExtensions: []string{ExtensionTenant},
+	}
+	// EventGetOrderStatus describes the shop.order.status_requested event
+	EventGetOrderStatus = &runtime.Event[GetOrderStatusPayload]{
+		Type:               EventTypeGetOrderStatus,
+		Codec:              runtime.ProtoJSON,
+		Description:        "Order status request",
+		RequiredExtensions: []string{ExtensionTenant},
+	}
+	// EventOrderStatus describes the shop.order.status_reported event
+	EventOrderStatus = &runtime.Event[OrderStatusPayload]{
+		Type:               EventTypeOrderStatus,
+		Codec:              runtime.Protobuf,
+		RequiredExtensions: []string{ExtensionTenant},
+	}
+	// EventOrderShipped describes the shop.order.shipped event
+	EventOrderShipped = &runtime.Event[Order_ShippedPayload]{
+		Type:               EventTypeOrderShipped,
+		Codec:              runtime.ProtoJSON,
+		RequiredExtensions: []string{ExtensionTenant},
+	}
+)
+
+func init() {
+	runtime.MustRegisterEvent(EventOrderCreated)
+	runtime.MustRegisterEvent(EventGetOrderStatus)
+	runtime.MustRegisterEvent(EventOrderStatus)
+	runtime.MustRegisterEvent(EventOrderShipped)
+}
+
+// RegisterEventsEvents registers the events declared in this file into r, e.g. a registry created with
+// runtime.NewRegistry. Events already registered into r are skipped; it fails if r holds another message
+// for one of their types
+func RegisterEventsEvents(r *runtime.Registry) error {
+	return errors.Join(
+		runtime.RegisterEvent(r, EventOrderCreated),
+		runtime.RegisterEvent(r, EventGetOrderStatus),
+		runtime.RegisterEvent(r, EventOrderStatus),
+		runtime.RegisterEvent(r, EventOrderShipped),
+	)
+}
+
+// ============================================================
+// Subject Functions
+// ============================================================
+
+// OrderCreatedSubject returns the subject Order placed by a customer events are published to,
+// derived from the payload using the template "shop.order.created.{currency}.{customer.id}"
+func OrderCreatedSubject(payload *OrderCreatedPayload) string {
+	return "shop.order.created." + runtime.SubjectToken(payload.GetCurrency()) + "." + runtime.SubjectToken(payload.GetCustomer().GetId())
+}
+
+// OrderCreatedSubjectFilter selects Order placed by a customer events by subject tokens
+// Fields left empty match any value
+type OrderCreatedSubjectFilter struct {
+	// Currency matches the {currency} token
+	Currency string
+	// CustomerId matches the {customer.id} token
+	CustomerId string
+}
+
+// Subject returns the subject pattern matching the filter
+func (f OrderCreatedSubjectFilter) Subject() string {
+	return "shop.order.created." + runtime.SubjectFilterToken(f.Currency) + "." + runtime.SubjectFilterToken(f.CustomerId)
+}
+
+// ============================================================
+// Partition Key Functions
+// ============================================================
+
+// OrderCreatedPartitionKey returns the partition key of Order placed by a customer events,
+// read from the payload field order_id
+func OrderCreatedPartitionKey(payload *OrderCreatedPayload) string {
+	return runtime.PartitionKeyValue(payload.GetOrderId())
+}
+
+// ============================================================
+// Event ID Functions
+// ============================================================
+
+// GetOrderStatusID returns the deterministic id of Order status request events,
+// derived from the event type and the payload fields order_id
+func GetOrderStatusID(payload *GetOrderStatusPayload) string {
+	return runtime.DeterministicID(EventTypeGetOrderStatus, payload.GetOrderId())
+}
+
+// ============================================================
+// Extensions
+// ============================================================
+
+const (
+	// ExtensionChannel is the name of the channel CloudEvents extension: Sales channel
+	ExtensionChannel = "channel"
+	// ExtensionPrio is the name of the priority CloudEvents extension
+	ExtensionPrio = "priority"
+	// ExtensionTenant is the name of the tenant CloudEvents extension: Tenant the event belongs to
+	ExtensionTenant = "tenant"
+)
+
+// WithChannel sets the channel extension of the published event
+func WithChannel(value string) runtime.PublishOption {
+	return runtime.WithExtension(ExtensionChannel, value)
+}
+
+// ChannelFromContext returns the channel extension of the event handled with ctx
+// It reports false if the event does not carry the extension or it is not a valid string
+func ChannelFromContext(ctx context.Context) (string, bool) {
+	return runtime.ExtensionString(ctx, ExtensionChannel)
+}
+
+// WithPrio sets the priority extension of the published event
+func WithPrio(value int32) runtime.PublishOption {
+	return runtime.WithExtension(ExtensionPrio, value)
+}
+
+// PrioFromContext returns the priority extension of the event handled with ctx
+// It reports false if the event does not carry the extension or it is not a valid int32
+func PrioFromContext(ctx context.Context) (int32, bool) {
+	return runtime.ExtensionInt(ctx, ExtensionPrio)
+}
+
+// WithTenant sets the tenant extension of the published event
+func WithTenant(value string) runtime.PublishOption {
+	return runtime.WithExtension(ExtensionTenant, value)
+}
+
+// TenantFromContext returns the tenant extension of the event handled with ctx
+// It reports false if the event does not carry the extension or it is not a valid string
+func TenantFromContext(ctx context.Context) (string, bool) {
+	return runtime.ExtensionString(ctx, ExtensionTenant)
+}
+
+// ============================================================
+// Payload Validation
+// ============================================================
+
+var (
+	pattern_Customer_Id = regexp.MustCompile("^c-[0-9]+$")
+)
+
+// Validate checks Customer against the field rules declared in proto
+// It returns a *runtime.ValidationError listing every violation
+func (x *Customer) Validate() error {
+	if x == nil {
+		return nil
+	}
+	var violations runtime.Violations
+	if x.GetId() == "" {
+		violations.Add("id", "required", "value is required")
+	}
+	if !pattern_Customer_Id.MatchString(x.GetId()) {
+		violations.Add("id", "pattern", "value must match pattern \"^c-[0-9]+$\"")
+	}
+	return violations.Err()
+}
+
+// Validate checks LineItem against the field rules declared in proto
+// It returns a *runtime.ValidationError listing every violation
+func (x *LineItem) Validate() error {
+	if x == nil {
+		return nil
+	}
+	var violations runtime.Violations
+	if x.GetSku() == "" {
+		violations.Add("sku", "required", "value is required")
+	}
+	if x.GetQuantity() < 1 {
+		violations.Add("quantity", "min", "value must be at least 1")
+	}
+	if x.GetQuantity() > 100 {
+		violations.Add("quantity", "max", "value must be at most 100")
+	}
+	return violations.Err()
+}
+
+// Validate checks OrderCreatedPayload against the field rules declared in proto
+// It returns a *runtime.ValidationError listing every violation
+func (x *OrderCreatedPayload) Validate() error {
+	if x == nil {
+		return nil
+	}
+	var violations runtime.Violations
+	if x.GetOrderId() == "" {
+		violations.Add("order_id", "required", "value is required")
+	}
+	violations.Nested("customer", x.GetCustomer())
+	if !runtime.EnumDefined(x.GetCurrency()) {
+		violations.Add("currency", "defined_only", "value must be a defined enum value")
+	}
+	if len(x.GetItems()) < 1 {
+		violations.Add("items", "min_items", "must have at least 1 items")
+	}
+	if len(x.GetItems()) > 50 {
+		violations.Add("items", "max_items", "must have at most 50 items")
+	}
+	for i, item := range x.GetItems() {
+		violations.Nested(runtime.Index("items", i), item)
+	}
+	return violations.Err()
+}
+
+// ============================================================
+// Upcaster Registration
+// ============================================================
+
+// RegisterOrderCreatedUpcaster registers fn to convert Order placed by a customer events published with
+// payload version from (older than 2) into the current payload type
+func RegisterOrderCreatedUpcaster(from uint32, fn runtime.Upcaster[OrderCreatedPayload]) {
+	EventOrderCreated.RegisterUpcaster(from, fn)
+}
+
+// ============================================================
+// Publish Functions
+// ============================================================
+
+// PublishOrderCreated publishes Order placed by a customer event
+// The event source must be specified using WithSource() option
+// The subject defaults to OrderCreatedSubject(payload) and can be overridden using WithSubject() option
+// The partition key defaults to OrderCreatedPartitionKey(payload) and can be overridden using WithPartitionKey() option
+func PublishOrderCreated(ctx context.Context, bus runtime.Publisher,
+	payload *OrderCreatedPayload, opts ...runtime.PublishOption) error {
+	if payload == nil {
+		return errors.New("events: payload is required")
+	}
+	opts = append([]runtime.PublishOption{
+		runtime.WithSubject(OrderCreatedSubject(payload)),
+		runtime.WithPartitionKey(OrderCreatedPartitionKey(payload)),
+	}, opts...)
+	event, subject, err := runtime.BuildEvent(EventOrderCreated, payload, opts)
+	if err != nil {
+		return err
+	}
+	return bus.Publish(ctx, subject, event)
+}
+
+// PublishGetOrderStatus publishes Order status request event
+// The event source must be specified using WithSource() option
+// The event id defaults to GetOrderStatusID(payload) and can be overridden using WithID() option
+func PublishGetOrderStatus(ctx context.Context, bus runtime.Publisher,
+	payload *GetOrderStatusPayload, opts ...runtime.PublishOption) error {
+	if payload == nil {
+		return errors.New("events: payload is required")
+	}
+	opts = append([]runtime.PublishOption{runtime.WithID(GetOrderStatusID(payload))}, opts...)
+	event, subject, err := runtime.BuildEvent(EventGetOrderStatus, payload, opts)
+	if err != nil {
+		return err
+	}
+	return bus.Publish(ctx, subject, event)
+}
+
+// PublishOrderStatus publishes  event
+// The event source must be specified using WithSource() option
+func PublishOrderStatus(ctx context.Context, bus runtime.Publisher,
+	payload *OrderStatusPayload, opts ...runtime.PublishOption) error {
+	if payload == nil {
+		return errors.New("events: payload is required")
+	}
+	event, subject, err := runtime.BuildEvent(EventOrderStatus, payload, opts)
+	if err != nil {
+		return err
+	}
+	return bus.Publish(ctx, subject, event)
+}
+
+// PublishOrderShipped publishes  event
+// The event source must be specified using WithSource() option
+func PublishOrderShipped(ctx context.Context, bus runtime.Publisher,
+	payload *Order_ShippedPayload, opts ...runtime.PublishOption) error {
+	if payload == nil {
+		return errors.New("events: payload is required")
+	}
+	event, subject, err := runtime.BuildEvent(EventOrderShipped, payload, opts)
+	if err != nil {
+		return err
+	}
+	return bus.Publish(ctx, subject, event)
+}
+
+// ============================================================
+// Request/Reply Functions
+// ============================================================
+
+// RequestGetOrderStatus sends Order status request request and waits for its shop.order.status_reported reply until ctx is done
+// The event source must be specified using WithSource() option
+// Errors returned by the responder are reported as *runtime.ReplyError
+func RequestGetOrderStatus(ctx context.Context, bus runtime.Requester,
+	payload *GetOrderStatusPayload, opts ...runtime.PublishOption) (*OrderStatusPayload, error) {
+	if payload == nil {
+		return nil, errors.New("events: payload is required")
+	}
+	opts = append([]runtime.PublishOption{runtime.WithID(GetOrderStatusID(payload))}, opts...)
+	return runtime.Request(ctx, bus, EventGetOrderStatus, EventOrderStatus, payload, opts)
+}
+
+// HandleGetOrderStatus answers Order status request requests with the shop.order.status_reported replies returned by handler
+// Responders in the same group compete for requests; with an empty group every responder receives them
+// opts apply to every reply; the reply source defaults to the request source
+// The responder stops when the returned subscription is unsubscribed or ctx is done
+func HandleGetOrderStatus(ctx context.Context, bus runtime.Responder, group string,
+	handler func(context.Context, *GetOrderStatusPayload) (*OrderStatusPayload, error),
+	opts ...runtime.PublishOption) (runtime.Subscription, error) {
+	return runtime.Respond(ctx, bus, EventGetOrderStatus, EventOrderStatus, EventTypeGetOrderStatus, group, handler, opts)
+}
+
+// ============================================================
+// Subscribe Functions (Broadcast Mode)
+// ============================================================
+
+// SubscribeOrderCreated subscribes to Order placed by a customer events (broadcast mode)
+// All subscribers will receive the event
+// Events published to custom subjects are consumed with runtime.WithSubscribeSubject or runtime.WithSubjectPrefix
+// Consumption stops when the returned subscription is unsubscribed or ctx is done
+func SubscribeOrderCreated(ctx context.Context, bus runtime.Subscriber,
+	handler func(context.Context, *OrderCreatedPayload) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return SubscribeOrderCreatedFiltered(ctx, bus, OrderCreatedSubjectFilter{}, handler, opts...)
+}
+
+// SubscribeOrderCreatedFiltered subscribes to Order placed by a customer events matching filter (broadcast mode)
+func SubscribeOrderCreatedFiltered(ctx context.Context, bus runtime.Subscriber,
+	filter OrderCreatedSubjectFilter, handler func(context.Context, *OrderCreatedPayload) error,
+	opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.Subscribe(ctx, bus, EventOrderCreated, filter.Subject(), handler, opts...)
+}
+
+// SubscribeOrderCreatedEnvelope subscribes to Order placed by a customer events (broadcast mode),
+// passing handler the payload together with the event's CloudEvents attributes and extensions
+func SubscribeOrderCreatedEnvelope(ctx context.Context, bus runtime.Subscriber,
+	handler func(context.Context, *runtime.Envelope[OrderCreatedPayload]) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeEnvelope(ctx, bus, EventOrderCreated, OrderCreatedSubjectFilter{}.Subject(), handler, opts...)
+}
+
+// SubscribeGetOrderStatus subscribes to Order status request events (broadcast mode)
+// All subscribers will receive the event
+// Events published to custom subjects are consumed with runtime.WithSubscribeSubject or runtime.WithSubjectPrefix
+// Consumption stops when the returned subscription is unsubscribed or ctx is done
+func SubscribeGetOrderStatus(ctx context.Context, bus runtime.Subscriber,
+	handler func(context.Context, *GetOrderStatusPayload) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.Subscribe(ctx, bus, EventGetOrderStatus, EventTypeGetOrderStatus, handler, opts...)
+}
+
+// SubscribeGetOrderStatusEnvelope subscribes to Order status request events (broadcast mode),
+// passing handler the payload together with the event's CloudEvents attributes and extensions
+func SubscribeGetOrderStatusEnvelope(ctx context.Context, bus runtime.Subscriber,
+	handler func(context.Context, *runtime.Envelope[GetOrderStatusPayload]) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeEnvelope(ctx, bus, EventGetOrderStatus, EventTypeGetOrderStatus, handler, opts...)
+}
+
+// SubscribeOrderStatus subscribes to  events (broadcast mode)
+// All subscribers will receive the event
+// Events published to custom subjects are consumed with runtime.WithSubscribeSubject or runtime.WithSubjectPrefix
+// Consumption stops when the returned subscription is unsubscribed or ctx is done
+func SubscribeOrderStatus(ctx context.Context, bus runtime.Subscriber,
+	handler func(context.Context, *OrderStatusPayload) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.Subscribe(ctx, bus, EventOrderStatus, EventTypeOrderStatus, handler, opts...)
+}
+
+// SubscribeOrderStatusEnvelope subscribes to  events (broadcast mode),
+// passing handler the payload together with the event's CloudEvents attributes and extensions
+func SubscribeOrderStatusEnvelope(ctx context.Context, bus runtime.Subscriber,
+	handler func(context.Context, *runtime.Envelope[OrderStatusPayload]) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeEnvelope(ctx, bus, EventOrderStatus, EventTypeOrderStatus, handler, opts...)
+}
+
+// SubscribeOrderShipped subscribes to  events (broadcast mode)
+// All subscribers will receive the event
+// Events published to custom subjects are consumed with runtime.WithSubscribeSubject or runtime.WithSubjectPrefix
+// Consumption stops when the returned subscription is unsubscribed or ctx is done
+func SubscribeOrderShipped(ctx context.Context, bus runtime.Subscriber,
+	handler func(context.Context, *Order_ShippedPayload) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.Subscribe(ctx, bus, EventOrderShipped, EventTypeOrderShipped, handler, opts...)
+}
+
+// SubscribeOrderShippedEnvelope subscribes to  events (broadcast mode),
+// passing handler the payload together with the event's CloudEvents attributes and extensions
+func SubscribeOrderShippedEnvelope(ctx context.Context, bus runtime.Subscriber,
+	handler func(context.Context, *runtime.Envelope[Order_ShippedPayload]) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeEnvelope(ctx, bus, EventOrderShipped, EventTypeOrderShipped, handler, opts...)
+}
+
+// ============================================================
+// Subscribe Functions (Handler Group Mode)
+// ============================================================
+
+// SubscribeOrderCreatedWithGroup subscribes to Order placed by a customer events (handler group mode)
+// Subscribers in the same group will compete for message consumption (load balancing)
+// Events published to custom subjects are consumed with runtime.WithSubscribeSubject or runtime.WithSubjectPrefix
+// Consumption stops when the returned subscription is unsubscribed or ctx is done
+func SubscribeOrderCreatedWithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	group string, handler func(context.Context, *OrderCreatedPayload) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return SubscribeOrderCreatedFilteredWithGroup(ctx, bus, OrderCreatedSubjectFilter{}, group, handler, opts...)
+}
+
+// SubscribeOrderCreatedFilteredWithGroup subscribes to Order placed by a customer events matching filter (handler group mode)
+func SubscribeOrderCreatedFilteredWithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	filter OrderCreatedSubjectFilter, group string, handler func(context.Context, *OrderCreatedPayload) error,
+	opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeWithGroup(ctx, bus, EventOrderCreated, filter.Subject(), group, handler, opts...)
+}
+
+// SubscribeOrderCreatedEnvelopeWithGroup subscribes to Order placed by a customer events (handler group mode),
+// passing handler the payload together with the event's CloudEvents attributes and extensions
+func SubscribeOrderCreatedEnvelopeWithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	group string, handler func(context.Context, *runtime.Envelope[OrderCreatedPayload]) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeEnvelopeWithGroup(ctx, bus, EventOrderCreated, OrderCreatedSubjectFilter{}.Subject(), group, handler, opts...)
+}
+
+// SubscribeGetOrderStatusWithGroup subscribes to Order status request events (handler group mode)
+// Subscribers in the same group will compete for message consumption (load balancing)
+// Events published to custom subjects are consumed with runtime.WithSubscribeSubject or runtime.WithSubjectPrefix
+// Consumption stops when the returned subscription is unsubscribed or ctx is done
+func SubscribeGetOrderStatusWithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	group string, handler func(context.Context, *GetOrderStatusPayload) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeWithGroup(ctx, bus, EventGetOrderStatus, EventTypeGetOrderStatus, group, handler, opts...)
+}
+
+// SubscribeGetOrderStatusEnvelopeWithGroup subscribes to Order status request events (handler group mode),
+// passing handler the payload together with the event's CloudEvents attributes and extensions
+func SubscribeGetOrderStatusEnvelopeWithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	group string, handler func(context.Context, *runtime.Envelope[GetOrderStatusPayload]) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeEnvelopeWithGroup(ctx, bus, EventGetOrderStatus, EventTypeGetOrderStatus, group, handler, opts...)
+}
+
+// SubscribeOrderStatusWithGroup subscribes to  events (handler group mode)
+// Subscribers in the same group will compete for message consumption (load balancing)
+// Events published to custom subjects are consumed with runtime.WithSubscribeSubject or runtime.WithSubjectPrefix
+// Consumption stops when the returned subscription is unsubscribed or ctx is done
+func SubscribeOrderStatusWithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	group string, handler func(context.Context, *OrderStatusPayload) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeWithGroup(ctx, bus, EventOrderStatus, EventTypeOrderStatus, group, handler, opts...)
+}
+
+// SubscribeOrderStatusEnvelopeWithGroup subscribes to  events (handler group mode),
+// passing handler the payload together with the event's CloudEvents attributes and extensions
+func SubscribeOrderStatusEnvelopeWithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	group string, handler func(context.Context, *runtime.Envelope[OrderStatusPayload]) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeEnvelopeWithGroup(ctx, bus, EventOrderStatus, EventTypeOrderStatus, group, handler, opts...)
+}
+
+// SubscribeOrderShippedWithGroup subscribes to  events (handler group mode)
+// Subscribers in the same group will compete for message consumption (load balancing)
+// Events published to custom subjects are consumed with runtime.WithSubscribeSubject or runtime.WithSubjectPrefix
+// Consumption stops when the returned subscription is unsubscribed or ctx is done
+func SubscribeOrderShippedWithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	group string, handler func(context.Context, *Order_ShippedPayload) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeWithGroup(ctx, bus, EventOrderShipped, EventTypeOrderShipped, group, handler, opts...)
+}
+
+// SubscribeOrderShippedEnvelopeWithGroup subscribes to  events (handler group mode),
+// passing handler the payload together with the event's CloudEvents attributes and extensions
+func SubscribeOrderShippedEnvelopeWithGroup(ctx context.Context, bus runtime.HandlerGroupSubscriber,
+	group string, handler func(context.Context, *runtime.Envelope[Order_ShippedPayload]) error, opts ...runtime.SubscribeOption) (runtime.Subscription, error) {
+	return runtime.SubscribeEnvelopeWithGroup(ctx, bus, EventOrderShipped, EventTypeOrderShipped, group, handler, opts...)
+}
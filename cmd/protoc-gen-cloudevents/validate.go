@@ -0,0 +1,359 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	cloudevents "github.com/yafeiaa/protoc-gen-cloudevents-go/proto/cloudevents"
+)
+
+// validatedMessage is a message generated with a Validate method
+type validatedMessage struct {
+	GoName string
+	// Checks are the Go statements recording the violations of the fields into violations
+	Checks []string
+}
+
+// patternVar is the package level variable holding a compiled pattern rule
+type patternVar struct {
+	Name    string
+	Pattern string
+}
+
+// validation is the validation code generated for a file
+type validation struct {
+	Messages []*validatedMessage
+	Patterns []*patternVar
+}
+
+// validationIndex reports which messages get a Validate method: those declaring field rules
+// and those with message fields, possibly repeated or map values, of such messages
+type validationIndex map[protoreflect.FullName]bool
+
+// needsValidate reports whether msg gets a Validate method
+func (idx validationIndex) needsValidate(msg protoreflect.MessageDescriptor) bool {
+	if v, ok := idx[msg.FullName()]; ok {
+		return v
+	}
+	// Recursive messages are assumed not to need validation until proven otherwise
+	idx[msg.FullName()] = false
+
+	needs := false
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if fieldRules(field) != nil {
+			needs = true
+		}
+		if field.IsMap() {
+			field = field.MapValue()
+		}
+		if field.Message() != nil && idx.needsValidate(field.Message()) {
+			needs = true
+		}
+	}
+	idx[msg.FullName()] = needs
+	return needs
+}
+
+// fieldRules returns the rules option of field, or nil if the option is absent
+func fieldRules(field protoreflect.FieldDescriptor) *cloudevents.FieldRules {
+	opts, ok := field.Options().(*descriptorpb.FieldOptions)
+	if !ok || opts == nil || !proto.HasExtension(opts, cloudevents.E_Rules) {
+		return nil
+	}
+	rules, _ := proto.GetExtension(opts, cloudevents.E_Rules).(*cloudevents.FieldRules)
+	return rules
+}
+
+// collectValidation generates the Validate methods of the messages declared in file
+func collectValidation(file *protogen.File, idx validationIndex) (*validation, error) {
+	v := &validation{}
+	var walk func(msgs []*protogen.Message) error
+	walk = func(msgs []*protogen.Message) error {
+		for _, msg := range msgs {
+			if msg.Desc.IsMapEntry() {
+				continue
+			}
+			if idx.needsValidate(msg.Desc) {
+				m, err := v.message(msg, idx)
+				if err != nil {
+					return err
+				}
+				v.Messages = append(v.Messages, m)
+			}
+			if err := walk(msg.Messages); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(file.Messages); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// message generates the checks of the Validate method of msg
+func (v *validation) message(msg *protogen.Message, idx validationIndex) (*validatedMessage, error) {
+	m := &validatedMessage{GoName: msg.GoIdent.GoName}
+	for _, field := range msg.Fields {
+		if field.GoName == "Validate" {
+			return nil, errorf(field.Desc, "field conflicts with the generated Validate method")
+		}
+
+		rules := fieldRules(field.Desc)
+		if rules == nil {
+			rules = &cloudevents.FieldRules{}
+		}
+		if err := checkRules(field, rules); err != nil {
+			return nil, err
+		}
+
+		var (
+			getter = "x.Get" + field.GoName + "()"
+			path   = strconv.Quote(string(field.Desc.Name()))
+			checks []string
+		)
+		if rules.GetRequired() {
+			checks = append(checks, fmt.Sprintf("if %s {\nviolations.Add(%s, \"required\", \"value is required\")\n}",
+				unsetCond(field, getter), path))
+		}
+		if min := rules.GetMinItems(); min > 0 {
+			checks = append(checks, fmt.Sprintf("if len(%s) < %d {\nviolations.Add(%s, \"min_items\", %q)\n}",
+				getter, min, path, fmt.Sprintf("must have at least %d items", min)))
+		}
+		if max := rules.GetMaxItems(); max > 0 {
+			checks = append(checks, fmt.Sprintf("if len(%s) > %d {\nviolations.Add(%s, \"max_items\", %q)\n}",
+				getter, max, path, fmt.Sprintf("must have at most %d items", max)))
+		}
+
+		switch {
+		case field.Desc.IsMap():
+			if value := field.Message.Fields[1]; value.Message != nil && idx.needsValidate(value.Message.Desc) {
+				checks = append(checks, fmt.Sprintf("for k, item := range %s {\nviolations.Nested(runtime.Key(%s, k), item)\n}",
+					getter, path))
+			}
+		case field.Desc.IsList():
+			if elem := v.element(msg, field, rules, idx, "item", fmt.Sprintf("runtime.Index(%s, i)", path)); elem != nil {
+				checks = append(checks, fmt.Sprintf("for i, item := range %s {\n%s\n}", getter, strings.Join(elem, "\n")))
+			}
+		default:
+			elem := v.element(msg, field, rules, idx, getter, path)
+			if cond := setCond(field); cond != "" && elem != nil {
+				elem = []string{fmt.Sprintf("if %s {\n%s\n}", cond, strings.Join(elem, "\n"))}
+			}
+			checks = append(checks, elem...)
+		}
+		m.Checks = append(m.Checks, checks...)
+	}
+	return m, nil
+}
+
+// element generates the checks of a single value of field, read with the expression value
+// and reported with the field path expression path
+func (v *validation) element(msg *protogen.Message, field *protogen.Field, rules *cloudevents.FieldRules,
+	idx validationIndex, value, path string) []string {
+	var checks []string
+	if pattern := rules.GetPattern(); pattern != "" {
+		name := v.patternName(msg, field)
+		v.Patterns = append(v.Patterns, &patternVar{Name: name, Pattern: strconv.Quote(pattern)})
+		checks = append(checks, fmt.Sprintf("if !%s.MatchString(%s) {\nviolations.Add(%s, \"pattern\", %q)\n}",
+			name, value, path, fmt.Sprintf("value must match pattern %q", pattern)))
+	}
+	if rules.Min != nil {
+		checks = append(checks, fmt.Sprintf("if %s < %s {\nviolations.Add(%s, \"min\", %q)\n}",
+			boundValue(field, value), boundLiteral(field, rules.GetMin(), math.Ceil), path,
+			"value must be at least "+formatFloat(rules.GetMin())))
+	}
+	if rules.Max != nil {
+		checks = append(checks, fmt.Sprintf("if %s > %s {\nviolations.Add(%s, \"max\", %q)\n}",
+			boundValue(field, value), boundLiteral(field, rules.GetMax(), math.Floor), path,
+			"value must be at most "+formatFloat(rules.GetMax())))
+	}
+	if rules.GetDefinedOnly() {
+		checks = append(checks, fmt.Sprintf("if !runtime.EnumDefined(%s) {\nviolations.Add(%s, \"defined_only\", \"value must be a defined enum value\")\n}",
+			value, path))
+	}
+	if field.Message != nil && idx.needsValidate(field.Message.Desc) {
+		checks = append(checks, fmt.Sprintf("violations.Nested(%s, %s)", path, value))
+	}
+	return checks
+}
+
+// checkRules validates that rules apply to the type of field
+func checkRules(field *protogen.Field, rules *cloudevents.FieldRules) error {
+	kind := field.Desc.Kind()
+	if field.Desc.IsMap() && (rules.GetPattern() != "" || rules.Min != nil || rules.Max != nil || rules.GetDefinedOnly()) {
+		return errorf(field.Desc, "map fields only support the required, min_items and max_items rules")
+	}
+	if pattern := rules.GetPattern(); pattern != "" {
+		if kind != protoreflect.StringKind {
+			return errorf(field.Desc, "pattern rule requires a string field")
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return errorf(field.Desc, "invalid pattern %q: %v", pattern, err)
+		}
+	}
+	if rules.Min != nil || rules.Max != nil {
+		if !isNumeric(kind) {
+			return errorf(field.Desc, "min and max rules require a numeric field")
+		}
+		for _, bound := range []*float64{rules.Min, rules.Max} {
+			if bound != nil && (math.IsNaN(*bound) || math.IsInf(*bound, 0)) {
+				return errorf(field.Desc, "min and max rules must be finite numbers")
+			}
+		}
+		if _, _, ok := integerRange(kind); ok {
+			if rules.Min != nil && !inIntegerRange(kind, math.Ceil(rules.GetMin())) {
+				return errorf(field.Desc, "min %v is out of range for a %s field", rules.GetMin(), kind)
+			}
+			if rules.Max != nil && !inIntegerRange(kind, math.Floor(rules.GetMax())) {
+				return errorf(field.Desc, "max %v is out of range for a %s field", rules.GetMax(), kind)
+			}
+		}
+		if rules.Min != nil && rules.Max != nil && rules.GetMin() > rules.GetMax() {
+			return errorf(field.Desc, "min %v is greater than max %v", rules.GetMin(), rules.GetMax())
+		}
+	}
+	if rules.GetMinItems() > 0 || rules.GetMaxItems() > 0 {
+		if !field.Desc.IsList() && !field.Desc.IsMap() {
+			return errorf(field.Desc, "min_items and max_items rules require a repeated or map field")
+		}
+		if rules.GetMaxItems() > 0 && rules.GetMinItems() > rules.GetMaxItems() {
+			return errorf(field.Desc, "min_items %d is greater than max_items %d", rules.GetMinItems(), rules.GetMaxItems())
+		}
+	}
+	if rules.GetDefinedOnly() && kind != protoreflect.EnumKind {
+		return errorf(field.Desc, "defined_only rule requires an enum field")
+	}
+	return nil
+}
+
+// unsetCond returns the Go condition true when the required field is not set
+func unsetCond(field *protogen.Field, getter string) string {
+	switch {
+	case field.Oneof != nil && !field.Oneof.Desc.IsSynthetic():
+		return fmt.Sprintf("_, ok := x.Get%s().(*%s); !ok", field.Oneof.GoName, field.GoIdent.GoName)
+	case field.Desc.IsList() || field.Desc.IsMap():
+		return "len(" + getter + ") == 0"
+	case field.Message != nil:
+		return getter + " == nil"
+	case field.Desc.HasPresence():
+		return "x." + field.GoName + " == nil"
+	}
+
+	switch field.Desc.Kind() {
+	case protoreflect.StringKind:
+		return getter + ` == ""`
+	case protoreflect.BytesKind:
+		return "len(" + getter + ") == 0"
+	case protoreflect.BoolKind:
+		return "!" + getter
+	}
+	return getter + " == 0"
+}
+
+// setCond returns the Go condition true when a scalar field with explicit presence is set,
+// empty for fields whose rules apply to their zero value
+func setCond(field *protogen.Field) string {
+	switch {
+	case field.Message != nil:
+		return ""
+	case field.Oneof != nil && !field.Oneof.Desc.IsSynthetic():
+		return fmt.Sprintf("_, ok := x.Get%s().(*%s); ok", field.Oneof.GoName, field.GoIdent.GoName)
+	case field.Desc.HasPresence():
+		return "x." + field.GoName + " != nil"
+	}
+	return ""
+}
+
+func isNumeric(kind protoreflect.Kind) bool {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind,
+		protoreflect.FloatKind, protoreflect.DoubleKind:
+		return true
+	}
+	return false
+}
+
+// integerRange returns the range [lo, hi) of the values of an integer field kind, false for other kinds.
+// The bounds are powers of two, so that they are exact float64 values
+func integerRange(kind protoreflect.Kind) (lo, hi float64, ok bool) {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return math.MinInt32, 1 << 31, true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return 0, 1 << 32, true
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return math.MinInt64, 1 << 63, true
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return 0, 1 << 64, true
+	}
+	return 0, 0, false
+}
+
+// inIntegerRange reports whether the integral value f fits the integer field kind
+func inIntegerRange(kind protoreflect.Kind, f float64) bool {
+	lo, hi, _ := integerRange(kind)
+	return lo <= f && f < hi
+}
+
+// boundValue returns the expression compared to min and max rules: integer values are compared as integers,
+// so that 64-bit values are not rounded to float64
+func boundValue(field *protogen.Field, value string) string {
+	if _, _, ok := integerRange(field.Desc.Kind()); ok {
+		return value
+	}
+	return "float64(" + value + ")"
+}
+
+// boundLiteral returns the Go literal of the min or max bound of field. Integer fields get the integer bound
+// rounded with round, which checkRules ensures fits the field type
+func boundLiteral(field *protogen.Field, bound float64, round func(float64) float64) string {
+	kind := field.Desc.Kind()
+	if _, _, ok := integerRange(kind); !ok {
+		return formatFloat(bound)
+	}
+	bound = round(bound)
+	if kind == protoreflect.Uint64Kind || kind == protoreflect.Fixed64Kind {
+		return strconv.FormatUint(uint64(bound), 10)
+	}
+	return strconv.FormatInt(int64(bound), 10)
+}
+
+// patternName returns the name of the variable holding the pattern of field, separating the message and field
+// names so that e.g. Order.ItemSku and OrderItem.Sku do not collide
+func (v *validation) patternName(msg *protogen.Message, field *protogen.Field) string {
+	base := "pattern_" + msg.GoIdent.GoName + "_" + field.GoName
+	name := base
+	for i := 2; v.hasPattern(name); i++ {
+		name = base + "_" + strconv.Itoa(i)
+	}
+	return name
+}
+
+func (v *validation) hasPattern(name string) bool {
+	for _, p := range v.Patterns {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFloat formats f as the shortest Go float literal
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	cloudevents "github.com/yafeiaa/protoc-gen-cloudevents-go/proto/cloudevents"
+)
+
+// TestCheckRules 测试字段规则与字段类型的匹配校验
+func TestCheckRules(t *testing.T) {
+	gen, _ := newPlugin(t, "", loadFixture(t))
+	msg := findMessage(t, gen, "shop.events.OrderCreatedPayload")
+
+	tests := []struct {
+		field protoreflect.Name
+		rules *cloudevents.FieldRules
+		err   string
+	}{
+		{field: "order_id", rules: &cloudevents.FieldRules{Required: true, Pattern: "^o-[0-9]+$"}},
+		{field: "customer", rules: &cloudevents.FieldRules{Required: true}},
+		{field: "coupon", rules: &cloudevents.FieldRules{Pattern: "^[A-Z]+$"}},
+		{field: "card_token", rules: &cloudevents.FieldRules{Required: true, Pattern: "^tok_"}},
+		{field: "total", rules: &cloudevents.FieldRules{Min: proto.Float64(0), Max: proto.Float64(1e6)}},
+		{field: "sequence", rules: &cloudevents.FieldRules{Min: proto.Float64(1)}},
+		{field: "items", rules: &cloudevents.FieldRules{MinItems: 1, MaxItems: 1}},
+		{field: "items", rules: &cloudevents.FieldRules{MinItems: 3}},
+		{field: "labels", rules: &cloudevents.FieldRules{Required: true, MaxItems: 10}},
+		{field: "currency", rules: &cloudevents.FieldRules{DefinedOnly: true}},
+
+		{field: "order_id", rules: &cloudevents.FieldRules{Pattern: "("}, err: `invalid pattern "("`},
+		{field: "total", rules: &cloudevents.FieldRules{Pattern: "^1"}, err: "pattern rule requires a string field"},
+		{field: "signature", rules: &cloudevents.FieldRules{Pattern: "^1"}, err: "pattern rule requires a string field"},
+		{field: "order_id", rules: &cloudevents.FieldRules{Min: proto.Float64(1)}, err: "min and max rules require a numeric field"},
+		{field: "currency", rules: &cloudevents.FieldRules{Max: proto.Float64(1)}, err: "min and max rules require a numeric field"},
+		{field: "total", rules: &cloudevents.FieldRules{Min: proto.Float64(math.NaN())}, err: "must be finite numbers"},
+		{field: "total", rules: &cloudevents.FieldRules{Max: proto.Float64(math.Inf(1))}, err: "must be finite numbers"},
+		{field: "total", rules: &cloudevents.FieldRules{Min: proto.Float64(5), Max: proto.Float64(1)}, err: "min 5 is greater than max 1"},
+		{field: "order_id", rules: &cloudevents.FieldRules{MinItems: 1}, err: "min_items and max_items rules require a repeated or map field"},
+		{field: "items", rules: &cloudevents.FieldRules{MinItems: 3, MaxItems: 2}, err: "min_items 3 is greater than max_items 2"},
+		{field: "labels", rules: &cloudevents.FieldRules{Pattern: "^a"}, err: "map fields only support the required, min_items and max_items rules"},
+		{field: "labels", rules: &cloudevents.FieldRules{Min: proto.Float64(1)}, err: "map fields only support"},
+		{field: "order_id", rules: &cloudevents.FieldRules{DefinedOnly: true}, err: "defined_only rule requires an enum field"},
+		{field: "sequence", rules: &cloudevents.FieldRules{Min: proto.Float64(-0.5), Max: proto.Float64(1.8e19)}},
+		{field: "sequence", rules: &cloudevents.FieldRules{Min: proto.Float64(-1)}, err: "min -1 is out of range for a uint64 field"},
+		{field: "sequence", rules: &cloudevents.FieldRules{Max: proto.Float64(1 << 64)}, err: "max 1.8446744073709552e+19 is out of range for a uint64 field"},
+	}
+	for _, tt := range tests {
+		field := findField(t, msg, tt.field)
+		err := checkRules(field, tt.rules)
+		if tt.err == "" {
+			assert.NoError(t, err, "%s %v", tt.field, tt.rules)
+			continue
+		}
+		require.Error(t, err, "%s %v", tt.field, tt.rules)
+		assert.Contains(t, err.Error(), tt.err)
+		assert.Contains(t, err.Error(), "events.proto:")
+		assert.Contains(t, err.Error(), string(field.Desc.FullName()))
+	}
+}
+
+// validatedFile 中 Order.item_sku、Order.item__sku 与 Order.Item.sku 的 pattern 变量名去掉分隔符后会冲突，
+// 并声明 64 位整数的取值范围
+const validatedFile = `
+name: "shop/orders.proto"
+package: "shop"
+syntax: "proto3"
+dependency: "cloudevents/event_meta.proto"
+options { go_package: "example.com/shop;shop" }
+message_type {
+  name: "Order"
+  field { name: "item_sku" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "itemSku"
+    options { [cloudevents.rules] { pattern: "^[A-Z]+$" } } }
+  field { name: "item__sku" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "itemSku2"
+    options { [cloudevents.rules] { pattern: "^[a-z]+$" } } }
+  field { name: "amount" number: 3 label: LABEL_OPTIONAL type: TYPE_INT64 json_name: "amount"
+    options { [cloudevents.rules] { min: -9007199254740992 max: 9007199254740992 } } }
+  field { name: "sequence" number: 4 label: LABEL_OPTIONAL type: TYPE_UINT64 json_name: "sequence"
+    options { [cloudevents.rules] { min: 1.5 max: 1.8e19 } } }
+  field { name: "ratio" number: 5 label: LABEL_OPTIONAL type: TYPE_FLOAT json_name: "ratio"
+    options { [cloudevents.rules] { max: 0.5 } } }
+  nested_type {
+    name: "Item"
+    field { name: "sku" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "sku"
+      options { [cloudevents.rules] { pattern: "^[0-9]+$" } } }
+  }
+}
+message_type {
+  name: "OrderItem"
+  field { name: "sku" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "sku"
+    options { [cloudevents.rules] { pattern: "^sku-" } } }
+}
+`
+
+// TestGenerate_Validation 测试 pattern 变量名互不冲突，整数字段的 min/max 以整数比较，且生成的代码可以编译
+func TestGenerate_Validation(t *testing.T) {
+	files := parseFile(t, validatedFile)
+	gen, cfg := newPlugin(t, "", files)
+	require.NoError(t, generate(gen, cfg))
+	resp := gen.Response()
+	require.Empty(t, resp.GetError())
+	require.Len(t, resp.File, 1)
+	content := resp.File[0].GetContent()
+
+	for _, pattern := range []string{
+		`pattern_Order_ItemSku\s+= regexp.MustCompile\("\^\[A-Z\]\+\$"\)`,
+		`pattern_Order_Item_Sku\s+= regexp.MustCompile\("\^\[a-z\]\+\$"\)`,
+		`pattern_Order_Item_Sku_2\s+= regexp.MustCompile\("\^\[0-9\]\+\$"\)`,
+		`pattern_OrderItem_Sku\s+= regexp.MustCompile\("\^sku-"\)`,
+	} {
+		assert.Regexp(t, pattern, content)
+	}
+	for _, check := range []string{
+		"if x.GetAmount() < -9007199254740992 {",
+		"if x.GetAmount() > 9007199254740992 {",
+		"if x.GetSequence() < 2 {",
+		"if x.GetSequence() > 18000000000000000000 {",
+		"if float64(x.GetRatio()) > 0.5 {",
+	} {
+		assert.Contains(t, content, check)
+	}
+	assert.Contains(t, content, `violations.Add("sequence", "min", "value must be at least 1.5")`)
+
+	compileGenerated(t, append(protocGenGo(t, files), resp.File...)...)
+}
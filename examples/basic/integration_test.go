@@ -2,13 +2,19 @@ package basic_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/examples/basic/events"
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/examples/basic/events/eventstest"
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/runtime"
 	"github.com/yafeiaa/protoc-gen-cloudevents-go/transport/memory"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // TestEndToEnd_PublishSubscribe 端到端测试：发布订阅流程
@@ -29,7 +35,7 @@ func TestEndToEnd_PublishSubscribe(t *testing.T) {
 		return nil
 	}
 
-	err := bus.Subscribe(ctx, "user.created", handler)
+	_, err := bus.Subscribe(ctx, "user.created", handler)
 	require.NoError(t, err)
 
 	// 发布事件
@@ -75,17 +81,17 @@ func TestMultipleEventTypes(t *testing.T) {
 	updatedEvents := make(chan *cloudevents.Event, 10)
 	deletedEvents := make(chan *cloudevents.Event, 10)
 
-	_ = bus.Subscribe(ctx, "user.created", func(ctx context.Context, e *cloudevents.Event) error {
+	_, _ = bus.Subscribe(ctx, "user.created", func(ctx context.Context, e *cloudevents.Event) error {
 		createdEvents <- e
 		return nil
 	})
 
-	_ = bus.Subscribe(ctx, "user.updated", func(ctx context.Context, e *cloudevents.Event) error {
+	_, _ = bus.Subscribe(ctx, "user.updated", func(ctx context.Context, e *cloudevents.Event) error {
 		updatedEvents <- e
 		return nil
 	})
 
-	_ = bus.Subscribe(ctx, "user.deleted", func(ctx context.Context, e *cloudevents.Event) error {
+	_, _ = bus.Subscribe(ctx, "user.deleted", func(ctx context.Context, e *cloudevents.Event) error {
 		deletedEvents <- e
 		return nil
 	})
@@ -137,7 +143,7 @@ func TestEventOrdering(t *testing.T) {
 		return nil
 	}
 
-	err := bus.Subscribe(ctx, "order.test", handler)
+	_, err := bus.Subscribe(ctx, "order.test", handler)
 	require.NoError(t, err)
 
 	// 按顺序发布事件
@@ -184,7 +190,7 @@ func TestWildcardSubscription(t *testing.T) {
 	}
 
 	// 订阅 app.*.created 模式
-	err := bus.Subscribe(ctx, "app.*.created", handler)
+	_, err := bus.Subscribe(ctx, "app.*.created", handler)
 	require.NoError(t, err)
 
 	// 发布各种事件
@@ -248,7 +254,7 @@ func TestContextCancellation(t *testing.T) {
 		}
 	}
 
-	err := bus.Subscribe(ctx, "cancel.test", handler)
+	_, err := bus.Subscribe(ctx, "cancel.test", handler)
 	require.NoError(t, err)
 
 	// 取消上下文
@@ -293,7 +299,7 @@ func TestConcurrentAccess(t *testing.T) {
 				received <- event
 				return nil
 			}
-			_ = bus.Subscribe(ctx, "concurrent.*", handler)
+			_, _ = bus.Subscribe(ctx, "concurrent.*", handler)
 		}()
 	}
 
@@ -327,3 +333,437 @@ func TestConcurrentAccess(t *testing.T) {
 	t.Logf("Received %d events in concurrent test", count)
 	assert.Greater(t, count, 0, "should receive events in concurrent scenario")
 }
+
+// TestGeneratedSubjectTemplate 测试生成代码按 subject_template 路由并按过滤条件订阅
+func TestGeneratedSubjectTemplate(t *testing.T) {
+	bus := memory.NewMemoryBus()
+	defer bus.Close(context.Background())
+
+	ctx := context.Background()
+
+	assert.Equal(t, "myapp.order.created.USD.user-1",
+		events.OrderCreatedSubject(&events.OrderCreatedPayload{Currency: "USD", UserId: "user-1"}))
+	assert.Equal(t, "myapp.order.created.USD.*",
+		events.OrderCreatedSubjectFilter{Currency: "USD"}.Subject())
+
+	all := make(chan *events.OrderCreatedPayload, 10)
+	usd := make(chan *events.OrderCreatedPayload, 10)
+
+	_, err := events.SubscribeOrderCreated(ctx, bus,
+		func(ctx context.Context, payload *events.OrderCreatedPayload) error {
+			all <- payload
+			return nil
+		})
+	require.NoError(t, err)
+	_, err = events.SubscribeOrderCreatedFiltered(ctx, bus,
+		events.OrderCreatedSubjectFilter{Currency: "USD"},
+		func(ctx context.Context, payload *events.OrderCreatedPayload) error {
+			usd <- payload
+			return nil
+		})
+	require.NoError(t, err)
+
+	for _, currency := range []string{"USD", "EUR"} {
+		require.NoError(t, events.PublishOrderCreated(ctx, bus,
+			&events.OrderCreatedPayload{OrderId: "order-" + currency, UserId: "user-1", Currency: currency},
+			runtime.WithSource("test/integration")))
+	}
+
+	assert.Len(t, all, 2)
+	require.Len(t, usd, 1)
+	assert.Equal(t, "order-USD", (<-usd).OrderId)
+}
+
+// TestGeneratedEventVersion 测试生成代码设置 dataschema 和 dataversion
+func TestGeneratedEventVersion(t *testing.T) {
+	bus := memory.NewMemoryBus()
+	defer bus.Close(context.Background())
+
+	ctx := context.Background()
+	received := make(chan *cloudevents.Event, 1)
+	_, err := bus.Subscribe(ctx, "myapp.order.created.USD.user-1",
+		func(ctx context.Context, event *cloudevents.Event) error {
+			received <- event
+			return nil
+		})
+	require.NoError(t, err)
+
+	require.NoError(t, events.PublishOrderCreated(ctx, bus,
+		&events.OrderCreatedPayload{OrderId: "order-1", UserId: "user-1", Currency: "USD"},
+		runtime.WithSource("test/integration")))
+
+	require.Len(t, received, 1)
+	event := <-received
+	assert.Equal(t, events.EventOrderCreated.DataSchema, event.DataSchema())
+	version, err := runtime.DataVersion(event)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), version)
+}
+
+// TestGeneratedJSONEncoding 测试 encoding/json 编码的事件 (含 Timestamp 与 oneof) 可以往返解码
+func TestGeneratedJSONEncoding(t *testing.T) {
+	bus := memory.NewMemoryBus()
+	defer bus.Close(context.Background())
+
+	ctx := context.Background()
+	received := make(chan *events.OrderCancelledPayload, 2)
+	raw := make(chan *cloudevents.Event, 2)
+	_, err := events.SubscribeOrderCancelled(ctx, bus, func(ctx context.Context, payload *events.OrderCancelledPayload) error {
+		event, _ := runtime.EventFromContext(ctx)
+		raw <- event
+		received <- payload
+		return nil
+	})
+	require.NoError(t, err)
+
+	cancelledAt := timestamppb.New(time.Date(2024, 5, 1, 12, 30, 0, 500, time.UTC))
+	for _, payload := range []*events.OrderCancelledPayload{
+		{OrderId: "order-1", CancelledAt: cancelledAt,
+			Reason: &events.OrderCancelledPayload_CustomerNote{CustomerNote: "changed my mind"}},
+		{OrderId: "order-2", CancelledAt: cancelledAt,
+			Reason: &events.OrderCancelledPayload_ErrorCode{ErrorCode: 42}},
+	} {
+		require.NoError(t, events.PublishOrderCancelled(ctx, bus, payload, runtime.WithSource("test/integration")))
+		require.Len(t, received, 1)
+		got := <-received
+		assert.True(t, proto.Equal(payload, got), "got %v, want %v", got, payload)
+
+		// 数据是 encoding/json 的形状，而不是 protojson
+		event := <-raw
+		assert.Equal(t, cloudevents.ApplicationJSON, event.DataContentType())
+		assert.Contains(t, string(event.Data()), `"cancelled_at":{"seconds":`)
+		assert.Contains(t, string(event.Data()), `"Reason":{`)
+
+		// 注册表按同样的编码动态解码
+		msg, err := runtime.Decode(event)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(payload, msg))
+	}
+}
+
+// TestGeneratedRegistry 测试生成代码注册事件并可按类型动态解码
+func TestGeneratedRegistry(t *testing.T) {
+	info, ok := runtime.DefaultRegistry.Lookup(events.EventTypeUserRegistered)
+	require.True(t, ok)
+	assert.Equal(t, "myapp.events.UserRegisteredPayload", string(info.FullName))
+	assert.Equal(t, "User registered successfully", info.Description)
+
+	bus := memory.NewMemoryBus()
+	defer bus.Close(context.Background())
+
+	ctx := context.Background()
+	received := make(chan *cloudevents.Event, 1)
+	_, err := bus.Subscribe(ctx, events.EventTypeUserRegistered,
+		func(ctx context.Context, event *cloudevents.Event) error {
+			received <- event
+			return nil
+		})
+	require.NoError(t, err)
+
+	require.NoError(t, events.PublishUserRegistered(ctx, bus,
+		&events.UserRegisteredPayload{UserId: "user-1"},
+		runtime.WithSource("test/integration")))
+
+	require.Len(t, received, 1)
+	msg, err := runtime.Decode(<-received)
+	require.NoError(t, err)
+	require.IsType(t, &events.UserRegisteredPayload{}, msg)
+	assert.Equal(t, "user-1", msg.(*events.UserRegisteredPayload).UserId)
+}
+
+// TestGeneratedRegisterEvents 测试生成的 RegisterEventsEvents 注册到自定义注册表且重复注册是空操作
+func TestGeneratedRegisterEvents(t *testing.T) {
+	r := runtime.NewRegistry()
+	require.NoError(t, events.RegisterEventsEvents(r))
+	require.NoError(t, events.RegisterEventsEvents(r))
+	require.NoError(t, events.RegisterEventsEvents(runtime.DefaultRegistry))
+
+	var types []string
+	for _, info := range r.Events() {
+		types = append(types, info.Type)
+	}
+	assert.Equal(t, []string{
+		events.EventTypeOrderCancelled,
+		events.EventTypeOrderCreated,
+		events.EventTypeOrderStatus,
+		events.EventTypeGetOrderStatus,
+		events.EventTypeUserRegistered,
+	}, types)
+}
+
+// notificationHandler 实现生成的 NotificationServiceEventHandler 接口
+type notificationHandler struct {
+	users  []string
+	orders []*runtime.Envelope[events.OrderCreatedPayload]
+}
+
+func (h *notificationHandler) OnUserRegistered(ctx context.Context, payload *events.UserRegisteredPayload) error {
+	h.users = append(h.users, payload.UserId)
+	return nil
+}
+
+func (h *notificationHandler) OnOrderCreated(ctx context.Context,
+	envelope *runtime.Envelope[events.OrderCreatedPayload]) error {
+	h.orders = append(h.orders, envelope)
+	return nil
+}
+
+// TestGeneratedEventHandlers 测试生成的服务处理器接口一次注册所有订阅
+func TestGeneratedEventHandlers(t *testing.T) {
+	bus := memory.NewMemoryBus()
+	defer bus.Close(context.Background())
+
+	ctx := context.Background()
+	handler := &notificationHandler{}
+	_, err := events.RegisterNotificationServiceEventHandlers(ctx, bus, handler, "notifications")
+	require.NoError(t, err)
+
+	require.NoError(t, events.PublishUserRegistered(ctx, bus,
+		&events.UserRegisteredPayload{UserId: "user-1"},
+		runtime.WithSource("test/integration")))
+	require.NoError(t, events.PublishOrderCreated(ctx, bus,
+		&events.OrderCreatedPayload{OrderId: "order-1", UserId: "user-1", Currency: "USD"},
+		runtime.WithSource("test/integration"), events.WithRegion("eu-west-1")))
+
+	assert.Equal(t, []string{"user-1"}, handler.users)
+	require.Len(t, handler.orders, 1)
+	assert.Equal(t, "order-1", handler.orders[0].Payload.OrderId)
+	assert.Equal(t, "myapp.order.created.USD.user-1", handler.orders[0].Subject)
+	region, ok := handler.orders[0].ExtensionString(events.ExtensionRegion)
+	assert.True(t, ok)
+	assert.Equal(t, "eu-west-1", region)
+
+	_, err = events.RegisterNotificationServiceEventHandlers(ctx, bus, nil, "notifications")
+	assert.Error(t, err)
+}
+
+// TestGeneratedRequestReply 测试生成的请求/响应函数
+func TestGeneratedRequestReply(t *testing.T) {
+	bus := memory.NewMemoryBus()
+	defer bus.Close(context.Background())
+
+	ctx := context.Background()
+	_, err := events.HandleGetOrderStatus(ctx, bus, "order-service",
+		func(ctx context.Context, req *events.GetOrderStatusPayload) (*events.OrderStatusPayload, error) {
+			if req.OrderId == "" {
+				return nil, errors.New("order_id is required")
+			}
+			return &events.OrderStatusPayload{OrderId: req.OrderId, Status: "shipped"}, nil
+		}, runtime.WithSource("test/order-service"))
+	require.NoError(t, err)
+
+	reply, err := events.RequestGetOrderStatus(ctx, bus, &events.GetOrderStatusPayload{OrderId: "order-1"},
+		runtime.WithSource("test/integration"))
+	require.NoError(t, err)
+	assert.Equal(t, "order-1", reply.OrderId)
+	assert.Equal(t, "shipped", reply.Status)
+
+	_, err = events.RequestGetOrderStatus(ctx, bus, &events.GetOrderStatusPayload{},
+		runtime.WithSource("test/integration"))
+	var replyErr *runtime.ReplyError
+	require.ErrorAs(t, err, &replyErr)
+	assert.Equal(t, "order_id is required", replyErr.Message)
+}
+
+// TestGeneratedValidation 测试生成的 Validate 在发布前执行并列出所有违规
+func TestGeneratedValidation(t *testing.T) {
+	bus := memory.NewMemoryBus()
+	defer bus.Close(context.Background())
+
+	ctx := context.Background()
+	err := events.PublishOrderCreated(ctx, bus,
+		&events.OrderCreatedPayload{UserId: "user-1", Currency: "usd", Amount: -1},
+		runtime.WithSource("test/integration"))
+
+	var verr *runtime.ValidationError
+	require.ErrorAs(t, err, &verr)
+	var fields []string
+	for _, v := range verr.Violations {
+		fields = append(fields, v.Field+"/"+v.Rule)
+	}
+	assert.Equal(t, []string{"order_id/required", "amount/min", "currency/pattern"}, fields)
+
+	assert.NoError(t, (&events.OrderCreatedPayload{OrderId: "order-1", UserId: "user-1", Currency: "USD"}).Validate())
+}
+
+// TestGeneratedRecorder 测试生成的测试包为 runtime.Recorder 记录的事件提供类型化访问与断言
+func TestGeneratedRecorder(t *testing.T) {
+	ctx := context.Background()
+	rec := runtime.NewRecorder()
+
+	require.NoError(t, events.PublishUserRegistered(ctx, rec,
+		&events.UserRegisteredPayload{UserId: "user-1", Email: "alice@example.com"},
+		runtime.WithSource("test/integration")))
+	require.NoError(t, events.PublishOrderCreated(ctx, rec,
+		&events.OrderCreatedPayload{OrderId: "order-1", UserId: "user-1", Currency: "USD"},
+		runtime.WithSource("test/integration")))
+
+	users := eventstest.UserRegistered(t, rec)
+	require.Len(t, users, 1)
+	assert.Equal(t, "alice@example.com", users[0].Email)
+	envelopes := eventstest.OrderCreatedEnvelopes(t, rec)
+	require.Len(t, envelopes, 1)
+	assert.Equal(t, "myapp.order.created.USD.user-1", envelopes[0].Subject)
+
+	order := eventstest.AssertPublishedOrderCreated(t, rec, func(p *events.OrderCreatedPayload) bool {
+		return p.UserId == "user-1"
+	})
+	assert.Equal(t, "order-1", order.OrderId)
+	eventstest.AssertNotPublishedOrderCreated(t, rec, func(p *events.OrderCreatedPayload) bool {
+		return p.UserId == "user-2"
+	})
+	eventstest.AssertNotPublishedOrderStatus(t, rec)
+
+	rec.Reset()
+	assert.Empty(t, eventstest.UserRegistered(t, rec))
+}
+
+// TestGeneratedPartitionKey 测试生成代码从 payload 设置 partitionkey 扩展
+func TestGeneratedPartitionKey(t *testing.T) {
+	ctx := context.Background()
+	rec := runtime.NewRecorder()
+	payload := &events.OrderCreatedPayload{OrderId: "order-1", UserId: "user-1", Currency: "USD"}
+
+	require.NoError(t, events.PublishOrderCreated(ctx, rec, payload, runtime.WithSource("test/integration")))
+	require.NoError(t, events.PublishOrderCreated(ctx, rec, payload,
+		runtime.WithSource("test/integration"), runtime.WithPartitionKey("user-1")))
+
+	envelopes := eventstest.OrderCreatedEnvelopes(t, rec)
+	require.Len(t, envelopes, 2)
+	key, ok := runtime.PartitionKey(envelopes[0].Event)
+	assert.True(t, ok)
+	assert.Equal(t, "order-1", key)
+	key, _ = runtime.PartitionKey(envelopes[1].Event)
+	assert.Equal(t, "user-1", key)
+	assert.Equal(t, "order-1", events.OrderCreatedPartitionKey(payload))
+}
+
+// TestGeneratedDeterministicID 测试生成代码从 payload 字段派生确定性 id
+func TestGeneratedDeterministicID(t *testing.T) {
+	ctx := context.Background()
+	rec := runtime.NewRecorder()
+	payload := &events.OrderCreatedPayload{OrderId: "order-1", UserId: "user-1", Currency: "USD"}
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, events.PublishOrderCreated(ctx, rec, payload, runtime.WithSource("test/integration")))
+	}
+	require.NoError(t, events.PublishOrderCreated(ctx, rec,
+		&events.OrderCreatedPayload{OrderId: "order-2", UserId: "user-1", Currency: "USD"},
+		runtime.WithSource("test/integration")))
+	require.NoError(t, events.PublishOrderCreated(ctx, rec, payload,
+		runtime.WithSource("test/integration"), runtime.WithID("custom-id")))
+
+	envelopes := eventstest.OrderCreatedEnvelopes(t, rec)
+	require.Len(t, envelopes, 4)
+	assert.Equal(t, events.OrderCreatedID(payload), envelopes[0].ID)
+	assert.Equal(t, envelopes[0].ID, envelopes[1].ID)
+	assert.NotEqual(t, envelopes[0].ID, envelopes[2].ID)
+	assert.Equal(t, "custom-id", envelopes[3].ID)
+}
+
+// TestGeneratedSubscribeOptions 测试订阅自定义主题并跳过共享主题上的其他事件类型
+func TestGeneratedSubscribeOptions(t *testing.T) {
+	bus := memory.NewMemoryBus()
+	defer bus.Close(context.Background())
+	ctx := context.Background()
+
+	var prefixed, custom []string
+	_, err := events.SubscribeUserRegistered(ctx, bus,
+		func(ctx context.Context, payload *events.UserRegisteredPayload) error {
+			prefixed = append(prefixed, payload.UserId)
+			return nil
+		}, runtime.WithSubjectPrefix(events.EventTypeUserRegistered))
+	require.NoError(t, err)
+	_, err = events.SubscribeUserRegistered(ctx, bus,
+		func(ctx context.Context, payload *events.UserRegisteredPayload) error {
+			custom = append(custom, payload.UserId)
+			return nil
+		}, runtime.WithSubscribeSubject("shared.subject"))
+	require.NoError(t, err)
+
+	source := runtime.WithSource("test/integration")
+	require.NoError(t, events.PublishUserRegistered(ctx, bus, &events.UserRegisteredPayload{UserId: "user-us"},
+		source, runtime.WithSubject(events.EventTypeUserRegistered+".us")))
+	require.NoError(t, events.PublishUserRegistered(ctx, bus, &events.UserRegisteredPayload{UserId: "user-shared"},
+		source, runtime.WithSubject("shared.subject")))
+	require.NoError(t, events.PublishOrderStatus(ctx, bus, &events.OrderStatusPayload{OrderId: "order-1"},
+		source, runtime.WithSubject("shared.subject")))
+
+	assert.Equal(t, []string{"user-us"}, prefixed)
+	assert.Equal(t, []string{"user-shared"}, custom)
+}
+
+// TestGeneratedSubscription 测试生成的订阅函数返回可取消的订阅句柄
+func TestGeneratedSubscription(t *testing.T) {
+	bus := memory.NewMemoryBus()
+	defer bus.Close(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var received []string
+	sub, err := events.SubscribeUserRegistered(ctx, bus,
+		func(ctx context.Context, payload *events.UserRegisteredPayload) error {
+			received = append(received, payload.UserId)
+			return nil
+		})
+	require.NoError(t, err)
+	handler := &notificationHandler{}
+	subs, err := events.RegisterNotificationServiceEventHandlers(ctx, bus, handler, "notifications")
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+
+	publish := func(userID string) {
+		require.NoError(t, events.PublishUserRegistered(context.Background(), bus,
+			&events.UserRegisteredPayload{UserId: userID},
+			runtime.WithSource("test/integration")))
+	}
+	publish("user-1")
+	require.NoError(t, subs.Unsubscribe())
+	publish("user-2")
+	require.NoError(t, sub.Drain(context.Background()))
+	publish("user-3")
+
+	assert.Equal(t, []string{"user-1", "user-2"}, received)
+	assert.Equal(t, []string{"user-1"}, handler.users)
+}
+
+// TestGeneratedMiddleware 测试生成的发布/订阅函数经过总线与订阅中间件
+func TestGeneratedMiddleware(t *testing.T) {
+	memoryBus := memory.NewMemoryBus()
+	defer memoryBus.Close(context.Background())
+
+	var published, handled []string
+	bus := runtime.NewMiddlewareBus(memoryBus).
+		UsePublish(func(next runtime.PublishFunc) runtime.PublishFunc {
+			return func(ctx context.Context, subject string, event *cloudevents.Event) error {
+				published = append(published, subject)
+				return next(ctx, subject, event)
+			}
+		}).
+		UseHandler(runtime.Recover())
+
+	ctx := context.Background()
+	_, err := events.SubscribeUserRegistered(ctx, bus,
+		func(ctx context.Context, payload *events.UserRegisteredPayload) error {
+			if payload.UserId == "panic" {
+				panic("handler failure")
+			}
+			return nil
+		}, runtime.WithHandlerMiddleware(func(next runtime.EventHandler) runtime.EventHandler {
+			return func(ctx context.Context, event *cloudevents.Event) error {
+				handled = append(handled, event.Type())
+				return next(ctx, event)
+			}
+		}))
+	require.NoError(t, err)
+
+	for _, userID := range []string{"user-1", "panic"} {
+		require.NoError(t, events.PublishUserRegistered(ctx, bus,
+			&events.UserRegisteredPayload{UserId: userID},
+			runtime.WithSource("test/integration")))
+	}
+
+	assert.Equal(t, []string{events.EventTypeUserRegistered, events.EventTypeUserRegistered}, published)
+	assert.Equal(t, []string{events.EventTypeUserRegistered, events.EventTypeUserRegistered}, handled)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/yafeiaa/protoc-gen-cloudevents-go/examples/basic/events"
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/runtime"
 	"github.com/yafeiaa/protoc-gen-cloudevents-go/transport/memory"
 )
 
@@ -24,8 +25,8 @@ func main() {
 			Username:     "johndoe",
 			RegisteredAt: time.Now().Unix(),
 		},
-		events.WithSource("myapp/api-server"),
-		events.WithExtension("trace_id", "trace-abc-123"),
+		runtime.WithSource("myapp/api-server"),
+		events.WithTraceID("trace-abc-123"),
 	)
 	if err != nil {
 		log.Fatalf("Failed to publish event: %v", err)
@@ -42,8 +43,8 @@ func main() {
 			Currency: "USD",
 			Items:    []string{"item-1", "item-2"},
 		},
-		events.WithSource("myapp/order-service"),
-		events.WithExtension("region", "us-west-2"),
+		runtime.WithSource("myapp/order-service"),
+		events.WithRegion("us-west-2"),
 	)
 	if err != nil {
 		log.Fatalf("Failed to publish event: %v", err)
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/yafeiaa/protoc-gen-cloudevents-go/examples/basic/events"
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/runtime"
 	"github.com/yafeiaa/protoc-gen-cloudevents-go/transport/memory"
 )
 
@@ -17,10 +18,10 @@ func main() {
 
 	// Subscribe to user registration events (broadcast mode)
 	log.Println("🔔 Subscribing to user registration events (broadcast mode)...")
-	err := events.SubscribeUserRegistered(ctx, bus,
-		func(ctx context.Context, payload *events.UserRegisteredPayload) error {
-			log.Printf("✉️  Received user registration event: user_id=%s, email=%s",
-				payload.UserId, payload.Email)
+	_, err := events.SubscribeUserRegisteredEnvelope(ctx, bus,
+		func(ctx context.Context, env *runtime.Envelope[events.UserRegisteredPayload]) error {
+			log.Printf("✉️  Received user registration event %s: user_id=%s, email=%s",
+				env.ID, env.Payload.UserId, env.Payload.Email)
 			return nil
 		})
 	if err != nil {
@@ -29,7 +30,7 @@ func main() {
 
 	// Subscribe to order creation events (handler group mode)
 	log.Println("🔔 Subscribing to order creation events (handler group: email-sender)...")
-	err = events.SubscribeOrderCreatedWithGroup(ctx, bus, "email-sender",
+	_, err = events.SubscribeOrderCreatedWithGroup(ctx, bus, "email-sender",
 		func(ctx context.Context, payload *events.OrderCreatedPayload) error {
 			log.Printf("📧 Sending order confirmation email: order_id=%s, amount=%.2f %s",
 				payload.OrderId, payload.Amount, payload.Currency)
@@ -40,7 +41,7 @@ func main() {
 	}
 
 	log.Println("🔔 Subscribing to order creation events (handler group: analytics)...")
-	err = events.SubscribeOrderCreatedWithGroup(ctx, bus, "analytics",
+	_, err = events.SubscribeOrderCreatedWithGroup(ctx, bus, "analytics",
 		func(ctx context.Context, payload *events.OrderCreatedPayload) error {
 			log.Printf("📊 Recording order analytics data: order_id=%s, items=%d",
 				payload.OrderId, len(payload.Items))
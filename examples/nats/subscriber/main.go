@@ -28,7 +28,7 @@ func main() {
 
 	// Subscribe to user registration events (broadcast mode)
 	log.Println("🔔 Subscribing to user registration events (broadcast mode)...")
-	err = events.SubscribeUserRegistered(ctx, bus,
+	_, err = events.SubscribeUserRegistered(ctx, bus,
 		func(ctx context.Context, payload *events.UserRegisteredPayload) error {
 			log.Printf("✉️  Received user registration event: user_id=%s, email=%s",
 				payload.UserId, payload.Email)
@@ -40,7 +40,7 @@ func main() {
 
 	// Subscribe to order creation events (handler group mode)
 	log.Println("🔔 Subscribing to order creation events (handler group: email-sender)...")
-	err = events.SubscribeOrderCreatedWithGroup(ctx, bus, "email-sender",
+	_, err = events.SubscribeOrderCreatedWithGroup(ctx, bus, "email-sender",
 		func(ctx context.Context, payload *events.OrderCreatedPayload) error {
 			log.Printf("📧 [email-sender] Sending order confirmation email: order_id=%s, amount=%.2f %s",
 				payload.OrderId, payload.Amount, payload.Currency)
@@ -51,7 +51,7 @@ func main() {
 	}
 
 	log.Println("🔔 Subscribing to order creation events (handler group: analytics)...")
-	err = events.SubscribeOrderCreatedWithGroup(ctx, bus, "analytics",
+	_, err = events.SubscribeOrderCreatedWithGroup(ctx, bus, "analytics",
 		func(ctx context.Context, payload *events.OrderCreatedPayload) error {
 			log.Printf("📊 [analytics] Recording order analytics data: order_id=%s, items=%d",
 				payload.OrderId, len(payload.Items))
@@ -1,18 +1,18 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.22.0
+// 	protoc-gen-go v1.36.10
 // 	protoc        v3.12.4
 // source: cloudevents/event_meta.proto
 
 package cloudevents
 
 import (
-	proto "github.com/golang/protobuf/proto"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -22,33 +22,178 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-// This is a compile-time assertion that a sufficiently up-to-date version
-// of the legacy proto package is being used.
-const _ = proto.ProtoPackageIsVersion4
+// ExtensionType is the value type of a CloudEvents extension
+type ExtensionType int32
+
+const (
+	// EXTENSION_TYPE_UNSPECIFIED is treated as EXTENSION_TYPE_STRING
+	ExtensionType_EXTENSION_TYPE_UNSPECIFIED ExtensionType = 0
+	// EXTENSION_TYPE_STRING is a CloudEvents String (Go string)
+	ExtensionType_EXTENSION_TYPE_STRING ExtensionType = 1
+	// EXTENSION_TYPE_INTEGER is a CloudEvents Integer (Go int32)
+	ExtensionType_EXTENSION_TYPE_INTEGER ExtensionType = 2
+	// EXTENSION_TYPE_BOOLEAN is a CloudEvents Boolean (Go bool)
+	ExtensionType_EXTENSION_TYPE_BOOLEAN ExtensionType = 3
+	// EXTENSION_TYPE_TIMESTAMP is a CloudEvents Timestamp (Go time.Time)
+	ExtensionType_EXTENSION_TYPE_TIMESTAMP ExtensionType = 4
+)
+
+// Enum value maps for ExtensionType.
+var (
+	ExtensionType_name = map[int32]string{
+		0: "EXTENSION_TYPE_UNSPECIFIED",
+		1: "EXTENSION_TYPE_STRING",
+		2: "EXTENSION_TYPE_INTEGER",
+		3: "EXTENSION_TYPE_BOOLEAN",
+		4: "EXTENSION_TYPE_TIMESTAMP",
+	}
+	ExtensionType_value = map[string]int32{
+		"EXTENSION_TYPE_UNSPECIFIED": 0,
+		"EXTENSION_TYPE_STRING":      1,
+		"EXTENSION_TYPE_INTEGER":     2,
+		"EXTENSION_TYPE_BOOLEAN":     3,
+		"EXTENSION_TYPE_TIMESTAMP":   4,
+	}
+)
+
+func (x ExtensionType) Enum() *ExtensionType {
+	p := new(ExtensionType)
+	*p = x
+	return p
+}
+
+func (x ExtensionType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ExtensionType) Descriptor() protoreflect.EnumDescriptor {
+	return file_cloudevents_event_meta_proto_enumTypes[0].Descriptor()
+}
+
+func (ExtensionType) Type() protoreflect.EnumType {
+	return &file_cloudevents_event_meta_proto_enumTypes[0]
+}
+
+func (x ExtensionType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ExtensionType.Descriptor instead.
+func (ExtensionType) EnumDescriptor() ([]byte, []int) {
+	return file_cloudevents_event_meta_proto_rawDescGZIP(), []int{0}
+}
+
+// Encoding selects how an event payload is encoded into the CloudEvents data
+type Encoding int32
+
+const (
+	// ENCODING_UNSPECIFIED uses the encoding plugin option
+	Encoding_ENCODING_UNSPECIFIED Encoding = 0
+	// ENCODING_PROTOJSON encodes with the canonical proto3 JSON mapping (application/json)
+	Encoding_ENCODING_PROTOJSON Encoding = 1
+	// ENCODING_PROTOBUF encodes with the binary protobuf wire format (application/protobuf)
+	Encoding_ENCODING_PROTOBUF Encoding = 2
+	// ENCODING_JSON encodes with encoding/json (application/json), kept for compatibility
+	Encoding_ENCODING_JSON Encoding = 3
+)
+
+// Enum value maps for Encoding.
+var (
+	Encoding_name = map[int32]string{
+		0: "ENCODING_UNSPECIFIED",
+		1: "ENCODING_PROTOJSON",
+		2: "ENCODING_PROTOBUF",
+		3: "ENCODING_JSON",
+	}
+	Encoding_value = map[string]int32{
+		"ENCODING_UNSPECIFIED": 0,
+		"ENCODING_PROTOJSON":   1,
+		"ENCODING_PROTOBUF":    2,
+		"ENCODING_JSON":        3,
+	}
+)
+
+func (x Encoding) Enum() *Encoding {
+	p := new(Encoding)
+	*p = x
+	return p
+}
+
+func (x Encoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Encoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_cloudevents_event_meta_proto_enumTypes[1].Descriptor()
+}
+
+func (Encoding) Type() protoreflect.EnumType {
+	return &file_cloudevents_event_meta_proto_enumTypes[1]
+}
+
+func (x Encoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Encoding.Descriptor instead.
+func (Encoding) EnumDescriptor() ([]byte, []int) {
+	return file_cloudevents_event_meta_proto_rawDescGZIP(), []int{1}
+}
 
 // EventMeta defines the metadata for an event, used through message options
 type EventMeta struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
-
+	state protoimpl.MessageState `protogen:"open.v1"`
 	// event_type is the unique event identifier (globally unique, used for routing, identification, and CloudEvents type)
 	// Format: {domain}.{resource}.{action}
 	// Examples: "myapp.user.registered"
-	//
-	//	"myapp.order.created"
+	//           "myapp.order.created"
 	EventType string `protobuf:"bytes,1,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
 	// description is the event description (optional, used for documentation and comments)
 	Description string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// encoding overrides how the payload is encoded into the CloudEvents data (optional)
+	// Defaults to the encoding plugin option (protojson unless configured otherwise)
+	Encoding Encoding `protobuf:"varint,3,opt,name=encoding,proto3,enum=cloudevents.Encoding" json:"encoding,omitempty"`
+	// subject_template derives the publish subject from payload fields (optional)
+	// Tokens are separated by dots; a token is either a literal or a {field} placeholder,
+	// where field is a singular scalar field path of the payload such as {user_id} or {customer.id}
+	// Example: "myapp.order.created.{currency}.{user_id}"
+	// Defaults to event_type if not specified
+	SubjectTemplate string `protobuf:"bytes,4,opt,name=subject_template,json=subjectTemplate,proto3" json:"subject_template,omitempty"`
+	// version is the current payload schema version (optional, 0 means unversioned)
+	// Publishers stamp it into the "dataversion" CloudEvents extension; subscribers treat
+	// events without the extension as version 1 and run registered upcasters for older versions
+	Version uint32 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	// dataschema is the URI of the payload schema for the current version (optional)
+	// It is set as the CloudEvents dataschema attribute of published events
+	// Example: "https://schemas.example.com/myapp.order.created/v2.json"
+	Dataschema string `protobuf:"bytes,6,opt,name=dataschema,proto3" json:"dataschema,omitempty"`
+	// extensions declares the CloudEvents extensions of the event (optional)
+	// Extensions declared with the file level extensions option apply to every event of the file
+	Extensions []*Extension `protobuf:"bytes,7,rep,name=extensions,proto3" json:"extensions,omitempty"`
+	// reply makes the event a request answered with the named reply event (optional)
+	// The reply message must itself carry the event_meta option; its name is resolved like a
+	// proto type name, relative to the package of the request, e.g. "GetOrderReply" or "myapp.orders.GetOrderReply"
+	// The generator emits RequestXxx() and HandleXxx() functions for request/reply
+	Reply string `protobuf:"bytes,8,opt,name=reply,proto3" json:"reply,omitempty"`
+	// partition_key is the payload field path whose value orders the events (optional)
+	// Publishers set it as the "partitionkey" CloudEvents extension; transports that honour ordering
+	// deliver all events with the same key to the same handler group member, in publish order
+	// The field must be a singular scalar field path such as "order_id" or "customer.id"
+	PartitionKey string `protobuf:"bytes,9,opt,name=partition_key,json=partitionKey,proto3" json:"partition_key,omitempty"`
+	// id_fields derives the CloudEvents id from the event_type and the listed payload field paths (optional)
+	// The id is a UUIDv5, so publishing the same business fact twice, e.g. when retrying after a timeout,
+	// yields the same id and consumers can deduplicate; by default every publish gets a random UUIDv4
+	// Fields must be singular scalar field paths such as "order_id" or "customer.id"
+	IdFields      []string `protobuf:"bytes,10,rep,name=id_fields,json=idFields,proto3" json:"id_fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *EventMeta) Reset() {
 	*x = EventMeta{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_cloudevents_event_meta_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_cloudevents_event_meta_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *EventMeta) String() string {
@@ -59,7 +204,7 @@ func (*EventMeta) ProtoMessage() {}
 
 func (x *EventMeta) ProtoReflect() protoreflect.Message {
 	mi := &file_cloudevents_event_meta_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -88,6 +233,295 @@ func (x *EventMeta) GetDescription() string {
 	return ""
 }
 
+func (x *EventMeta) GetEncoding() Encoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return Encoding_ENCODING_UNSPECIFIED
+}
+
+func (x *EventMeta) GetSubjectTemplate() string {
+	if x != nil {
+		return x.SubjectTemplate
+	}
+	return ""
+}
+
+func (x *EventMeta) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *EventMeta) GetDataschema() string {
+	if x != nil {
+		return x.Dataschema
+	}
+	return ""
+}
+
+func (x *EventMeta) GetExtensions() []*Extension {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+func (x *EventMeta) GetReply() string {
+	if x != nil {
+		return x.Reply
+	}
+	return ""
+}
+
+func (x *EventMeta) GetPartitionKey() string {
+	if x != nil {
+		return x.PartitionKey
+	}
+	return ""
+}
+
+func (x *EventMeta) GetIdFields() []string {
+	if x != nil {
+		return x.IdFields
+	}
+	return nil
+}
+
+// Extension declares a CloudEvents extension attribute
+// The generator emits a typed WithXxx() publish option and a typed XxxFromContext() getter for it
+type Extension struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name is the extension attribute name (required)
+	// It must consist of 1 to 20 lowercase letters or digits, e.g. "tenantid"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// type is the extension value type, string if not specified
+	Type ExtensionType `protobuf:"varint,2,opt,name=type,proto3,enum=cloudevents.ExtensionType" json:"type,omitempty"`
+	// go_name is the Go name used in generated identifiers, e.g. "TenantID" for WithTenantID() (optional)
+	// Defaults to name with its first letter capitalized
+	GoName string `protobuf:"bytes,3,opt,name=go_name,json=goName,proto3" json:"go_name,omitempty"`
+	// description is the extension description (optional, used for documentation and comments)
+	Description string `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	// required makes publishing fail when the extension is not set
+	Required      bool `protobuf:"varint,5,opt,name=required,proto3" json:"required,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Extension) Reset() {
+	*x = Extension{}
+	mi := &file_cloudevents_event_meta_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Extension) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Extension) ProtoMessage() {}
+
+func (x *Extension) ProtoReflect() protoreflect.Message {
+	mi := &file_cloudevents_event_meta_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Extension.ProtoReflect.Descriptor instead.
+func (*Extension) Descriptor() ([]byte, []int) {
+	return file_cloudevents_event_meta_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Extension) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Extension) GetType() ExtensionType {
+	if x != nil {
+		return x.Type
+	}
+	return ExtensionType_EXTENSION_TYPE_UNSPECIFIED
+}
+
+func (x *Extension) GetGoName() string {
+	if x != nil {
+		return x.GoName
+	}
+	return ""
+}
+
+func (x *Extension) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Extension) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+// EventHandler marks a service method as an event handler
+// The method request type must be an event payload (a message with the event_meta option);
+// the response type is ignored, google.protobuf.Empty is recommended
+type EventHandler struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// envelope passes the handler the payload together with the event's CloudEvents attributes and extensions
+	Envelope      bool `protobuf:"varint,1,opt,name=envelope,proto3" json:"envelope,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventHandler) Reset() {
+	*x = EventHandler{}
+	mi := &file_cloudevents_event_meta_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventHandler) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventHandler) ProtoMessage() {}
+
+func (x *EventHandler) ProtoReflect() protoreflect.Message {
+	mi := &file_cloudevents_event_meta_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventHandler.ProtoReflect.Descriptor instead.
+func (*EventHandler) Descriptor() ([]byte, []int) {
+	return file_cloudevents_event_meta_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EventHandler) GetEnvelope() bool {
+	if x != nil {
+		return x.Envelope
+	}
+	return false
+}
+
+// FieldRules declares the constraints a payload field must satisfy
+// The generator emits a Validate() method that publishing runs before sending and subscribing runs after decoding
+type FieldRules struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// required rejects unset fields: nil messages, empty strings, bytes, lists and maps,
+	// zero scalars and enums, unset optional and oneof fields
+	Required bool `protobuf:"varint,1,opt,name=required,proto3" json:"required,omitempty"`
+	// pattern is a regular expression (RE2 syntax) string values must match, e.g. "^[A-Z]{3}$"
+	Pattern string `protobuf:"bytes,2,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// min and max bound numeric values, inclusive
+	Min *float64 `protobuf:"fixed64,3,opt,name=min,proto3,oneof" json:"min,omitempty"`
+	Max *float64 `protobuf:"fixed64,4,opt,name=max,proto3,oneof" json:"max,omitempty"`
+	// min_items and max_items bound the number of elements of repeated and map fields (max_items 0 means unbounded)
+	MinItems uint32 `protobuf:"varint,5,opt,name=min_items,json=minItems,proto3" json:"min_items,omitempty"`
+	MaxItems uint32 `protobuf:"varint,6,opt,name=max_items,json=maxItems,proto3" json:"max_items,omitempty"`
+	// defined_only rejects enum values not declared by the enum
+	DefinedOnly   bool `protobuf:"varint,7,opt,name=defined_only,json=definedOnly,proto3" json:"defined_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldRules) Reset() {
+	*x = FieldRules{}
+	mi := &file_cloudevents_event_meta_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldRules) ProtoMessage() {}
+
+func (x *FieldRules) ProtoReflect() protoreflect.Message {
+	mi := &file_cloudevents_event_meta_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldRules.ProtoReflect.Descriptor instead.
+func (*FieldRules) Descriptor() ([]byte, []int) {
+	return file_cloudevents_event_meta_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FieldRules) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *FieldRules) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *FieldRules) GetMin() float64 {
+	if x != nil && x.Min != nil {
+		return *x.Min
+	}
+	return 0
+}
+
+func (x *FieldRules) GetMax() float64 {
+	if x != nil && x.Max != nil {
+		return *x.Max
+	}
+	return 0
+}
+
+func (x *FieldRules) GetMinItems() uint32 {
+	if x != nil {
+		return x.MinItems
+	}
+	return 0
+}
+
+func (x *FieldRules) GetMaxItems() uint32 {
+	if x != nil {
+		return x.MaxItems
+	}
+	return 0
+}
+
+func (x *FieldRules) GetDefinedOnly() bool {
+	if x != nil {
+		return x.DefinedOnly
+	}
+	return false
+}
+
 var file_cloudevents_event_meta_proto_extTypes = []protoimpl.ExtensionInfo{
 	{
 		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
@@ -97,6 +531,30 @@ var file_cloudevents_event_meta_proto_extTypes = []protoimpl.ExtensionInfo{
 		Tag:           "bytes,50001,opt,name=event_meta",
 		Filename:      "cloudevents/event_meta.proto",
 	},
+	{
+		ExtendedType:  (*descriptorpb.FileOptions)(nil),
+		ExtensionType: ([]*Extension)(nil),
+		Field:         50001,
+		Name:          "cloudevents.extensions",
+		Tag:           "bytes,50001,rep,name=extensions",
+		Filename:      "cloudevents/event_meta.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*EventHandler)(nil),
+		Field:         50001,
+		Name:          "cloudevents.event_handler",
+		Tag:           "bytes,50001,opt,name=event_handler",
+		Filename:      "cloudevents/event_meta.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*FieldRules)(nil),
+		Field:         50001,
+		Name:          "cloudevents.rules",
+		Tag:           "bytes,50001,opt,name=rules",
+		Filename:      "cloudevents/event_meta.proto",
+	},
 }
 
 // Extension fields to descriptorpb.MessageOptions.
@@ -105,57 +563,127 @@ var (
 	E_EventMeta = &file_cloudevents_event_meta_proto_extTypes[0]
 )
 
+// Extension fields to descriptorpb.FileOptions.
+var (
+	// repeated cloudevents.Extension extensions = 50001;
+	E_Extensions = &file_cloudevents_event_meta_proto_extTypes[1]
+)
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional cloudevents.EventHandler event_handler = 50001;
+	E_EventHandler = &file_cloudevents_event_meta_proto_extTypes[2]
+)
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// optional cloudevents.FieldRules rules = 50001;
+	E_Rules = &file_cloudevents_event_meta_proto_extTypes[3]
+)
+
 var File_cloudevents_event_meta_proto protoreflect.FileDescriptor
 
-var file_cloudevents_event_meta_proto_rawDesc = []byte{
-	0x0a, 0x1c, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2f, 0x65, 0x76,
-	0x65, 0x6e, 0x74, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b,
-	0x63, 0x6c, 0x6f, 0x75, 0x64, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x1a, 0x20, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x65, 0x73,
-	0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x4c, 0x0a,
-	0x09, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x76,
-	0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
-	0x65, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73,
-	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
-	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x3a, 0x58, 0x0a, 0x0a, 0x65,
-	0x76, 0x65, 0x6e, 0x74, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x4d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0xd1, 0x86, 0x03, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73,
-	0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x52, 0x09, 0x65, 0x76, 0x65, 0x6e,
-	0x74, 0x4d, 0x65, 0x74, 0x61, 0x42, 0x49, 0x5a, 0x47, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
-	0x63, 0x6f, 0x6d, 0x2f, 0x79, 0x61, 0x66, 0x65, 0x69, 0x61, 0x61, 0x2f, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x63, 0x2d, 0x67, 0x65, 0x6e, 0x2d, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x65, 0x76, 0x65, 0x6e,
-	0x74, 0x73, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x65, 0x76,
-	0x65, 0x6e, 0x74, 0x73, 0x3b, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
+const file_cloudevents_event_meta_proto_rawDesc = "" +
+	"\n" +
+	"\x1ccloudevents/event_meta.proto\x12\vcloudevents\x1a google/protobuf/descriptor.proto\"\xf4\x02\n" +
+	"\tEventMeta\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x01 \x01(\tR\teventType\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x121\n" +
+	"\bencoding\x18\x03 \x01(\x0e2\x15.cloudevents.EncodingR\bencoding\x12)\n" +
+	"\x10subject_template\x18\x04 \x01(\tR\x0fsubjectTemplate\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\rR\aversion\x12\x1e\n" +
+	"\n" +
+	"dataschema\x18\x06 \x01(\tR\n" +
+	"dataschema\x126\n" +
+	"\n" +
+	"extensions\x18\a \x03(\v2\x16.cloudevents.ExtensionR\n" +
+	"extensions\x12\x14\n" +
+	"\x05reply\x18\b \x01(\tR\x05reply\x12#\n" +
+	"\rpartition_key\x18\t \x01(\tR\fpartitionKey\x12\x1b\n" +
+	"\tid_fields\x18\n" +
+	" \x03(\tR\bidFields\"\xa6\x01\n" +
+	"\tExtension\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12.\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x1a.cloudevents.ExtensionTypeR\x04type\x12\x17\n" +
+	"\ago_name\x18\x03 \x01(\tR\x06goName\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1a\n" +
+	"\brequired\x18\x05 \x01(\bR\brequired\"*\n" +
+	"\fEventHandler\x12\x1a\n" +
+	"\benvelope\x18\x01 \x01(\bR\benvelope\"\xdd\x01\n" +
+	"\n" +
+	"FieldRules\x12\x1a\n" +
+	"\brequired\x18\x01 \x01(\bR\brequired\x12\x18\n" +
+	"\apattern\x18\x02 \x01(\tR\apattern\x12\x15\n" +
+	"\x03min\x18\x03 \x01(\x01H\x00R\x03min\x88\x01\x01\x12\x15\n" +
+	"\x03max\x18\x04 \x01(\x01H\x01R\x03max\x88\x01\x01\x12\x1b\n" +
+	"\tmin_items\x18\x05 \x01(\rR\bminItems\x12\x1b\n" +
+	"\tmax_items\x18\x06 \x01(\rR\bmaxItems\x12!\n" +
+	"\fdefined_only\x18\a \x01(\bR\vdefinedOnlyB\x06\n" +
+	"\x04_minB\x06\n" +
+	"\x04_max*\xa0\x01\n" +
+	"\rExtensionType\x12\x1e\n" +
+	"\x1aEXTENSION_TYPE_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15EXTENSION_TYPE_STRING\x10\x01\x12\x1a\n" +
+	"\x16EXTENSION_TYPE_INTEGER\x10\x02\x12\x1a\n" +
+	"\x16EXTENSION_TYPE_BOOLEAN\x10\x03\x12\x1c\n" +
+	"\x18EXTENSION_TYPE_TIMESTAMP\x10\x04*f\n" +
+	"\bEncoding\x12\x18\n" +
+	"\x14ENCODING_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12ENCODING_PROTOJSON\x10\x01\x12\x15\n" +
+	"\x11ENCODING_PROTOBUF\x10\x02\x12\x11\n" +
+	"\rENCODING_JSON\x10\x03:X\n" +
+	"\n" +
+	"event_meta\x12\x1f.google.protobuf.MessageOptions\x18ц\x03 \x01(\v2\x16.cloudevents.EventMetaR\teventMeta:V\n" +
+	"\n" +
+	"extensions\x12\x1c.google.protobuf.FileOptions\x18ц\x03 \x03(\v2\x16.cloudevents.ExtensionR\n" +
+	"extensions:`\n" +
+	"\revent_handler\x12\x1e.google.protobuf.MethodOptions\x18ц\x03 \x01(\v2\x19.cloudevents.EventHandlerR\feventHandler:N\n" +
+	"\x05rules\x12\x1d.google.protobuf.FieldOptions\x18ц\x03 \x01(\v2\x17.cloudevents.FieldRulesR\x05rulesBLZJgithub.com/yafeiaa/protoc-gen-cloudevents-go/proto/cloudevents;cloudeventsb\x06proto3"
 
 var (
 	file_cloudevents_event_meta_proto_rawDescOnce sync.Once
-	file_cloudevents_event_meta_proto_rawDescData = file_cloudevents_event_meta_proto_rawDesc
+	file_cloudevents_event_meta_proto_rawDescData []byte
 )
 
 func file_cloudevents_event_meta_proto_rawDescGZIP() []byte {
 	file_cloudevents_event_meta_proto_rawDescOnce.Do(func() {
-		file_cloudevents_event_meta_proto_rawDescData = protoimpl.X.CompressGZIP(file_cloudevents_event_meta_proto_rawDescData)
+		file_cloudevents_event_meta_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_cloudevents_event_meta_proto_rawDesc), len(file_cloudevents_event_meta_proto_rawDesc)))
 	})
 	return file_cloudevents_event_meta_proto_rawDescData
 }
 
-var file_cloudevents_event_meta_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
-var file_cloudevents_event_meta_proto_goTypes = []interface{}{
-	(*EventMeta)(nil),                   // 0: cloudevents.EventMeta
-	(*descriptorpb.MessageOptions)(nil), // 1: google.protobuf.MessageOptions
+var file_cloudevents_event_meta_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_cloudevents_event_meta_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_cloudevents_event_meta_proto_goTypes = []any{
+	(ExtensionType)(0),                  // 0: cloudevents.ExtensionType
+	(Encoding)(0),                       // 1: cloudevents.Encoding
+	(*EventMeta)(nil),                   // 2: cloudevents.EventMeta
+	(*Extension)(nil),                   // 3: cloudevents.Extension
+	(*EventHandler)(nil),                // 4: cloudevents.EventHandler
+	(*FieldRules)(nil),                  // 5: cloudevents.FieldRules
+	(*descriptorpb.MessageOptions)(nil), // 6: google.protobuf.MessageOptions
+	(*descriptorpb.FileOptions)(nil),    // 7: google.protobuf.FileOptions
+	(*descriptorpb.MethodOptions)(nil),  // 8: google.protobuf.MethodOptions
+	(*descriptorpb.FieldOptions)(nil),   // 9: google.protobuf.FieldOptions
 }
 var file_cloudevents_event_meta_proto_depIdxs = []int32{
-	1, // 0: cloudevents.event_meta:extendee -> google.protobuf.MessageOptions
-	0, // 1: cloudevents.event_meta:type_name -> cloudevents.EventMeta
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	1, // [1:2] is the sub-list for extension type_name
-	0, // [0:1] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1,  // 0: cloudevents.EventMeta.encoding:type_name -> cloudevents.Encoding
+	3,  // 1: cloudevents.EventMeta.extensions:type_name -> cloudevents.Extension
+	0,  // 2: cloudevents.Extension.type:type_name -> cloudevents.ExtensionType
+	6,  // 3: cloudevents.event_meta:extendee -> google.protobuf.MessageOptions
+	7,  // 4: cloudevents.extensions:extendee -> google.protobuf.FileOptions
+	8,  // 5: cloudevents.event_handler:extendee -> google.protobuf.MethodOptions
+	9,  // 6: cloudevents.rules:extendee -> google.protobuf.FieldOptions
+	2,  // 7: cloudevents.event_meta:type_name -> cloudevents.EventMeta
+	3,  // 8: cloudevents.extensions:type_name -> cloudevents.Extension
+	4,  // 9: cloudevents.event_handler:type_name -> cloudevents.EventHandler
+	5,  // 10: cloudevents.rules:type_name -> cloudevents.FieldRules
+	11, // [11:11] is the sub-list for method output_type
+	11, // [11:11] is the sub-list for method input_type
+	7,  // [7:11] is the sub-list for extension type_name
+	3,  // [3:7] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_cloudevents_event_meta_proto_init() }
@@ -163,37 +691,24 @@ func file_cloudevents_event_meta_proto_init() {
 	if File_cloudevents_event_meta_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_cloudevents_event_meta_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EventMeta); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
+	file_cloudevents_event_meta_proto_msgTypes[3].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_cloudevents_event_meta_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   1,
-			NumExtensions: 1,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cloudevents_event_meta_proto_rawDesc), len(file_cloudevents_event_meta_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   4,
+			NumExtensions: 4,
 			NumServices:   0,
 		},
 		GoTypes:           file_cloudevents_event_meta_proto_goTypes,
 		DependencyIndexes: file_cloudevents_event_meta_proto_depIdxs,
+		EnumInfos:         file_cloudevents_event_meta_proto_enumTypes,
 		MessageInfos:      file_cloudevents_event_meta_proto_msgTypes,
 		ExtensionInfos:    file_cloudevents_event_meta_proto_extTypes,
 	}.Build()
 	File_cloudevents_event_meta_proto = out.File
-	file_cloudevents_event_meta_proto_rawDesc = nil
 	file_cloudevents_event_meta_proto_goTypes = nil
 	file_cloudevents_event_meta_proto_depIdxs = nil
 }
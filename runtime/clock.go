@@ -0,0 +1,41 @@
+package runtime
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock returns the current time, used as the time of published events
+type Clock func() time.Time
+
+var defaultClock atomic.Pointer[Clock]
+
+// SetClock sets the Clock of events published without WithClock. A nil clock restores time.Now
+func SetClock(clock Clock) {
+	if clock == nil {
+		defaultClock.Store(nil)
+		return
+	}
+	defaultClock.Store(&clock)
+}
+
+func currentClock() Clock {
+	if clock := defaultClock.Load(); clock != nil {
+		return *clock
+	}
+	return time.Now
+}
+
+// WithClock reads the event time from clock instead of the clock set with SetClock
+func WithClock(clock Clock) PublishOption {
+	return func(o *publishOptions) {
+		o.clock = clock
+	}
+}
+
+// FixedClock returns a Clock always returning t, for reproducible events in tests
+func FixedClock(t time.Time) Clock {
+	return func() time.Time {
+		return t
+	}
+}
@@ -0,0 +1,162 @@
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ApplicationProtobuf is the datacontenttype of events carrying binary protobuf data
+const ApplicationProtobuf = "application/protobuf"
+
+// Codec encodes event payloads into CloudEvents data
+type Codec interface {
+	// ContentType returns the datacontenttype set on events encoded by this codec
+	ContentType() string
+	// Marshal encodes a payload
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes data into a payload
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	// JSON encodes payloads with encoding/json
+	JSON Codec = jsonCodec{}
+	// ProtoJSON encodes proto payloads with the canonical proto3 JSON mapping
+	ProtoJSON Codec = protoJSONCodec{}
+	// Protobuf encodes proto payloads with the binary protobuf wire format
+	Protobuf Codec = protobufCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return cloudevents.ApplicationJSON }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal decodes data into v with encoding/json. Proto messages are decoded through their descriptor,
+// since encoding/json cannot decode the oneofs of generated structs; protojson data is accepted as well
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return json.Unmarshal(data, v)
+	}
+	proto.Reset(m)
+	err := unmarshalStructJSON(data, m.ProtoReflect())
+	if err == nil {
+		return nil
+	}
+	opts := protojson.UnmarshalOptions{DiscardUnknown: true}
+	proto.Reset(m)
+	if opts.Unmarshal(data, m) == nil {
+		return nil
+	}
+	return err
+}
+
+type protoJSONCodec struct{}
+
+func (protoJSONCodec) ContentType() string { return cloudevents.ApplicationJSON }
+
+func (protoJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	m, err := asProtoMessage(v)
+	if err != nil {
+		return nil, err
+	}
+	return protojson.Marshal(m)
+}
+
+// Unmarshal decodes data into v with protojson. Data encoded with encoding/json from the generated struct,
+// which protojson rejects or would misread, is decoded as the JSON codec does; unknown fields are discarded
+func (protoJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	m, err := asProtoMessage(v)
+	if err != nil {
+		return err
+	}
+	if protojson.Unmarshal(data, m) == nil {
+		return nil
+	}
+	proto.Reset(m)
+	if unmarshalStructJSON(data, m.ProtoReflect()) == nil {
+		return nil
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, m)
+}
+
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string { return ApplicationProtobuf }
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, err := asProtoMessage(v)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	m, err := asProtoMessage(v)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, m)
+}
+
+func asProtoMessage(v interface{}) (proto.Message, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto message", v)
+	}
+	return m, nil
+}
+
+// CodecForContentType returns the codec able to decode data of the given datacontenttype into v.
+// JSON data is decoded with protojson when v is a proto message; both JSON codecs also accept
+// the data produced by the other one
+func CodecForContentType(contentType string, v interface{}) (Codec, error) {
+	mediaType := contentType
+	if contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid datacontenttype %q: %w", contentType, err)
+		}
+		mediaType = parsed
+	}
+
+	switch {
+	case mediaType == ApplicationProtobuf, mediaType == "application/x-protobuf":
+		return Protobuf, nil
+	case mediaType == "", mediaType == cloudevents.ApplicationJSON, mediaType == "text/json",
+		strings.HasSuffix(mediaType, "+json"):
+		if _, ok := v.(proto.Message); ok {
+			return ProtoJSON, nil
+		}
+		return JSON, nil
+	default:
+		return nil, fmt.Errorf("unsupported datacontenttype %q", contentType)
+	}
+}
+
+// DecodeData decodes the data of event into v according to its datacontenttype
+func DecodeData(event *cloudevents.Event, v interface{}) error {
+	return decodeData(event, v, nil)
+}
+
+// decodeData decodes the data of event into v with preferred if it produces the datacontenttype of event,
+// e.g. with JSON for events published with encoding=json, or with the codec chosen by CodecForContentType
+func decodeData(event *cloudevents.Event, v interface{}, preferred Codec) error {
+	codec, err := CodecForContentType(event.DataContentType(), v)
+	if err != nil {
+		return err
+	}
+	if preferred != nil && preferred.ContentType() == codec.ContentType() {
+		codec = preferred
+	}
+	return codec.Unmarshal(event.Data(), v)
+}
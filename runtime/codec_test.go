@@ -0,0 +1,211 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// TestProtoJSONCodec 测试 protojson 编码使用 proto3 JSON 映射
+func TestProtoJSONCodec(t *testing.T) {
+	data, err := ProtoJSON.Marshal(wrapperspb.Int64(42))
+	require.NoError(t, err)
+	assert.JSONEq(t, `"42"`, string(data))
+	assert.Equal(t, "application/json", ProtoJSON.ContentType())
+
+	var out wrapperspb.Int64Value
+	require.NoError(t, ProtoJSON.Unmarshal(data, &out))
+	assert.Equal(t, int64(42), out.GetValue())
+
+	_, err = ProtoJSON.Marshal(&testPayload{})
+	assert.Error(t, err)
+}
+
+// TestProtobufCodec 测试二进制 protobuf 编码
+func TestProtobufCodec(t *testing.T) {
+	in := wrapperspb.String("hello")
+	data, err := Protobuf.Marshal(in)
+	require.NoError(t, err)
+	assert.Equal(t, ApplicationProtobuf, Protobuf.ContentType())
+
+	var out wrapperspb.StringValue
+	require.NoError(t, Protobuf.Unmarshal(data, &out))
+	assert.True(t, proto.Equal(in, &out))
+}
+
+// TestCodecForContentType 测试按 datacontenttype 选择解码器
+func TestCodecForContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		v           interface{}
+		want        Codec
+	}{
+		{"", &wrapperspb.StringValue{}, ProtoJSON},
+		{"application/json", &wrapperspb.StringValue{}, ProtoJSON},
+		{"application/json; charset=utf-8", &wrapperspb.StringValue{}, ProtoJSON},
+		{"application/cloudevents+json", &wrapperspb.StringValue{}, ProtoJSON},
+		{"application/json", &testPayload{}, JSON},
+		{"application/protobuf", &wrapperspb.StringValue{}, Protobuf},
+		{"application/x-protobuf", &wrapperspb.StringValue{}, Protobuf},
+	}
+	for _, tt := range tests {
+		got, err := CodecForContentType(tt.contentType, tt.v)
+		require.NoError(t, err, tt.contentType)
+		assert.Equal(t, tt.want, got, tt.contentType)
+	}
+
+	_, err := CodecForContentType("application/xml", &wrapperspb.StringValue{})
+	assert.Error(t, err)
+}
+
+// TestSubscribe_DecodesByContentType 测试订阅端按 datacontenttype 解码（包括 encoding/json 编码的旧数据）
+func TestSubscribe_DecodesByContentType(t *testing.T) {
+	ctx := context.Background()
+
+	for _, codec := range []Codec{ProtoJSON, Protobuf, JSON} {
+		bus := newFakeBus()
+		desc := &Event[descriptorpb.EnumValueDescriptorProto]{Type: "test.event.created", Codec: codec}
+
+		var got *descriptorpb.EnumValueDescriptorProto
+		_, err := Subscribe(ctx, bus, desc, "test.event.created",
+			func(ctx context.Context, p *descriptorpb.EnumValueDescriptorProto) error {
+				got = p
+				return nil
+			})
+		require.NoError(t, err)
+
+		payload := &descriptorpb.EnumValueDescriptorProto{Name: proto.String("ACTIVE"), Number: proto.Int32(7)}
+		event, subject, err := BuildEvent(desc, payload,
+			[]PublishOption{WithSource("test/source")})
+		require.NoError(t, err)
+		assert.Equal(t, codec.ContentType(), event.DataContentType())
+		require.NoError(t, bus.Publish(ctx, subject, event))
+
+		require.NotNil(t, got)
+		assert.True(t, proto.Equal(payload, got), "codec %T", codec)
+	}
+}
+
+// TestJSONCodec_ProtoMessages 测试 encoding/json 编码的生成结构体可以用两种 JSON 编码解码
+func TestJSONCodec_ProtoMessages(t *testing.T) {
+	for _, in := range []proto.Message{
+		timestamppb.New(time.Unix(1700000000, 42)),
+		&descriptorpb.DescriptorProto{
+			Name: proto.String("Order"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:   proto.String("id"),
+				Number: proto.Int32(1),
+				Type:   descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(),
+			}},
+			ReservedName: []string{"legacy"},
+		},
+	} {
+		data, err := JSON.Marshal(in)
+		require.NoError(t, err)
+		for _, codec := range []Codec{JSON, ProtoJSON} {
+			out := in.ProtoReflect().New().Interface()
+			require.NoError(t, codec.Unmarshal(data, out), "%T: %s", codec, data)
+			assert.True(t, proto.Equal(in, out), "%T: got %v, want %v", codec, out, in)
+		}
+	}
+
+	// JSON 也接受 protojson 编码的数据
+	var ts timestamppb.Timestamp
+	require.NoError(t, JSON.Unmarshal([]byte(`"2023-11-14T22:13:20Z"`), &ts))
+	assert.Equal(t, int64(1700000000), ts.GetSeconds())
+	assert.Error(t, JSON.Unmarshal([]byte(`{"seconds":"x"}`), &ts))
+}
+
+// orderFile 描述一个含 Timestamp、oneof、枚举、map 与 int64 列表的消息
+const orderFile = `
+name: "order.proto"
+package: "test"
+syntax: "proto3"
+dependency: "google/protobuf/timestamp.proto"
+message_type {
+  name: "Order"
+  field { name: "order_id" number: 1 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "orderId" }
+  field { name: "created_at" number: 2 label: LABEL_OPTIONAL type: TYPE_MESSAGE type_name: ".google.protobuf.Timestamp" json_name: "createdAt" }
+  field { name: "card_token" number: 3 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 0 json_name: "cardToken" }
+  field { name: "iban" number: 4 label: LABEL_OPTIONAL type: TYPE_STRING oneof_index: 0 json_name: "iban" }
+  field { name: "status" number: 5 label: LABEL_OPTIONAL type: TYPE_ENUM type_name: ".test.Status" json_name: "status" }
+  field { name: "labels" number: 6 label: LABEL_REPEATED type: TYPE_MESSAGE type_name: ".test.Order.LabelsEntry" json_name: "labels" }
+  field { name: "amounts" number: 7 label: LABEL_REPEATED type: TYPE_INT64 json_name: "amounts" }
+  nested_type {
+    name: "LabelsEntry"
+    field { name: "key" number: 1 label: LABEL_OPTIONAL type: TYPE_INT32 json_name: "key" }
+    field { name: "value" number: 2 label: LABEL_OPTIONAL type: TYPE_STRING json_name: "value" }
+    options { map_entry: true }
+  }
+  oneof_decl { name: "payment" }
+}
+enum_type {
+  name: "Status"
+  value { name: "STATUS_UNSPECIFIED" number: 0 }
+  value { name: "STATUS_PAID" number: 1 }
+}
+`
+
+// TestUnmarshalStructJSON 测试按描述符解码 encoding/json 形状的数据，oneof 以 Go 名称包装
+func TestUnmarshalStructJSON(t *testing.T) {
+	var fdp descriptorpb.FileDescriptorProto
+	require.NoError(t, prototext.Unmarshal([]byte(orderFile), &fdp))
+	file, err := protodesc.NewFile(&fdp, protoregistry.GlobalFiles)
+	require.NoError(t, err)
+	desc := file.Messages().ByName("Order")
+
+	msg := dynamicpb.NewMessage(desc)
+	require.NoError(t, unmarshalStructJSON([]byte(`{
+		"order_id": "o-1",
+		"created_at": {"seconds": 1700000000, "nanos": 42},
+		"Payment": {"Iban": "DE89"},
+		"status": 1,
+		"labels": {"7": "seven"},
+		"amounts": [1, 9007199254740993]
+	}`), msg))
+	data, err := protojson.Marshal(msg)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"orderId": "o-1",
+		"createdAt": "2023-11-14T22:13:20.000000042Z",
+		"iban": "DE89",
+		"status": "STATUS_PAID",
+		"labels": {"7": "seven"},
+		"amounts": ["1", "9007199254740993"]
+	}`, string(data))
+
+	for _, data := range []string{
+		`{"orderId": "o-1"}`,
+		`{"Payment": {"Iban": "DE89", "CardToken": "tok"}}`,
+		`{"Payment": {"Cash": true}}`,
+		`{"labels": {"seven": "7"}}`,
+		`{"created_at": "2023-11-14T22:13:20Z"}`,
+	} {
+		assert.Error(t, unmarshalStructJSON([]byte(data), dynamicpb.NewMessage(desc)), data)
+	}
+}
+
+// TestEvent_DecodesWithCodec 测试事件用描述符的编码解码 encoding/json 编码的数据
+func TestEvent_DecodesWithCodec(t *testing.T) {
+	desc := &Event[timestamppb.Timestamp]{Type: "test.event.created", Codec: JSON}
+	payload := timestamppb.New(time.Unix(1700000000, 42))
+	event, _, err := BuildEvent(desc, payload, []PublishOption{WithSource("test/source")})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"seconds":1700000000,"nanos":42}`, string(event.Data()))
+
+	got, err := desc.Decode(context.Background(), event)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(payload, got), "got %v", got)
+}
@@ -0,0 +1,95 @@
+package runtime
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// Envelope is a decoded event payload together with the CloudEvents attributes of the event carrying it
+type Envelope[T any] struct {
+	// ID is the event id
+	ID string
+	// Source is the event source
+	Source string
+	// Type is the event type
+	Type string
+	// Subject is the event subject
+	Subject string
+	// Time is the time the event was published
+	Time time.Time
+	// SpecVersion is the CloudEvents specification version
+	SpecVersion string
+	// DataContentType is the media type of the event data
+	DataContentType string
+	// DataSchema is the URI of the payload schema
+	DataSchema string
+	// Extensions holds a copy of the extension attributes of the event by name
+	Extensions map[string]interface{}
+	// Payload is the decoded event data
+	Payload *T
+	// Event is the received event
+	Event *cloudevents.Event
+}
+
+// NewEnvelope wraps payload decoded from event into an Envelope
+func NewEnvelope[T any](event *cloudevents.Event, payload *T) *Envelope[T] {
+	return &Envelope[T]{
+		ID:              event.ID(),
+		Source:          event.Source(),
+		Type:            event.Type(),
+		Subject:         event.Subject(),
+		Time:            event.Time(),
+		SpecVersion:     event.SpecVersion(),
+		DataContentType: event.DataContentType(),
+		DataSchema:      event.DataSchema(),
+		Extensions:      maps.Clone(event.Extensions()),
+		Payload:         payload,
+		Event:           event,
+	}
+}
+
+// Extension returns the value of the extension attribute name
+func (e *Envelope[T]) Extension(name string) (interface{}, bool) {
+	v, ok := e.Extensions[name]
+	return v, ok
+}
+
+// ExtensionString returns the extension attribute name as a string.
+// It reports false if the extension is absent or cannot be converted
+func (e *Envelope[T]) ExtensionString(name string) (string, bool) {
+	return toString(e.Extension(name))
+}
+
+// ExtensionInt returns the extension attribute name as an integer.
+// It reports false if the extension is absent or cannot be converted
+func (e *Envelope[T]) ExtensionInt(name string) (int32, bool) {
+	return toInteger(e.Extension(name))
+}
+
+// ExtensionBool returns the extension attribute name as a boolean.
+// It reports false if the extension is absent or cannot be converted
+func (e *Envelope[T]) ExtensionBool(name string) (bool, bool) {
+	return toBool(e.Extension(name))
+}
+
+// ExtensionTime returns the extension attribute name as a timestamp.
+// It reports false if the extension is absent or cannot be converted
+func (e *Envelope[T]) ExtensionTime(name string) (time.Time, bool) {
+	return toTime(e.Extension(name))
+}
+
+type eventContextKey struct{}
+
+// ContextWithEvent returns a copy of ctx carrying event
+func ContextWithEvent(ctx context.Context, event *cloudevents.Event) context.Context {
+	return context.WithValue(ctx, eventContextKey{}, event)
+}
+
+// EventFromContext returns the event being handled, available in the ctx passed to generated handlers
+func EventFromContext(ctx context.Context) (*cloudevents.Event, bool) {
+	event, ok := ctx.Value(eventContextKey{}).(*cloudevents.Event)
+	return event, ok
+}
@@ -0,0 +1,130 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// ExtensionDataVersion is the CloudEvents extension carrying the payload schema version
+const ExtensionDataVersion = "dataversion"
+
+// Event describes an event type generated from a message with the event_meta option
+type Event[T any] struct {
+	// Type is the CloudEvents type (event_type)
+	Type string
+	// Description is the event description from EventMeta
+	Description string
+	// Codec encodes published payloads, JSON if nil
+	Codec Codec
+	// Version is the current payload schema version, 0 if the event is not versioned
+	Version uint32
+	// DataSchema is the CloudEvents dataschema URI of the current version (optional)
+	DataSchema string
+	// RequiredExtensions lists the extensions every published event must set
+	RequiredExtensions []string
+
+	mu        sync.RWMutex
+	upcasters map[uint32]Upcaster[T]
+}
+
+// Upcaster converts an event carrying an older payload version into the current payload type
+type Upcaster[T any] func(ctx context.Context, event *cloudevents.Event) (*T, error)
+
+// RegisterUpcaster registers fn to convert events published with payload version from
+// Subscribers run it instead of decoding the data directly when an event of that version arrives
+func (e *Event[T]) RegisterUpcaster(from uint32, fn Upcaster[T]) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.upcasters == nil {
+		e.upcasters = make(map[uint32]Upcaster[T])
+	}
+	e.upcasters[from] = fn
+}
+
+func (e *Event[T]) upcaster(version uint32) Upcaster[T] {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.upcasters[version]
+}
+
+// Decode decodes the payload of event. Events of an older version than e.Version are converted
+// by the upcaster registered for their version; without one, or for other versions, the data
+// is decoded directly into the current type. Payloads with a generated Validate method are then validated
+func (e *Event[T]) Decode(ctx context.Context, event *cloudevents.Event) (*T, error) {
+	payload, err := e.decode(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePayload(payload); err != nil {
+		return nil, fmt.Errorf("events: invalid payload for %s: %w", e.Type, err)
+	}
+	return payload, nil
+}
+
+func (e *Event[T]) decode(ctx context.Context, event *cloudevents.Event) (*T, error) {
+	if e.Version > 0 {
+		version, err := DataVersion(event)
+		if err != nil {
+			return nil, fmt.Errorf("events: decode payload for %s: %w", e.Type, err)
+		}
+		if version < e.Version {
+			if up := e.upcaster(version); up != nil {
+				payload, err := up(ctx, event)
+				if err != nil {
+					return nil, fmt.Errorf("events: upcast %s from version %d: %w", e.Type, version, err)
+				}
+				return payload, nil
+			}
+		}
+	}
+
+	var payload T
+	if data := event.Data(); len(data) > 0 {
+		if err := decodeData(event, &payload, e.codec()); err != nil {
+			return nil, fmt.Errorf("events: decode payload for %s: %w", e.Type, err)
+		}
+	}
+	return &payload, nil
+}
+
+// codec returns the codec of published payloads
+func (e *Event[T]) codec() Codec {
+	if e.Codec == nil {
+		return JSON
+	}
+	return e.Codec
+}
+
+// DataVersion returns the payload schema version of event.
+// Events without the dataversion extension are considered version 1
+func DataVersion(event *cloudevents.Event) (uint32, error) {
+	value, ok := event.Extensions()[ExtensionDataVersion]
+	if !ok {
+		return 1, nil
+	}
+
+	var (
+		version uint64
+		err     error
+	)
+	switch v := value.(type) {
+	case int32:
+		if v < 0 {
+			return 0, fmt.Errorf("invalid %s extension %d", ExtensionDataVersion, v)
+		}
+		return uint32(v), nil
+	case string:
+		version, err = strconv.ParseUint(v, 10, 32)
+	default:
+		version, err = strconv.ParseUint(fmt.Sprint(v), 10, 32)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s extension %v", ExtensionDataVersion, value)
+	}
+	return uint32(version), nil
+}
@@ -0,0 +1,104 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildEvent_Versioned 测试版本化事件设置 dataschema 和 dataversion
+func TestBuildEvent_Versioned(t *testing.T) {
+	desc := &Event[testPayload]{
+		Type:       "test.event.created",
+		Version:    2,
+		DataSchema: "https://schemas.example.com/test.event.created/v2.json",
+	}
+
+	event, _, err := BuildEvent(desc, &testPayload{Name: "alice"}, []PublishOption{WithSource("test/source")})
+	require.NoError(t, err)
+	assert.Equal(t, desc.DataSchema, event.DataSchema())
+
+	version, err := DataVersion(event)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), version)
+
+	// 未版本化的事件不设置扩展
+	event, _, err = BuildEvent(newTestEvent(), &testPayload{}, []PublishOption{WithSource("test/source")})
+	require.NoError(t, err)
+	assert.Empty(t, event.DataSchema())
+	assert.NotContains(t, event.Extensions(), ExtensionDataVersion)
+}
+
+// TestDataVersion 测试读取 dataversion 扩展
+func TestDataVersion(t *testing.T) {
+	event := cloudevents.NewEvent()
+	version, err := DataVersion(&event)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), version, "missing extension defaults to version 1")
+
+	event.SetExtension(ExtensionDataVersion, 3)
+	version, err = DataVersion(&event)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), version)
+
+	event.SetExtension(ExtensionDataVersion, "v3")
+	_, err = DataVersion(&event)
+	assert.Error(t, err)
+
+	event.SetExtension(ExtensionDataVersion, -1)
+	_, err = DataVersion(&event)
+	assert.Error(t, err, "negative versions are invalid")
+}
+
+// TestSubscribe_Upcaster 测试订阅端对旧版本事件运行 upcaster
+func TestSubscribe_Upcaster(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeBus()
+
+	type legacyPayload struct {
+		FullName string `json:"full_name"`
+	}
+	v1 := &Event[legacyPayload]{Type: "test.event.created", Version: 1}
+	v2 := &Event[testPayload]{Type: "test.event.created", Version: 2}
+	v2.RegisterUpcaster(1, func(ctx context.Context, event *cloudevents.Event) (*testPayload, error) {
+		var old legacyPayload
+		if err := DecodeData(event, &old); err != nil {
+			return nil, err
+		}
+		return &testPayload{Name: old.FullName}, nil
+	})
+
+	var got []string
+	_, err := Subscribe(ctx, bus, v2, "test.event.created", func(ctx context.Context, p *testPayload) error {
+		got = append(got, p.Name)
+		return nil
+	})
+	require.NoError(t, err)
+
+	event, subject, err := BuildEvent(v1, &legacyPayload{FullName: "alice"}, []PublishOption{WithSource("test/source")})
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, subject, event))
+
+	event, subject, err = BuildEvent(v2, &testPayload{Name: "bob"}, []PublishOption{WithSource("test/source")})
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, subject, event))
+
+	assert.Equal(t, []string{"alice", "bob"}, got)
+}
+
+// TestEventDecode_UpcasterError 测试 upcaster 返回错误
+func TestEventDecode_UpcasterError(t *testing.T) {
+	desc := &Event[testPayload]{Type: "test.event.created", Version: 2}
+	desc.RegisterUpcaster(1, func(ctx context.Context, event *cloudevents.Event) (*testPayload, error) {
+		return nil, errors.New("boom")
+	})
+
+	event := cloudevents.NewEvent()
+	_, err := desc.Decode(context.Background(), &event)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "upcast test.event.created from version 1")
+}
@@ -0,0 +1,105 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/types"
+)
+
+// maxExtensionNameLength is the length CloudEvents extension names should not exceed
+const maxExtensionNameLength = 20
+
+// contextAttributes are the CloudEvents context attribute names extensions cannot use
+var contextAttributes = map[string]bool{
+	"specversion":     true,
+	"id":              true,
+	"source":          true,
+	"type":            true,
+	"subject":         true,
+	"time":            true,
+	"datacontenttype": true,
+	"dataschema":      true,
+	"data":            true,
+	"data_base64":     true,
+}
+
+// ValidateExtensionName checks that name is a valid CloudEvents extension name:
+// 1 to 20 lowercase ASCII letters or digits, other than a context attribute name
+func ValidateExtensionName(name string) error {
+	if name == "" || len(name) > maxExtensionNameLength {
+		return fmt.Errorf("invalid extension name %q: must be 1 to %d characters", name, maxExtensionNameLength)
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return fmt.Errorf("invalid extension name %q: must consist of lowercase letters and digits", name)
+		}
+	}
+	if contextAttributes[name] {
+		return fmt.Errorf("invalid extension name %q: reserved context attribute", name)
+	}
+	return nil
+}
+
+// ExtensionString returns the extension name of the event being handled as a string.
+// It reports false outside a generated handler, or if the extension is absent or cannot be converted
+func ExtensionString(ctx context.Context, name string) (string, bool) {
+	return toString(extensionFromContext(ctx, name))
+}
+
+// ExtensionInt returns the extension name of the event being handled as an integer
+func ExtensionInt(ctx context.Context, name string) (int32, bool) {
+	return toInteger(extensionFromContext(ctx, name))
+}
+
+// ExtensionBool returns the extension name of the event being handled as a boolean
+func ExtensionBool(ctx context.Context, name string) (bool, bool) {
+	return toBool(extensionFromContext(ctx, name))
+}
+
+// ExtensionTime returns the extension name of the event being handled as a timestamp
+func ExtensionTime(ctx context.Context, name string) (time.Time, bool) {
+	return toTime(extensionFromContext(ctx, name))
+}
+
+func extensionFromContext(ctx context.Context, name string) (interface{}, bool) {
+	event, ok := EventFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	v, ok := event.Extensions()[name]
+	return v, ok
+}
+
+func toString(v interface{}, ok bool) (string, bool) {
+	if !ok {
+		return "", false
+	}
+	s, err := types.ToString(v)
+	return s, err == nil
+}
+
+func toInteger(v interface{}, ok bool) (int32, bool) {
+	if !ok {
+		return 0, false
+	}
+	i, err := types.ToInteger(v)
+	return i, err == nil
+}
+
+func toBool(v interface{}, ok bool) (bool, bool) {
+	if !ok {
+		return false, false
+	}
+	b, err := types.ToBool(v)
+	return b, err == nil
+}
+
+func toTime(v interface{}, ok bool) (time.Time, bool) {
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := types.ToTime(v)
+	return t, err == nil
+}
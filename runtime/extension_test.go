@@ -0,0 +1,80 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateExtensionName 测试扩展名校验规则
+func TestValidateExtensionName(t *testing.T) {
+	for _, name := range []string{"traceid", "tenantid", "region2", "dataversion"} {
+		assert.NoError(t, ValidateExtensionName(name), name)
+	}
+	for _, name := range []string{"", "trace_id", "traceId", "trace-id", "averyveryverylongextension", "subject", "data"} {
+		assert.Error(t, ValidateExtensionName(name), name)
+	}
+}
+
+// TestBuildEvent_UndeclaredExtension 测试发布时不校验未声明的扩展名
+func TestBuildEvent_UndeclaredExtension(t *testing.T) {
+	event, _, err := BuildEvent(newTestEvent(), &testPayload{},
+		[]PublishOption{WithSource("test/source"), WithExtension("trace_id", "abc"), WithExtension("region", "eu")})
+	require.NoError(t, err)
+	assert.Equal(t, "eu", event.Extensions()["region"])
+}
+
+// TestBuildEvent_RequiredExtensions 测试必填扩展
+func TestBuildEvent_RequiredExtensions(t *testing.T) {
+	desc := &Event[testPayload]{Type: "test.event.created", RequiredExtensions: []string{"tenantid"}}
+
+	_, _, err := BuildEvent(desc, &testPayload{}, []PublishOption{WithSource("test/source")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "extension tenantid is required")
+
+	_, _, err = BuildEvent(desc, &testPayload{},
+		[]PublishOption{WithSource("test/source"), WithExtension("tenantid", struct{}{})})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid value for extension tenantid")
+
+	event, _, err := BuildEvent(desc, &testPayload{},
+		[]PublishOption{WithSource("test/source"), WithExtension("tenantid", "acme")})
+	require.NoError(t, err)
+	assert.Equal(t, "acme", event.Extensions()["tenantid"])
+}
+
+// TestExtensionFromContext 测试在 handler 中读取类型化扩展
+func TestExtensionFromContext(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeBus()
+	desc := newTestEvent()
+
+	var (
+		tenant   string
+		priority int32
+		urgent   bool
+		ok       [3]bool
+	)
+	_, err := Subscribe(ctx, bus, desc, "test.event.created", func(ctx context.Context, p *testPayload) error {
+		tenant, ok[0] = ExtensionString(ctx, "tenantid")
+		priority, ok[1] = ExtensionInt(ctx, "priority")
+		urgent, ok[2] = ExtensionBool(ctx, "urgent")
+		return nil
+	})
+	require.NoError(t, err)
+
+	event, subject, err := BuildEvent(desc, &testPayload{}, []PublishOption{WithSource("test/source"),
+		WithExtension("tenantid", "acme"), WithExtension("priority", 5), WithExtension("urgent", true)})
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, subject, event))
+
+	assert.Equal(t, [3]bool{true, true, true}, ok)
+	assert.Equal(t, "acme", tenant)
+	assert.Equal(t, int32(5), priority)
+	assert.True(t, urgent)
+
+	_, found := ExtensionString(ctx, "tenantid")
+	assert.False(t, found)
+}
@@ -0,0 +1,162 @@
+package runtime
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDNamespace is the UUIDv5 namespace of the ids derived by DeterministicID
+var IDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/yafeiaa/protoc-gen-cloudevents-go"))
+
+// IDGenerator returns the id of an event published at time t
+// Time-based generators embed t, so ids sort like event times
+type IDGenerator func(t time.Time) string
+
+var defaultIDGenerator atomic.Pointer[IDGenerator]
+
+// SetIDGenerator sets the IDGenerator of events published without WithID or WithIDGenerator.
+// A nil generator restores the default, UUIDv4
+func SetIDGenerator(gen IDGenerator) {
+	if gen == nil {
+		defaultIDGenerator.Store(nil)
+		return
+	}
+	defaultIDGenerator.Store(&gen)
+}
+
+func currentIDGenerator() IDGenerator {
+	if gen := defaultIDGenerator.Load(); gen != nil {
+		return *gen
+	}
+	return UUIDv4
+}
+
+// WithID sets the event id, overriding the random or payload derived id
+// Publishing the same business fact with the same id lets consumers deduplicate retried publishes
+func WithID(id string) PublishOption {
+	return func(o *publishOptions) {
+		o.id = id
+	}
+}
+
+// WithIDGenerator generates the event id with gen instead of the generator set with SetIDGenerator
+// Ids set with WithID or derived from the payload take precedence
+func WithIDGenerator(gen IDGenerator) PublishOption {
+	return func(o *publishOptions) {
+		o.idGenerator = gen
+	}
+}
+
+// DeterministicID returns the UUIDv5 identifying the event of type eventType with the given field values,
+// so that the same values always yield the same id
+func DeterministicID(eventType string, values ...interface{}) string {
+	var name strings.Builder
+	name.WriteString(eventType)
+	for _, v := range values {
+		// Length prefixes keep ("a.b", "c") and ("a", "b.c") apart
+		s := fmt.Sprint(v)
+		name.WriteString("\n" + strconv.Itoa(len(s)) + ":" + s)
+	}
+	return uuid.NewSHA1(IDNamespace, []byte(name.String())).String()
+}
+
+// UUIDv4 generates random UUIDs, the default IDGenerator
+func UUIDv4(time.Time) string {
+	return uuid.New().String()
+}
+
+// UUIDv7 generates UUIDs starting with the millisecond Unix timestamp of t (RFC 9562)
+func UUIDv7(t time.Time) string {
+	var u uuid.UUID
+	putMillis(u[:6], t)
+	randomBytes(u[6:])
+	u[6] = u[6]&0x0f | 0x70 // version 7
+	u[8] = u[8]&0x3f | 0x80 // RFC 9562 variant
+	return u.String()
+}
+
+// crockford is the Crockford base32 alphabet of ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID generates 26 character ULIDs starting with the millisecond Unix timestamp of t
+// (https://github.com/ulid/spec)
+func ULID(t time.Time) string {
+	var b [16]byte
+	putMillis(b[:6], t)
+	randomBytes(b[6:])
+
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// ksuidEpoch is the Unix time of the KSUID epoch
+const ksuidEpoch = 1400000000
+
+// base62 is the alphabet of KSUIDs
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// KSUID generates 27 character KSUIDs starting with the second timestamp of t
+// (https://github.com/segmentio/ksuid)
+func KSUID(t time.Time) string {
+	var b [20]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(t.Unix()-ksuidEpoch))
+	randomBytes(b[4:])
+	return encodeKSUID(b)
+}
+
+func encodeKSUID(b [20]byte) string {
+	var (
+		n     = new(big.Int).SetBytes(b[:])
+		radix = big.NewInt(int64(len(base62)))
+		digit = new(big.Int)
+		out   [27]byte
+	)
+	for i := len(out) - 1; i >= 0; i-- {
+		n.DivMod(n, radix, digit)
+		out[i] = base62[digit.Int64()]
+	}
+	return string(out[:])
+}
+
+// SequentialIDs returns an IDGenerator generating prefix-1, prefix-2, ..., for reproducible events in tests
+func SequentialIDs(prefix string) IDGenerator {
+	var (
+		mu   sync.Mutex
+		next int
+	)
+	return func(time.Time) string {
+		mu.Lock()
+		defer mu.Unlock()
+		next++
+		return prefix + "-" + strconv.Itoa(next)
+	}
+}
+
+// putMillis writes the 48-bit millisecond Unix timestamp of t into b
+func putMillis(b []byte, t time.Time) {
+	ms := uint64(t.UnixMilli())
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+}
+
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("events: read random bytes: %v", err))
+	}
+}
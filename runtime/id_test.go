@@ -0,0 +1,107 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeterministicID 测试相同事件类型和字段值生成相同的 UUIDv5
+func TestDeterministicID(t *testing.T) {
+	id := DeterministicID("test.event.created", "order-1", int64(2))
+	assert.Equal(t, id, DeterministicID("test.event.created", "order-1", int64(2)))
+
+	parsed, err := uuid.Parse(id)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(5), parsed.Version())
+
+	assert.NotEqual(t, id, DeterministicID("test.event.updated", "order-1", int64(2)))
+	assert.NotEqual(t, id, DeterministicID("test.event.created", "order-1", int64(3)))
+	assert.NotEqual(t, DeterministicID("test.event.created", "a\n1:b"), DeterministicID("test.event.created", "a", "b"))
+}
+
+// TestWithID 测试 WithID 覆盖随机 id
+func TestWithID(t *testing.T) {
+	opts := []PublishOption{WithSource("test/source")}
+	first, _, err := BuildEvent(newTestEvent(), &testPayload{}, opts)
+	require.NoError(t, err)
+	second, _, err := BuildEvent(newTestEvent(), &testPayload{}, opts)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID(), second.ID())
+
+	event, _, err := BuildEvent(newTestEvent(), &testPayload{}, append(opts, WithID("event-1")))
+	require.NoError(t, err)
+	assert.Equal(t, "event-1", event.ID())
+}
+
+// TestIDGenerators 测试内置 ID 生成器的格式与时间排序
+func TestIDGenerators(t *testing.T) {
+	at := time.UnixMilli(1469918176385)
+
+	v7, err := uuid.Parse(UUIDv7(at))
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), v7.Version())
+	assert.Equal(t, uuid.RFC4122, v7.Variant())
+	sec, nsec := v7.Time().UnixTime()
+	assert.Equal(t, at.UnixMilli(), time.Unix(sec, nsec).UnixMilli())
+
+	// Example of the ULID specification
+	ulid := ULID(at)
+	assert.Len(t, ulid, 26)
+	assert.Equal(t, "01ARYZ6S41", ulid[:10])
+
+	assert.Len(t, KSUID(at), 27)
+	var max [20]byte
+	for i := range max {
+		max[i] = 0xff
+	}
+	assert.Equal(t, "aWgEPTl1tmebfsQzFP4bxwgy80V", encodeKSUID(max))
+	assert.Equal(t, "000000000000000000000000000", encodeKSUID([20]byte{}))
+
+	for _, gen := range []IDGenerator{UUIDv7, ULID, KSUID} {
+		assert.Less(t, gen(at), gen(at.Add(time.Hour)))
+	}
+
+	seq := SequentialIDs("evt")
+	assert.Equal(t, "evt-1", seq(at))
+	assert.Equal(t, "evt-2", seq(at))
+}
+
+// TestBuildEvent_IDGeneratorAndClock 测试全局与单次发布的 ID 生成器和时钟
+func TestBuildEvent_IDGeneratorAndClock(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	SetIDGenerator(SequentialIDs("global"))
+	SetClock(FixedClock(at))
+	defer SetIDGenerator(nil)
+	defer SetClock(nil)
+
+	opts := []PublishOption{WithSource("test/source")}
+	event, _, err := BuildEvent(newTestEvent(), &testPayload{}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "global-1", event.ID())
+	assert.Equal(t, at, event.Time())
+
+	later := at.Add(time.Minute)
+	event, _, err = BuildEvent(newTestEvent(), &testPayload{},
+		append(opts, WithIDGenerator(SequentialIDs("call")), WithClock(FixedClock(later))))
+	require.NoError(t, err)
+	assert.Equal(t, "call-1", event.ID())
+	assert.Equal(t, later, event.Time())
+
+	event, _, err = BuildEvent(newTestEvent(), &testPayload{},
+		append(opts, WithIDGenerator(ULID), WithID("explicit")))
+	require.NoError(t, err)
+	assert.Equal(t, "explicit", event.ID())
+
+	SetIDGenerator(nil)
+	SetClock(nil)
+	event, _, err = BuildEvent(newTestEvent(), &testPayload{}, opts)
+	require.NoError(t, err)
+	parsed, err := uuid.Parse(event.ID())
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(4), parsed.Version())
+	assert.WithinDuration(t, time.Now(), event.Time(), time.Minute)
+}
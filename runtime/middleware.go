@@ -0,0 +1,156 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// PublishFunc is the function signature for publishing an event to a subject
+type PublishFunc func(ctx context.Context, subject string, event *cloudevents.Event) error
+
+// PublishMiddleware wraps the publishing of events, e.g. to log, measure or enrich published events
+// It may inspect or modify the event before calling next, and must return the error of next unless it handles it
+type PublishMiddleware func(next PublishFunc) PublishFunc
+
+// HandlerMiddleware wraps the handling of events, e.g. to log, measure, authorize or recover from panics
+// It receives the raw CloudEvent before its payload is decoded and may skip next to drop the event
+type HandlerMiddleware func(next EventHandler) EventHandler
+
+// ChainPublish composes middlewares into one, the first middleware being the outermost
+func ChainPublish(middlewares ...PublishMiddleware) PublishMiddleware {
+	return func(next PublishFunc) PublishFunc {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			if middlewares[i] != nil {
+				next = middlewares[i](next)
+			}
+		}
+		return next
+	}
+}
+
+// ChainHandler composes middlewares into one, the first middleware being the outermost
+func ChainHandler(middlewares ...HandlerMiddleware) HandlerMiddleware {
+	return func(next EventHandler) EventHandler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			if middlewares[i] != nil {
+				next = middlewares[i](next)
+			}
+		}
+		return next
+	}
+}
+
+// Recover is a HandlerMiddleware turning panics of the handler into errors
+func Recover() HandlerMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, event *cloudevents.Event) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("events: panic handling %s event %s: %v", event.Type(), event.ID(), r)
+				}
+			}()
+			return next(ctx, event)
+		}
+	}
+}
+
+// Bus is the interface implemented by the transports, for publishing and subscribing in both modes
+type Bus interface {
+	Publisher
+	Subscriber
+	HandlerGroupSubscriber
+}
+
+var (
+	_ Bus       = (*MiddlewareBus)(nil)
+	_ Requester = (*MiddlewareBus)(nil)
+	_ Responder = (*MiddlewareBus)(nil)
+)
+
+// MiddlewareBus wraps a Bus, passing the events published through it to the publish middlewares
+// and the events delivered to its subscribers to the handler middlewares
+// Request events pass through the publish middlewares and requests answered by responders through
+// the handler middlewares. Middlewares must be added before the bus is used
+type MiddlewareBus struct {
+	bus      Bus
+	publish  []PublishMiddleware
+	handlers []HandlerMiddleware
+}
+
+// NewMiddlewareBus wraps bus without middlewares
+// Example: runtime.NewMiddlewareBus(bus).UseHandler(runtime.Recover(), logEvents)
+func NewMiddlewareBus(bus Bus) *MiddlewareBus {
+	return &MiddlewareBus{bus: bus}
+}
+
+// UsePublish appends middlewares to the publish middlewares of b and returns b
+func (b *MiddlewareBus) UsePublish(middlewares ...PublishMiddleware) *MiddlewareBus {
+	b.publish = append(b.publish, middlewares...)
+	return b
+}
+
+// UseHandler appends middlewares to the handler middlewares of b and returns b
+func (b *MiddlewareBus) UseHandler(middlewares ...HandlerMiddleware) *MiddlewareBus {
+	b.handlers = append(b.handlers, middlewares...)
+	return b
+}
+
+// Publish publishes event through the publish middlewares
+func (b *MiddlewareBus) Publish(ctx context.Context, subject string, event *cloudevents.Event) error {
+	return ChainPublish(b.publish...)(b.bus.Publish)(ctx, subject, event)
+}
+
+// Subscribe subscribes handler wrapped with the handler middlewares (broadcast mode)
+func (b *MiddlewareBus) Subscribe(ctx context.Context, subject string, handler EventHandler) (Subscription, error) {
+	return b.bus.Subscribe(ctx, subject, ChainHandler(b.handlers...)(handler))
+}
+
+// SubscribeWithHandlerGroup subscribes handler wrapped with the handler middlewares (handler group mode)
+func (b *MiddlewareBus) SubscribeWithHandlerGroup(ctx context.Context, subject, group string,
+	handler EventHandler) (Subscription, error) {
+	return b.bus.SubscribeWithHandlerGroup(ctx, subject, group, ChainHandler(b.handlers...)(handler))
+}
+
+// Request sends event through the publish middlewares and waits for its reply
+// It fails if the wrapped bus is not a Requester
+func (b *MiddlewareBus) Request(ctx context.Context, subject string, event *cloudevents.Event) (*cloudevents.Event, error) {
+	requester, ok := b.bus.(Requester)
+	if !ok {
+		return nil, errors.New("events: bus does not support request/reply")
+	}
+
+	var reply *cloudevents.Event
+	err := ChainPublish(b.publish...)(func(ctx context.Context, subject string, event *cloudevents.Event) error {
+		var err error
+		reply, err = requester.Request(ctx, subject, event)
+		return err
+	})(ctx, subject, event)
+	return reply, err
+}
+
+// Respond registers handler wrapped with the handler middlewares to answer requests sent to subject
+// It fails if the wrapped bus is not a Responder
+func (b *MiddlewareBus) Respond(ctx context.Context, subject, group string,
+	handler RequestHandler) (Subscription, error) {
+	responder, ok := b.bus.(Responder)
+	if !ok {
+		return nil, errors.New("events: bus does not support request/reply")
+	}
+	if handler == nil {
+		return nil, errors.New("events: handler is required")
+	}
+
+	middleware := ChainHandler(b.handlers...)
+	return responder.Respond(ctx, subject, group, func(ctx context.Context, event *cloudevents.Event) (*cloudevents.Event, error) {
+		var reply *cloudevents.Event
+		err := middleware(func(ctx context.Context, event *cloudevents.Event) error {
+			var err error
+			reply, err = handler(ctx, event)
+			return err
+		})(ctx, event)
+		return reply, err
+	})
+}
@@ -0,0 +1,95 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tracePublish 记录发布中间件的调用顺序
+func tracePublish(calls *[]string, name string) PublishMiddleware {
+	return func(next PublishFunc) PublishFunc {
+		return func(ctx context.Context, subject string, event *cloudevents.Event) error {
+			*calls = append(*calls, name)
+			return next(ctx, subject, event)
+		}
+	}
+}
+
+// traceHandler 记录处理中间件的调用顺序
+func traceHandler(calls *[]string, name string) HandlerMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, event *cloudevents.Event) error {
+			*calls = append(*calls, name)
+			return next(ctx, event)
+		}
+	}
+}
+
+// TestMiddlewareBus 测试总线包装器按顺序执行发布与处理中间件
+func TestMiddlewareBus(t *testing.T) {
+	ctx := context.Background()
+	var calls []string
+	bus := NewMiddlewareBus(newFakeBus()).
+		UsePublish(tracePublish(&calls, "publish-1"), nil, tracePublish(&calls, "publish-2")).
+		UseHandler(traceHandler(&calls, "bus-1"), traceHandler(&calls, "bus-2"))
+
+	_, err := Subscribe(ctx, bus, newTestEvent(), "test.event.created",
+		func(ctx context.Context, p *testPayload) error {
+			calls = append(calls, "handler:"+p.Name)
+			return nil
+		}, WithHandlerMiddleware(traceHandler(&calls, "subscription")))
+	require.NoError(t, err)
+
+	event, subject, err := BuildEvent(newTestEvent(), &testPayload{Name: "alice"},
+		[]PublishOption{WithSource("test/source")})
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, subject, event))
+	assert.Equal(t, []string{"publish-1", "publish-2", "bus-1", "bus-2", "subscription", "handler:alice"}, calls)
+
+	// 共享 subject 上其他类型的事件不会进入订阅中间件
+	calls = nil
+	reply, _, err := BuildEvent(newTestReplyEvent(), &testReply{}, []PublishOption{WithSource("test/source")})
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, subject, reply))
+	assert.Equal(t, []string{"publish-1", "publish-2", "bus-1", "bus-2"}, calls)
+}
+
+// TestMiddlewareBus_RequestReply 测试请求/响应经过中间件
+func TestMiddlewareBus_RequestReply(t *testing.T) {
+	ctx := context.Background()
+	var calls []string
+	bus := NewMiddlewareBus(newFakeBus()).
+		UsePublish(tracePublish(&calls, "publish")).
+		UseHandler(traceHandler(&calls, "handler"))
+
+	_, err := Respond(ctx, bus, newTestEvent(), newTestReplyEvent(), "test.event.created", "",
+		func(ctx context.Context, p *testPayload) (*testReply, error) {
+			return &testReply{Greeting: "hello " + p.Name}, nil
+		}, nil)
+	require.NoError(t, err)
+
+	out, err := Request(ctx, bus, newTestEvent(), newTestReplyEvent(), &testPayload{Name: "bob"},
+		[]PublishOption{WithSource("test/source")})
+	require.NoError(t, err)
+	assert.Equal(t, "hello bob", out.Greeting)
+	assert.Equal(t, []string{"publish", "handler"}, calls)
+}
+
+// TestRecover 测试 Recover 将 handler panic 转换为错误
+func TestRecover(t *testing.T) {
+	handler := Recover()(func(ctx context.Context, event *cloudevents.Event) error {
+		panic("boom")
+	})
+
+	event := cloudevents.NewEvent()
+	event.SetID("event-1")
+	event.SetType("test.event.created")
+	err := handler(context.Background(), &event)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+	assert.Contains(t, err.Error(), "event-1")
+}
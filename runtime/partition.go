@@ -0,0 +1,48 @@
+package runtime
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// ExtensionPartitionKey is the CloudEvents extension carrying the partition key of an event,
+// see https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/partitioning.md
+const ExtensionPartitionKey = "partitionkey"
+
+// WithPartitionKey sets the partition key of the event, overriding the key derived from the payload
+// Transports honouring ordering deliver events with the same key to the same handler group member
+// An empty key publishes the event without partition key
+func WithPartitionKey(key string) PublishOption {
+	return func(o *publishOptions) {
+		if key == "" {
+			delete(o.extensions, ExtensionPartitionKey)
+			return
+		}
+		o.extensions[ExtensionPartitionKey] = key
+	}
+}
+
+// PartitionKeyValue formats a payload field value as a partition key
+func PartitionKeyValue(v interface{}) string {
+	return fmt.Sprint(v)
+}
+
+// PartitionKey returns the partition key of event. It reports false if the event has no partition key
+func PartitionKey(event *cloudevents.Event) (string, bool) {
+	v, ok := event.Extensions()[ExtensionPartitionKey]
+	key, ok := toString(v, ok)
+	if !ok || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+// PartitionIndex returns the member out of n handler group members that receives the events with key,
+// so that transports route same-key events consistently
+func PartitionIndex(key string, n int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
@@ -0,0 +1,37 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithPartitionKey 测试设置、覆盖与清除分区键
+func TestWithPartitionKey(t *testing.T) {
+	event, _, err := BuildEvent(newTestEvent(), &testPayload{},
+		[]PublishOption{WithSource("test/source"), WithPartitionKey("order-1")})
+	require.NoError(t, err)
+	key, ok := PartitionKey(event)
+	assert.True(t, ok)
+	assert.Equal(t, "order-1", key)
+
+	event, _, err = BuildEvent(newTestEvent(), &testPayload{},
+		[]PublishOption{WithSource("test/source"), WithPartitionKey("order-1"), WithPartitionKey("")})
+	require.NoError(t, err)
+	_, ok = PartitionKey(event)
+	assert.False(t, ok)
+	assert.NotContains(t, event.Extensions(), ExtensionPartitionKey)
+}
+
+// TestPartitionIndex 测试分区键到组成员的映射稳定且在范围内
+func TestPartitionIndex(t *testing.T) {
+	assert.Equal(t, "42", PartitionKeyValue(int64(42)))
+	for _, key := range []string{"", "a", "order-1", "order-2"} {
+		index := PartitionIndex(key, 3)
+		assert.GreaterOrEqual(t, index, 0)
+		assert.Less(t, index, 3)
+		assert.Equal(t, index, PartitionIndex(key, 3))
+	}
+	assert.Equal(t, 0, PartitionIndex("a", 1))
+}
@@ -0,0 +1,126 @@
+package runtime
+
+import (
+	"fmt"
+	"strconv"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// PublishOption is a functional option for publishing events
+type PublishOption func(*publishOptions)
+
+type publishOptions struct {
+	id          string
+	idGenerator IDGenerator
+	clock       Clock
+	source      string
+	subject     string
+	extensions  map[string]interface{}
+}
+
+// WithSource sets the event source (required)
+// Examples: "myapp/api-server", "myapp/controller/pod-abc"
+func WithSource(source string) PublishOption {
+	return func(o *publishOptions) {
+		o.source = source
+	}
+}
+
+// WithSubject overrides the NATS subject (optional)
+// Defaults to event_type if not specified
+func WithSubject(subject string) PublishOption {
+	return func(o *publishOptions) {
+		o.subject = subject
+	}
+}
+
+// WithExtension adds CloudEvents extension attributes
+func WithExtension(key string, value interface{}) PublishOption {
+	return func(o *publishOptions) {
+		if key != "" {
+			o.extensions[key] = value
+		}
+	}
+}
+
+// BuildEvent builds a CloudEvent of type desc carrying payload encoded with desc.Codec,
+// and returns it together with the subject it should be published to
+func BuildEvent[T any](desc *Event[T], payload *T, opts []PublishOption) (*cloudevents.Event, string, error) {
+	eventType := desc.Type
+	options := &publishOptions{
+		extensions: make(map[string]interface{}),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+
+	if options.source == "" {
+		return nil, "", fmt.Errorf("events: source is required, use WithSource() option")
+	}
+
+	subject := options.subject
+	if subject == "" {
+		subject = eventType
+	}
+
+	ce := cloudevents.NewEvent()
+	clock := options.clock
+	if clock == nil {
+		clock = currentClock()
+	}
+	now := clock()
+	id := options.id
+	if id == "" {
+		gen := options.idGenerator
+		if gen == nil {
+			gen = currentIDGenerator()
+		}
+		id = gen(now)
+	}
+
+	ce.SetID(id)
+	ce.SetSpecVersion(cloudevents.VersionV1)
+	ce.SetTime(now)
+	ce.SetType(eventType)
+	ce.SetSource(options.source)
+	ce.SetSubject(subject)
+	if desc.DataSchema != "" {
+		ce.SetDataSchema(desc.DataSchema)
+	}
+	if desc.Version > 0 {
+		ce.SetExtension(ExtensionDataVersion, strconv.FormatUint(uint64(desc.Version), 10))
+	}
+
+	for k, v := range options.extensions {
+		ce.SetExtension(k, v)
+	}
+	// Required extensions are declared in proto, so their names are known to be valid and only values are checked
+	for _, name := range desc.RequiredExtensions {
+		v, ok := options.extensions[name]
+		if !ok {
+			return nil, "", fmt.Errorf("events: extension %s is required for type %s", name, eventType)
+		}
+		if err := ce.Context.SetExtension(name, v); err != nil {
+			return nil, "", fmt.Errorf("events: invalid value for extension %s: %w", name, err)
+		}
+	}
+
+	if payload != nil {
+		if err := validatePayload(payload); err != nil {
+			return nil, "", fmt.Errorf("events: invalid payload for %s: %w", eventType, err)
+		}
+		codec := desc.codec()
+		data, err := codec.Marshal(payload)
+		if err != nil {
+			return nil, "", fmt.Errorf("events: encode data for type %s: %w", eventType, err)
+		}
+		if err := ce.SetData(codec.ContentType(), data); err != nil {
+			return nil, "", fmt.Errorf("events: set data for type %s: %w", eventType, err)
+		}
+	}
+
+	return &ce, subject, nil
+}
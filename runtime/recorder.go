@@ -0,0 +1,137 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+var _ Publisher = (*Recorder)(nil)
+
+// Recorder is a Publisher recording published events instead of delivering them, for use in tests
+type Recorder struct {
+	mu     sync.Mutex
+	events []*RecordedEvent
+}
+
+// RecordedEvent is an event published to a Recorder
+type RecordedEvent struct {
+	// Subject is the subject the event was published to
+	Subject string
+	// Event is a copy of the published event
+	Event *cloudevents.Event
+}
+
+// NewRecorder creates an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Publish records a copy of event
+func (r *Recorder) Publish(ctx context.Context, subject string, event *cloudevents.Event) error {
+	if event == nil {
+		return errors.New("events: event is required")
+	}
+
+	clone := event.Clone()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, &RecordedEvent{Subject: subject, Event: &clone})
+	return nil
+}
+
+// Events returns the recorded events in publish order
+func (r *Recorder) Events() []*RecordedEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*RecordedEvent(nil), r.events...)
+}
+
+// Reset discards the recorded events
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = nil
+}
+
+// Recorded decodes the recorded events of type desc with desc.Decode, in publish order
+func Recorded[T any](ctx context.Context, r *Recorder, desc *Event[T]) ([]*Envelope[T], error) {
+	var envelopes []*Envelope[T]
+	for _, recorded := range r.Events() {
+		if recorded.Event.Type() != desc.Type {
+			continue
+		}
+		payload, err := desc.Decode(ContextWithEvent(ctx, recorded.Event), recorded.Event)
+		if err != nil {
+			return nil, err
+		}
+		envelopes = append(envelopes, NewEnvelope(recorded.Event, payload))
+	}
+	return envelopes, nil
+}
+
+// TestingT is the part of testing.TB the recorder assertions report failures to
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// RecordedEnvelopes is like Recorded but fails t if a recorded event cannot be decoded
+func RecordedEnvelopes[T any](t TestingT, r *Recorder, desc *Event[T]) []*Envelope[T] {
+	t.Helper()
+	envelopes, err := Recorded(context.Background(), r, desc)
+	if err != nil {
+		t.Fatalf("decode recorded %s events: %v", desc.Type, err)
+	}
+	return envelopes
+}
+
+// RecordedPayloads returns the payloads of the recorded events of type desc, in publish order.
+// It fails t if a recorded event cannot be decoded
+func RecordedPayloads[T any](t TestingT, r *Recorder, desc *Event[T]) []*T {
+	t.Helper()
+	envelopes := RecordedEnvelopes(t, r, desc)
+	payloads := make([]*T, len(envelopes))
+	for i, envelope := range envelopes {
+		payloads[i] = envelope.Payload
+	}
+	return payloads
+}
+
+// AssertPublished fails t unless an event of type desc whose payload satisfies all of match was recorded,
+// and returns the first such payload
+func AssertPublished[T any](t TestingT, r *Recorder, desc *Event[T], match ...func(*T) bool) *T {
+	t.Helper()
+	payloads := RecordedPayloads(t, r, desc)
+	for _, payload := range payloads {
+		if matches(payload, match) {
+			return payload
+		}
+	}
+	t.Fatalf("no matching %s event published among %d recorded", desc.Type, len(payloads))
+	return nil
+}
+
+// AssertNotPublished fails t if an event of type desc whose payload satisfies all of match was recorded
+func AssertNotPublished[T any](t TestingT, r *Recorder, desc *Event[T], match ...func(*T) bool) {
+	t.Helper()
+	for _, payload := range RecordedPayloads(t, r, desc) {
+		if matches(payload, match) {
+			t.Errorf("unexpected %s event published: %v", desc.Type, payload)
+			return
+		}
+	}
+}
+
+// matches reports whether payload satisfies all of match
+func matches[T any](payload *T, match []func(*T) bool) bool {
+	for _, m := range match {
+		if !m(payload) {
+			return false
+		}
+	}
+	return true
+}
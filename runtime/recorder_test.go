@@ -0,0 +1,102 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecorder 测试记录器按发布顺序记录事件并按类型解码
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	rec := NewRecorder()
+	opts := []PublishOption{WithSource("test/source")}
+
+	publish := func(event *cloudevents.Event, subject string, err error) {
+		require.NoError(t, err)
+		require.NoError(t, rec.Publish(ctx, subject, event))
+	}
+	publish(BuildEvent(newTestEvent(), &testPayload{Name: "alice"}, opts))
+	publish(BuildEvent(newTestReplyEvent(), &testReply{Greeting: "hi"}, opts))
+	publish(BuildEvent(newTestEvent(), &testPayload{Name: "bob"}, append(opts, WithSubject("custom.subject"))))
+
+	events := rec.Events()
+	require.Len(t, events, 3)
+	assert.Equal(t, "test.event.created", events[0].Subject)
+	assert.Equal(t, "custom.subject", events[2].Subject)
+
+	envelopes, err := Recorded(ctx, rec, newTestEvent())
+	require.NoError(t, err)
+	require.Len(t, envelopes, 2)
+	assert.Equal(t, "alice", envelopes[0].Payload.Name)
+	assert.Equal(t, "bob", envelopes[1].Payload.Name)
+	assert.Equal(t, "test/source", envelopes[1].Source)
+
+	rec.Reset()
+	assert.Empty(t, rec.Events())
+	assert.Error(t, rec.Publish(ctx, "test.event.created", nil))
+}
+
+// fakeT 记录断言报告的失败，Fatalf 不终止测试
+type fakeT struct {
+	errors []string
+	fatals []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Fatalf(format string, args ...any) {
+	t.fatals = append(t.fatals, fmt.Sprintf(format, args...))
+}
+
+// TestRecorderAssertions 测试按事件描述符读取与断言记录的事件
+func TestRecorderAssertions(t *testing.T) {
+	ctx := context.Background()
+	rec := NewRecorder()
+	desc := newTestEvent()
+	for _, name := range []string{"alice", "bob"} {
+		event, subject, err := BuildEvent(desc, &testPayload{Name: name}, []PublishOption{WithSource("test/source")})
+		require.NoError(t, err)
+		require.NoError(t, rec.Publish(ctx, subject, event))
+	}
+	isBob := func(p *testPayload) bool { return p.Name == "bob" }
+	isCarol := func(p *testPayload) bool { return p.Name == "carol" }
+
+	ft := &fakeT{}
+	payloads := RecordedPayloads(ft, rec, desc)
+	require.Len(t, payloads, 2)
+	assert.Equal(t, "alice", payloads[0].Name)
+	assert.Len(t, RecordedEnvelopes(ft, rec, desc), 2)
+	assert.Equal(t, "bob", AssertPublished(ft, rec, desc, isBob).Name)
+	assert.Equal(t, "alice", AssertPublished(ft, rec, desc).Name)
+	AssertNotPublished(ft, rec, desc, isCarol)
+	AssertNotPublished(ft, rec, newTestReplyEvent())
+	assert.Empty(t, ft.errors)
+	assert.Empty(t, ft.fatals)
+
+	assert.Nil(t, AssertPublished(ft, rec, desc, isBob, isCarol))
+	assert.Equal(t, []string{"no matching test.event.created event published among 2 recorded"}, ft.fatals)
+	AssertNotPublished(ft, rec, desc, isBob)
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "unexpected test.event.created event published")
+
+	// 无法解码的事件使断言失败
+	ft = &fakeT{}
+	bad := cloudevents.NewEvent()
+	bad.SetID("1")
+	bad.SetSource("test/source")
+	bad.SetType(desc.Type)
+	require.NoError(t, bad.SetData(cloudevents.ApplicationJSON, []byte(`{"name": 1}`)))
+	require.NoError(t, rec.Publish(ctx, desc.Type, &bad))
+	RecordedPayloads(ft, rec, desc)
+	require.Len(t, ft.fatals, 1)
+	assert.Contains(t, ft.fatals[0], "decode recorded test.event.created events")
+}
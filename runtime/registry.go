@@ -0,0 +1,144 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrUnknownEventType is returned when decoding an event whose type is not registered
+var ErrUnknownEventType = errors.New("events: unknown event type")
+
+// EventInfo describes a registered event type
+type EventInfo struct {
+	// Type is the CloudEvents type (event_type)
+	Type string
+	// Description is the event description from EventMeta
+	Description string
+	// FullName is the full name of the payload proto message
+	FullName protoreflect.FullName
+	// ContentType is the datacontenttype of published events
+	ContentType string
+	// Version is the current payload schema version, 0 if the event is unversioned
+	Version uint32
+	// DataSchema is the CloudEvents dataschema URI of the current version
+	DataSchema string
+
+	newMessage func() proto.Message
+	decode     func(context.Context, *cloudevents.Event) (proto.Message, error)
+}
+
+// New returns a new empty payload message of the event
+func (i *EventInfo) New() proto.Message {
+	return i.newMessage()
+}
+
+// Registry maps CloudEvents types to the generated events that carry them
+type Registry struct {
+	mu     sync.RWMutex
+	events map[string]*EventInfo
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{events: make(map[string]*EventInfo)}
+}
+
+// DefaultRegistry is the registry generated code registers its events into
+var DefaultRegistry = NewRegistry()
+
+// RegisterEvent registers desc into r.
+// Registering the same payload message for the same type again is a no-op, e.g. when two Go packages generated
+// from the same proto are linked into one binary; it fails if another message is registered for the type
+func RegisterEvent[T any, P interface {
+	*T
+	proto.Message
+}](r *Registry, desc *Event[T]) error {
+	info := &EventInfo{
+		Type:        desc.Type,
+		Description: desc.Description,
+		FullName:    P(new(T)).ProtoReflect().Descriptor().FullName(),
+		ContentType: desc.codec().ContentType(),
+		Version:     desc.Version,
+		DataSchema:  desc.DataSchema,
+		newMessage:  func() proto.Message { return P(new(T)) },
+		decode: func(ctx context.Context, event *cloudevents.Event) (proto.Message, error) {
+			payload, err := desc.Decode(ctx, event)
+			if err != nil {
+				return nil, err
+			}
+			return P(payload), nil
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if other, ok := r.events[info.Type]; ok {
+		if other.FullName == info.FullName {
+			return nil
+		}
+		return fmt.Errorf("events: event type %s already registered by %s", info.Type, other.FullName)
+	}
+	r.events[info.Type] = info
+	return nil
+}
+
+// MustRegisterEvent registers desc into DefaultRegistry and panics if registration fails.
+// Generated code calls it from init
+func MustRegisterEvent[T any, P interface {
+	*T
+	proto.Message
+}](desc *Event[T]) {
+	if err := RegisterEvent[T, P](DefaultRegistry, desc); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the event registered for eventType
+func (r *Registry) Lookup(eventType string) (*EventInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info, ok := r.events[eventType]
+	return info, ok
+}
+
+// Events returns all registered events sorted by type
+func (r *Registry) Events() []*EventInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]*EventInfo, 0, len(r.events))
+	for _, info := range r.events {
+		events = append(events, info)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Type < events[j].Type })
+	return events
+}
+
+// Decode decodes the payload of event into the proto message registered for its type,
+// running upcasters registered for older versions
+func (r *Registry) Decode(event *cloudevents.Event) (proto.Message, error) {
+	return r.DecodeContext(context.Background(), event)
+}
+
+// DecodeContext is like Decode but passes ctx to upcasters
+func (r *Registry) DecodeContext(ctx context.Context, event *cloudevents.Event) (proto.Message, error) {
+	info, ok := r.Lookup(event.Type())
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownEventType, event.Type())
+	}
+	return info.decode(ctx, event)
+}
+
+// Decode decodes the payload of event using DefaultRegistry
+func Decode(event *cloudevents.Event) (proto.Message, error) {
+	return DefaultRegistry.Decode(event)
+}
@@ -0,0 +1,93 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// TestRegistry_Decode 测试按事件类型动态解码
+func TestRegistry_Decode(t *testing.T) {
+	r := NewRegistry()
+	desc := &Event[descriptorpb.EnumValueDescriptorProto]{
+		Type:        "test.enum.created",
+		Description: "Enum value created",
+		Codec:       Protobuf,
+	}
+	require.NoError(t, RegisterEvent(r, desc))
+	require.NoError(t, RegisterEvent(r, &Event[wrapperspb.StringValue]{Type: "test.string.created", Codec: ProtoJSON}))
+
+	info, ok := r.Lookup("test.enum.created")
+	require.True(t, ok)
+	assert.Equal(t, "Enum value created", info.Description)
+	assert.Equal(t, "google.protobuf.EnumValueDescriptorProto", string(info.FullName))
+	assert.Equal(t, ApplicationProtobuf, info.ContentType)
+	assert.IsType(t, &descriptorpb.EnumValueDescriptorProto{}, info.New())
+
+	events := r.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, "test.enum.created", events[0].Type)
+	assert.Equal(t, "test.string.created", events[1].Type)
+
+	payload := &descriptorpb.EnumValueDescriptorProto{Name: proto.String("ACTIVE"), Number: proto.Int32(7)}
+	event, _, err := BuildEvent(desc, payload, []PublishOption{WithSource("test/source")})
+	require.NoError(t, err)
+
+	got, err := r.Decode(event)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(payload, got))
+}
+
+// TestRegistry_DecodeUpcasts 测试动态解码时运行 upcaster
+func TestRegistry_DecodeUpcasts(t *testing.T) {
+	r := NewRegistry()
+	desc := &Event[wrapperspb.StringValue]{Type: "test.string.created", Codec: ProtoJSON, Version: 2}
+	desc.RegisterUpcaster(1, func(ctx context.Context, event *cloudevents.Event) (*wrapperspb.StringValue, error) {
+		return wrapperspb.String("upcasted"), nil
+	})
+	require.NoError(t, RegisterEvent(r, desc))
+
+	event := cloudevents.NewEvent()
+	event.SetType("test.string.created")
+
+	got, err := r.Decode(&event)
+	require.NoError(t, err)
+	assert.Equal(t, "upcasted", got.(*wrapperspb.StringValue).GetValue())
+}
+
+// TestRegistry_Errors 测试重复注册和未知类型
+func TestRegistry_Errors(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, RegisterEvent(r, &Event[wrapperspb.StringValue]{Type: "test.string.created"}))
+
+	// 同一消息以相同类型重复注册是空操作，保留首次注册的事件
+	first, ok := r.Lookup("test.string.created")
+	require.True(t, ok)
+	require.NoError(t, RegisterEvent(r, &Event[wrapperspb.StringValue]{Type: "test.string.created", Description: "again"}))
+	info, ok := r.Lookup("test.string.created")
+	require.True(t, ok)
+	assert.Same(t, first, info)
+	assert.NotPanics(t, func() {
+		MustRegisterEvent(&Event[wrapperspb.StringValue]{Type: "test.registry.must_registered"})
+		MustRegisterEvent(&Event[wrapperspb.StringValue]{Type: "test.registry.must_registered"})
+	})
+	assert.Panics(t, func() {
+		MustRegisterEvent(&Event[wrapperspb.BytesValue]{Type: "test.registry.must_registered"})
+	})
+
+	err := RegisterEvent(r, &Event[wrapperspb.BytesValue]{Type: "test.string.created"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already registered by google.protobuf.StringValue")
+
+	event := cloudevents.NewEvent()
+	event.SetType("test.unknown.created")
+	_, err = r.Decode(&event)
+	assert.True(t, errors.Is(err, ErrUnknownEventType))
+}
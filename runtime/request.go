@@ -0,0 +1,99 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// ExtensionReplyError is the CloudEvents extension of a reply carrying the error returned by the responder
+const ExtensionReplyError = "replyerror"
+
+// ErrNoResponders is returned by Requester implementations when no responder listens on the request subject
+var ErrNoResponders = errors.New("events: no responders")
+
+// ReplyError is the error returned by Request when the responder failed to handle the request
+type ReplyError struct {
+	// Type is the CloudEvents type of the request
+	Type string
+	// Message is the error message reported by the responder
+	Message string
+}
+
+func (e *ReplyError) Error() string {
+	return fmt.Sprintf("events: %s request failed: %s", e.Type, e.Message)
+}
+
+// Request sends payload as an event of type desc and waits for the reply of type reply,
+// returning its decoded payload. Errors reported by the responder are returned as *ReplyError
+func Request[Req, Rep any](ctx context.Context, bus Requester, desc *Event[Req], reply *Event[Rep],
+	payload *Req, opts []PublishOption) (*Rep, error) {
+	event, subject, err := BuildEvent(desc, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := bus.Request(ctx, subject, event)
+	if err != nil {
+		return nil, fmt.Errorf("events: request %s: %w", desc.Type, err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("events: request %s: empty reply", desc.Type)
+	}
+	if v, ok := resp.Extensions()[ExtensionReplyError]; ok {
+		message, _ := toString(v, true)
+		return nil, &ReplyError{Type: desc.Type, Message: message}
+	}
+	if resp.Type() != reply.Type {
+		return nil, fmt.Errorf("events: request %s: unexpected reply type %s, want %s", desc.Type, resp.Type(), reply.Type)
+	}
+	return reply.Decode(ContextWithEvent(ctx, resp), resp)
+}
+
+// Respond answers the requests of type desc sent to subject with the replies of type reply returned by handler.
+// opts apply to every reply; the reply source defaults to the request source.
+// Errors of handler are sent back to the requester, which receives them as *ReplyError.
+// The responder stops when the returned subscription is unsubscribed or ctx is done
+func Respond[Req, Rep any](ctx context.Context, bus Responder, desc *Event[Req], reply *Event[Rep],
+	subject, group string, handler func(context.Context, *Req) (*Rep, error), opts []PublishOption) (Subscription, error) {
+	if handler == nil {
+		return nil, errors.New("events: handler is required")
+	}
+
+	return bus.Respond(ctx, subject, group, func(eventCtx context.Context, event *cloudevents.Event) (*cloudevents.Event, error) {
+		replyOpts := append([]PublishOption{WithSource(event.Source())}, opts...)
+
+		var resp *cloudevents.Event
+		out, err := handleRequest(eventCtx, desc, event, handler)
+		if err == nil {
+			if resp, _, err = BuildEvent(reply, out, replyOpts); err == nil {
+				return resp, nil
+			}
+		}
+
+		// Error replies carry no data, so they are built without the codec and required extensions of reply
+		resp, _, err = BuildEvent(&Event[Rep]{Type: reply.Type}, nil,
+			append(replyOpts, WithExtension(ExtensionReplyError, err.Error())))
+		return resp, err
+	})
+}
+
+// handleRequest decodes the payload of a request event and passes it to handler
+func handleRequest[Req, Rep any](ctx context.Context, desc *Event[Req], event *cloudevents.Event,
+	handler func(context.Context, *Req) (*Rep, error)) (*Rep, error) {
+	ctx = ContextWithEvent(ctx, event)
+	payload, err := desc.Decode(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	out, err := handler(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return nil, errors.New("events: reply payload is required")
+	}
+	return out, nil
+}
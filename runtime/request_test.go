@@ -0,0 +1,78 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testReply struct {
+	Greeting string `json:"greeting"`
+}
+
+func newTestReplyEvent() *Event[testReply] {
+	return &Event[testReply]{Type: "test.event.replied", Codec: JSON}
+}
+
+// TestRequestRespond 测试请求/响应往返
+func TestRequestRespond(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeBus()
+
+	_, err := Respond(ctx, bus, newTestEvent(), newTestReplyEvent(), "test.event.created", "greeters",
+		func(ctx context.Context, payload *testPayload) (*testReply, error) {
+			event, ok := EventFromContext(ctx)
+			require.True(t, ok)
+			assert.Equal(t, "test/client", event.Source())
+			return &testReply{Greeting: "hello " + payload.Name}, nil
+		}, []PublishOption{WithExtension("region", "eu")})
+	require.NoError(t, err)
+	assert.Equal(t, "greeters", bus.groups["test.event.created"])
+
+	reply, err := Request(ctx, bus, newTestEvent(), newTestReplyEvent(), &testPayload{Name: "alice"},
+		[]PublishOption{WithSource("test/client")})
+	require.NoError(t, err)
+	assert.Equal(t, "hello alice", reply.Greeting)
+}
+
+// TestRequestRespond_Errors 测试响应方错误、空响应与无响应方
+func TestRequestRespond_Errors(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeBus()
+	opts := []PublishOption{WithSource("test/client")}
+
+	_, err := Request(ctx, bus, newTestEvent(), newTestReplyEvent(), &testPayload{}, opts)
+	assert.ErrorIs(t, err, ErrNoResponders)
+
+	_, err = Respond(ctx, bus, newTestEvent(), newTestReplyEvent(), "test.event.created", "",
+		func(ctx context.Context, payload *testPayload) (*testReply, error) {
+			switch payload.Name {
+			case "":
+				return nil, nil
+			case "bob":
+				return nil, errors.New("unknown user")
+			}
+			return &testReply{Greeting: "hello " + payload.Name}, nil
+		}, nil)
+	require.NoError(t, err)
+
+	_, err = Request(ctx, bus, newTestEvent(), newTestReplyEvent(), &testPayload{Name: "bob"}, opts)
+	var replyErr *ReplyError
+	require.ErrorAs(t, err, &replyErr)
+	assert.Equal(t, "test.event.created", replyErr.Type)
+	assert.Equal(t, "unknown user", replyErr.Message)
+
+	_, err = Request(ctx, bus, newTestEvent(), newTestReplyEvent(), &testPayload{}, opts)
+	require.ErrorAs(t, err, &replyErr)
+	assert.Contains(t, replyErr.Message, "reply payload is required")
+
+	// 响应类型与预期不符
+	_, err = Request(ctx, bus, newTestEvent(), &Event[testReply]{Type: "test.event.other"}, &testPayload{Name: "carol"}, opts)
+	assert.ErrorContains(t, err, "unexpected reply type test.event.replied")
+
+	_, err = Respond[testPayload, testReply](ctx, bus, newTestEvent(), newTestReplyEvent(), "test.event.created", "", nil, nil)
+	assert.Error(t, err)
+}
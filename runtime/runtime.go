@@ -0,0 +1,44 @@
+// Package runtime provides the types and helpers shared by all code generated by protoc-gen-cloudevents
+package runtime
+
+import (
+	"context"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// Publisher is the interface for publishing events
+type Publisher interface {
+	Publish(ctx context.Context, subject string, event *cloudevents.Event) error
+}
+
+// Subscriber is the interface for subscribing to events (broadcast mode)
+// The subscription ends when it is unsubscribed or ctx is done
+type Subscriber interface {
+	Subscribe(ctx context.Context, subject string, handler EventHandler) (Subscription, error)
+}
+
+// HandlerGroupSubscriber is the interface for subscribing to events (handler group mode)
+// The subscription ends when it is unsubscribed or ctx is done
+type HandlerGroupSubscriber interface {
+	SubscribeWithHandlerGroup(ctx context.Context, subject, group string, handler EventHandler) (Subscription, error)
+}
+
+// EventHandler is the function signature for event handlers
+type EventHandler func(context.Context, *cloudevents.Event) error
+
+// Requester is the interface for sending request events and waiting for their reply (request/reply mode)
+type Requester interface {
+	Request(ctx context.Context, subject string, event *cloudevents.Event) (*cloudevents.Event, error)
+}
+
+// Responder is the interface for answering request events (request/reply mode)
+// Responders in the same group compete for requests; with an empty group every responder receives them
+// and the requester gets the first reply. The responder stops when the returned subscription is unsubscribed
+// or ctx is done
+type Responder interface {
+	Respond(ctx context.Context, subject, group string, handler RequestHandler) (Subscription, error)
+}
+
+// RequestHandler is the function signature for request handlers, returning the reply event
+type RequestHandler func(context.Context, *cloudevents.Event) (*cloudevents.Event, error)
@@ -0,0 +1,246 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload struct {
+	Name string `json:"name"`
+}
+
+func newTestEvent() *Event[testPayload] {
+	return &Event[testPayload]{Type: "test.event.created", Codec: JSON}
+}
+
+// fakeBus 记录订阅并同步投递事件
+type fakeBus struct {
+	handlers   map[string][]EventHandler
+	groups     map[string]string
+	responders map[string]RequestHandler
+}
+
+func newFakeBus() *fakeBus {
+	return &fakeBus{
+		handlers:   make(map[string][]EventHandler),
+		groups:     make(map[string]string),
+		responders: make(map[string]RequestHandler),
+	}
+}
+
+func (b *fakeBus) Publish(ctx context.Context, subject string, event *cloudevents.Event) error {
+	for _, h := range b.handlers[subject] {
+		if err := h(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *fakeBus) Subscribe(ctx context.Context, subject string, handler EventHandler) (Subscription, error) {
+	b.handlers[subject] = append(b.handlers[subject], handler)
+	return Subscriptions{}, nil
+}
+
+func (b *fakeBus) SubscribeWithHandlerGroup(ctx context.Context, subject, group string,
+	handler EventHandler) (Subscription, error) {
+	b.groups[subject] = group
+	return b.Subscribe(ctx, subject, handler)
+}
+
+func (b *fakeBus) Request(ctx context.Context, subject string, event *cloudevents.Event) (*cloudevents.Event, error) {
+	handler, ok := b.responders[subject]
+	if !ok {
+		return nil, ErrNoResponders
+	}
+	return handler(ctx, event)
+}
+
+func (b *fakeBus) Respond(ctx context.Context, subject, group string, handler RequestHandler) (Subscription, error) {
+	b.groups[subject] = group
+	b.responders[subject] = handler
+	return Subscriptions{}, nil
+}
+
+// TestBuildEvent_RequiresSource 测试缺少 source 时报错
+func TestBuildEvent_RequiresSource(t *testing.T) {
+	_, _, err := BuildEvent(newTestEvent(), &testPayload{}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "source is required")
+}
+
+// TestBuildEvent_Defaults 测试默认属性
+func TestBuildEvent_Defaults(t *testing.T) {
+	event, subject, err := BuildEvent(newTestEvent(), &testPayload{Name: "alice"},
+		[]PublishOption{WithSource("test/source"), WithExtension("region", "eu"), nil})
+	require.NoError(t, err)
+
+	assert.Equal(t, "test.event.created", subject)
+	assert.Equal(t, "test.event.created", event.Type())
+	assert.Equal(t, "test/source", event.Source())
+	assert.Equal(t, subject, event.Subject())
+	assert.NotEmpty(t, event.ID())
+	assert.False(t, event.Time().IsZero())
+	assert.Equal(t, "eu", event.Extensions()["region"])
+	assert.Equal(t, "application/json", event.DataContentType())
+	assert.JSONEq(t, `{"name":"alice"}`, string(event.Data()))
+}
+
+// TestBuildEvent_WithSubject 测试覆盖 subject
+func TestBuildEvent_WithSubject(t *testing.T) {
+	event, subject, err := BuildEvent(newTestEvent(), nil,
+		[]PublishOption{WithSource("test/source"), WithSubject("test.event.created.eu")})
+	require.NoError(t, err)
+	assert.Equal(t, "test.event.created.eu", subject)
+	assert.Equal(t, subject, event.Subject())
+}
+
+// TestSubscribe_DecodesPayload 测试订阅时解码 payload
+func TestSubscribe_DecodesPayload(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeBus()
+
+	desc := &Event[testPayload]{Type: "test.event.created"}
+
+	var got *testPayload
+	_, err := Subscribe(ctx, bus, desc, "test.event.created", func(ctx context.Context, p *testPayload) error {
+		got = p
+		return nil
+	})
+	require.NoError(t, err)
+
+	event, subject, err := BuildEvent(desc, &testPayload{Name: "bob"},
+		[]PublishOption{WithSource("test/source")})
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, subject, event))
+
+	require.NotNil(t, got)
+	assert.Equal(t, "bob", got.Name)
+}
+
+// TestSubscribe_Options 测试订阅自定义 subject 并跳过其他类型的事件
+func TestSubscribe_Options(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeBus()
+
+	var got []string
+	handler := func(ctx context.Context, p *testPayload) error {
+		got = append(got, p.Name)
+		return nil
+	}
+	_, err := Subscribe(ctx, bus, newTestEvent(), "test.event.created", handler,
+		WithSubscribeSubject("shared.subject"))
+	require.NoError(t, err)
+	_, err = SubscribeWithGroup(ctx, bus, newTestEvent(), "test.event.created", "workers", handler,
+		WithSubjectPrefix("test.event.created"))
+	require.NoError(t, err)
+	assert.Empty(t, bus.handlers["test.event.created"])
+	assert.Equal(t, "workers", bus.groups["test.event.created.>"])
+
+	opts := []PublishOption{WithSource("test/source"), WithSubject("shared.subject")}
+	event, subject, err := BuildEvent(newTestEvent(), &testPayload{Name: "alice"}, opts)
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, subject, event))
+	reply, subject, err := BuildEvent(newTestReplyEvent(), &testReply{Greeting: "hi"}, opts)
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, subject, reply))
+
+	assert.Equal(t, []string{"alice"}, got)
+}
+
+// TestSubscribe_RequiresHandler 测试 handler 为空时报错
+func TestSubscribe_RequiresHandler(t *testing.T) {
+	_, err := Subscribe(context.Background(), newFakeBus(), newTestEvent(), "test.event.created", nil)
+	assert.Error(t, err)
+}
+
+// TestSubscribeWithGroup 测试组模式订阅
+func TestSubscribeWithGroup(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeBus()
+
+	handler := func(ctx context.Context, p *testPayload) error { return nil }
+
+	_, err := SubscribeWithGroup(ctx, bus, newTestEvent(), "test.event.created", "", handler)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "group is required")
+
+	_, err = SubscribeWithGroup(ctx, bus, newTestEvent(), "test.event.created", "workers", handler)
+	require.NoError(t, err)
+	assert.Equal(t, "workers", bus.groups["test.event.created"])
+}
+
+// TestSubscribeEnvelope 测试订阅时获取 CloudEvents 属性和扩展
+func TestSubscribeEnvelope(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeBus()
+	desc := newTestEvent()
+
+	var got *Envelope[testPayload]
+	_, err := SubscribeEnvelope(ctx, bus, desc, "test.event.created",
+		func(ctx context.Context, env *Envelope[testPayload]) error {
+			event, ok := EventFromContext(ctx)
+			require.True(t, ok)
+			assert.Same(t, env.Event, event)
+			got = env
+			return nil
+		})
+	require.NoError(t, err)
+
+	event, subject, err := BuildEvent(desc, &testPayload{Name: "carol"},
+		[]PublishOption{WithSource("test/source"), WithExtension("traceid", "abc"), WithExtension("attempt", 3)})
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, subject, event))
+
+	require.NotNil(t, got)
+	assert.Equal(t, "carol", got.Payload.Name)
+	assert.Equal(t, event.ID(), got.ID)
+	assert.Equal(t, "test/source", got.Source)
+	assert.Equal(t, "test.event.created", got.Type)
+	assert.Equal(t, subject, got.Subject)
+	assert.Equal(t, event.Time(), got.Time)
+	assert.Equal(t, "1.0", got.SpecVersion)
+	assert.Equal(t, "application/json", got.DataContentType)
+
+	traceID, ok := got.ExtensionString("traceid")
+	assert.True(t, ok)
+	assert.Equal(t, "abc", traceID)
+	attempt, ok := got.ExtensionInt("attempt")
+	assert.True(t, ok)
+	assert.Equal(t, int32(3), attempt)
+	_, ok = got.ExtensionString("missing")
+	assert.False(t, ok)
+
+	// 修改信封的扩展不影响事件本身
+	got.Extensions["traceid"] = "changed"
+	assert.Equal(t, "abc", got.Event.Extensions()["traceid"])
+}
+
+// TestSubscribe_EventFromContext 测试普通 handler 通过 ctx 获取事件
+func TestSubscribe_EventFromContext(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeBus()
+	desc := newTestEvent()
+
+	var id string
+	_, err := SubscribeWithGroup(ctx, bus, desc, "test.event.created", "workers",
+		func(ctx context.Context, p *testPayload) error {
+			event, ok := EventFromContext(ctx)
+			require.True(t, ok)
+			id = event.ID()
+			return nil
+		})
+	require.NoError(t, err)
+
+	event, subject, err := BuildEvent(desc, &testPayload{}, []PublishOption{WithSource("test/source")})
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, subject, event))
+	assert.Equal(t, event.ID(), id)
+
+	_, ok := EventFromContext(ctx)
+	assert.False(t, ok)
+}
@@ -0,0 +1,195 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// unmarshalStructJSON decodes data produced by encoding/json from a generated proto struct into m.
+// Such data keys fields by their proto name, except oneofs: they are keyed by their Go name and wrap the set
+// field as {"FieldGoName": value}. Enums are numbers and well-known types such as Timestamp plain messages.
+// Unknown keys are rejected, so that protojson data is not mistaken for it
+func unmarshalStructJSON(data []byte, m protoreflect.Message) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	desc := m.Descriptor()
+	for key, raw := range obj {
+		if isJSONNull(raw) {
+			continue
+		}
+		if fd := desc.Fields().ByName(protoreflect.Name(key)); fd != nil && !isRealOneofField(fd) {
+			if err := setStructJSONField(m, fd, raw); err != nil {
+				return fmt.Errorf("field %s: %w", key, err)
+			}
+			continue
+		}
+
+		oneof := oneofByGoName(desc.Oneofs(), key)
+		if oneof == nil {
+			return fmt.Errorf("unknown field %q in %s", key, desc.FullName())
+		}
+		var wrapper map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &wrapper); err != nil {
+			return fmt.Errorf("oneof %s: %w", key, err)
+		}
+		if len(wrapper) != 1 {
+			return fmt.Errorf("oneof %s: want exactly one field, got %d", key, len(wrapper))
+		}
+		for name, value := range wrapper {
+			fd := fieldByGoName(oneof.Fields(), name)
+			if fd == nil {
+				return fmt.Errorf("oneof %s: unknown field %q", key, name)
+			}
+			if err := setStructJSONField(m, fd, value); err != nil {
+				return fmt.Errorf("field %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func setStructJSONField(m protoreflect.Message, fd protoreflect.FieldDescriptor, raw json.RawMessage) error {
+	switch {
+	case fd.IsList():
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return err
+		}
+		list := m.Mutable(fd).List()
+		for _, item := range items {
+			value, err := structJSONValue(fd, list.NewElement, item)
+			if err != nil {
+				return err
+			}
+			list.Append(value)
+		}
+	case fd.IsMap():
+		var entries map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			return err
+		}
+		mp := m.Mutable(fd).Map()
+		for k, item := range entries {
+			key, err := structJSONMapKey(fd.MapKey(), k)
+			if err != nil {
+				return err
+			}
+			value, err := structJSONValue(fd.MapValue(), mp.NewValue, item)
+			if err != nil {
+				return err
+			}
+			mp.Set(key, value)
+		}
+	case fd.Message() != nil:
+		return unmarshalStructJSON(raw, m.Mutable(fd).Message())
+	default:
+		value, err := structJSONScalar(fd, raw)
+		if err != nil {
+			return err
+		}
+		m.Set(fd, value)
+	}
+	return nil
+}
+
+// structJSONValue decodes a list element or map value of field fd, creating messages with newValue
+func structJSONValue(fd protoreflect.FieldDescriptor, newValue func() protoreflect.Value,
+	raw json.RawMessage) (protoreflect.Value, error) {
+	if fd.Message() == nil {
+		return structJSONScalar(fd, raw)
+	}
+	value := newValue()
+	if isJSONNull(raw) {
+		return value, nil
+	}
+	return value, unmarshalStructJSON(raw, value.Message())
+}
+
+// structJSONMapKey decodes a map key, which encoding/json writes as a string for every key type
+func structJSONMapKey(fd protoreflect.FieldDescriptor, key string) (protoreflect.MapKey, error) {
+	if fd.Kind() == protoreflect.StringKind {
+		return protoreflect.ValueOfString(key).MapKey(), nil
+	}
+	value, err := structJSONScalar(fd, json.RawMessage(key))
+	if err != nil {
+		return protoreflect.MapKey{}, fmt.Errorf("map key %q: %w", key, err)
+	}
+	return value.MapKey(), nil
+}
+
+func structJSONScalar(fd protoreflect.FieldDescriptor, raw json.RawMessage) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return decodeJSONValue(raw, protoreflect.ValueOfBool)
+	case protoreflect.EnumKind:
+		return decodeJSONValue(raw, func(v int32) protoreflect.Value {
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v))
+		})
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return decodeJSONValue(raw, protoreflect.ValueOfInt32)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return decodeJSONValue(raw, protoreflect.ValueOfInt64)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return decodeJSONValue(raw, protoreflect.ValueOfUint32)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return decodeJSONValue(raw, protoreflect.ValueOfUint64)
+	case protoreflect.FloatKind:
+		return decodeJSONValue(raw, protoreflect.ValueOfFloat32)
+	case protoreflect.DoubleKind:
+		return decodeJSONValue(raw, protoreflect.ValueOfFloat64)
+	case protoreflect.StringKind:
+		return decodeJSONValue(raw, protoreflect.ValueOfString)
+	case protoreflect.BytesKind:
+		return decodeJSONValue(raw, protoreflect.ValueOfBytes)
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field kind %s", fd.Kind())
+	}
+}
+
+func decodeJSONValue[T any](raw json.RawMessage, valueOf func(T) protoreflect.Value) (protoreflect.Value, error) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return protoreflect.Value{}, err
+	}
+	return valueOf(v), nil
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// isRealOneofField reports whether fd is part of a oneof declared in proto, as opposed to a proto3 optional field
+func isRealOneofField(fd protoreflect.FieldDescriptor) bool {
+	oneof := fd.ContainingOneof()
+	return oneof != nil && !oneof.IsSynthetic()
+}
+
+// goNameMatches reports whether goName is the Go name protoc-gen-go derives from the proto name
+func goNameMatches(name protoreflect.Name, goName string) bool {
+	return strings.EqualFold(strings.ReplaceAll(string(name), "_", ""), goName)
+}
+
+func oneofByGoName(oneofs protoreflect.OneofDescriptors, goName string) protoreflect.OneofDescriptor {
+	for i := 0; i < oneofs.Len(); i++ {
+		if oneof := oneofs.Get(i); !oneof.IsSynthetic() && goNameMatches(oneof.Name(), goName) {
+			return oneof
+		}
+	}
+	return nil
+}
+
+func fieldByGoName(fields protoreflect.FieldDescriptors, goName string) protoreflect.FieldDescriptor {
+	for i := 0; i < fields.Len(); i++ {
+		if fd := fields.Get(i); goNameMatches(fd.Name(), goName) {
+			return fd
+		}
+	}
+	return nil
+}
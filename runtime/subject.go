@@ -0,0 +1,28 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+)
+
+// subjectReplacer replaces the characters that are not allowed inside a subject token
+var subjectReplacer = strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_", "\t", "_", "\r", "_", "\n", "_")
+
+// SubjectToken formats v as a single subject token for subjects derived from payload fields
+// Characters that would split or wildcard the subject (dots, "*", ">" and whitespace) are replaced by "_",
+// and an empty value is rendered as "_"
+func SubjectToken(v interface{}) string {
+	token := subjectReplacer.Replace(fmt.Sprint(v))
+	if token == "" {
+		return "_"
+	}
+	return token
+}
+
+// SubjectFilterToken formats a subject filter value as a token, using the "*" wildcard for an empty value
+func SubjectFilterToken(v string) string {
+	if v == "" {
+		return "*"
+	}
+	return SubjectToken(v)
+}
@@ -0,0 +1,24 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSubjectToken 测试 subject token 格式化
+func TestSubjectToken(t *testing.T) {
+	assert.Equal(t, "USD", SubjectToken("USD"))
+	assert.Equal(t, "42", SubjectToken(int64(42)))
+	assert.Equal(t, "true", SubjectToken(true))
+	assert.Equal(t, "_", SubjectToken(""))
+	assert.Equal(t, "a_b_c_d", SubjectToken("a.b*c>d"))
+	assert.Equal(t, "new_york", SubjectToken("new york"))
+}
+
+// TestSubjectFilterToken 测试 subject 过滤 token
+func TestSubjectFilterToken(t *testing.T) {
+	assert.Equal(t, "*", SubjectFilterToken(""))
+	assert.Equal(t, "USD", SubjectFilterToken("USD"))
+	assert.Equal(t, "a_b", SubjectFilterToken("a.b"))
+}
@@ -0,0 +1,139 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// SubscribeOption is a functional option for subscribing to events
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	subject     string
+	middlewares []HandlerMiddleware
+}
+
+// WithSubscribeSubject subscribes to subject instead of the event's default subject, e.g. to consume events
+// published with WithSubject. The subject may contain the "*" and ">" wildcards
+func WithSubscribeSubject(subject string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.subject = subject
+	}
+}
+
+// WithSubjectPrefix subscribes to every subject below prefix, i.e. to the "prefix.>" wildcard subject
+// Example: WithSubjectPrefix(EventTypeUserRegistered) also receives events published to "myapp.user.registered.us"
+func WithSubjectPrefix(prefix string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.subject = prefix + ".>"
+	}
+}
+
+// WithHandlerMiddleware wraps the handler of the subscription with middlewares, the first being the outermost
+// They run inside the middlewares of the bus, e.g. of a MiddlewareBus
+func WithHandlerMiddleware(middlewares ...HandlerMiddleware) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// newSubscribeOptions applies opts to the options of a subscription to subject
+func newSubscribeOptions(subject string, opts []SubscribeOption) *subscribeOptions {
+	options := &subscribeOptions{subject: subject}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+	return options
+}
+
+// handler wraps handler with the middlewares of the subscription and skips events of types other than eventType,
+// which may share the subject, before they reach the middlewares
+func (o *subscribeOptions) handler(eventType string, handler EventHandler) EventHandler {
+	handler = ChainHandler(o.middlewares...)(handler)
+	return func(ctx context.Context, event *cloudevents.Event) error {
+		if event.Type() != eventType {
+			return nil
+		}
+		return handler(ctx, event)
+	}
+}
+
+// Subscribe subscribes handler to events of type desc published to subject (broadcast mode),
+// decoding each event's payload with desc.Decode before invoking handler
+// Events of other types arriving on the subject are skipped. The returned Subscription stops the delivery,
+// which also ends once ctx is done
+func Subscribe[T any](ctx context.Context, bus Subscriber, desc *Event[T], subject string,
+	handler func(context.Context, *T) error, opts ...SubscribeOption) (Subscription, error) {
+	if handler == nil {
+		return nil, errors.New("events: handler is required")
+	}
+
+	options := newSubscribeOptions(subject, opts)
+	return bus.Subscribe(ctx, options.subject, options.handler(desc.Type, decodeHandler(desc, payloadHandler(handler))))
+}
+
+// SubscribeEnvelope is like Subscribe but passes handler the payload wrapped in its Envelope
+func SubscribeEnvelope[T any](ctx context.Context, bus Subscriber, desc *Event[T], subject string,
+	handler func(context.Context, *Envelope[T]) error, opts ...SubscribeOption) (Subscription, error) {
+	if handler == nil {
+		return nil, errors.New("events: handler is required")
+	}
+
+	options := newSubscribeOptions(subject, opts)
+	return bus.Subscribe(ctx, options.subject, options.handler(desc.Type, decodeHandler(desc, handler)))
+}
+
+// SubscribeWithGroup subscribes handler to events of type desc published to subject (handler group mode),
+// decoding each event's payload with desc.Decode before invoking handler
+// Events of other types arriving on the subject are skipped
+func SubscribeWithGroup[T any](ctx context.Context, bus HandlerGroupSubscriber,
+	desc *Event[T], subject, group string, handler func(context.Context, *T) error, opts ...SubscribeOption) (Subscription, error) {
+	if handler == nil {
+		return nil, errors.New("events: handler is required")
+	}
+	if group == "" {
+		return nil, errors.New("events: group is required")
+	}
+
+	options := newSubscribeOptions(subject, opts)
+	return bus.SubscribeWithHandlerGroup(ctx, options.subject, group,
+		options.handler(desc.Type, decodeHandler(desc, payloadHandler(handler))))
+}
+
+// SubscribeEnvelopeWithGroup is like SubscribeWithGroup but passes handler the payload wrapped in its Envelope
+func SubscribeEnvelopeWithGroup[T any](ctx context.Context, bus HandlerGroupSubscriber,
+	desc *Event[T], subject, group string, handler func(context.Context, *Envelope[T]) error,
+	opts ...SubscribeOption) (Subscription, error) {
+	if handler == nil {
+		return nil, errors.New("events: handler is required")
+	}
+	if group == "" {
+		return nil, errors.New("events: group is required")
+	}
+
+	options := newSubscribeOptions(subject, opts)
+	return bus.SubscribeWithHandlerGroup(ctx, options.subject, group, options.handler(desc.Type, decodeHandler(desc, handler)))
+}
+
+// decodeHandler adapts handler to an EventHandler decoding the payload of each event with desc.
+// The event is made available to handler through EventFromContext
+func decodeHandler[T any](desc *Event[T], handler func(context.Context, *Envelope[T]) error) EventHandler {
+	return func(eventCtx context.Context, event *cloudevents.Event) error {
+		eventCtx = ContextWithEvent(eventCtx, event)
+		payload, err := desc.Decode(eventCtx, event)
+		if err != nil {
+			return err
+		}
+		return handler(eventCtx, NewEnvelope(event, payload))
+	}
+}
+
+func payloadHandler[T any](handler func(context.Context, *T) error) func(context.Context, *Envelope[T]) error {
+	return func(ctx context.Context, envelope *Envelope[T]) error {
+		return handler(ctx, envelope.Payload)
+	}
+}
@@ -0,0 +1,45 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+)
+
+// Subscription is the handle of a subscription returned by every subscribe call
+// Transports unsubscribe automatically once the context passed to the subscribe call is done
+type Subscription interface {
+	// Unsubscribe stops delivering events to the handler, dropping the events not yet handled
+	// Unsubscribing a subscription that is already closed is a no-op
+	Unsubscribe() error
+
+	// Drain stops receiving new events and returns once the events already received are handled,
+	// or with the error of ctx once it is done, leaving the remaining events to be handled in the background
+	// It must not be called from the handler of the subscription itself
+	Drain(ctx context.Context) error
+}
+
+// Subscriptions is a group of subscriptions closed together,
+// e.g. the subscriptions of a generated RegisterXxxEventHandlers call
+type Subscriptions []Subscription
+
+// Unsubscribe unsubscribes every subscription of s, returning the joined errors
+func (s Subscriptions) Unsubscribe() error {
+	var errs []error
+	for _, sub := range s {
+		if err := sub.Unsubscribe(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Drain drains every subscription of s until ctx is done, returning the joined errors
+func (s Subscriptions) Drain(ctx context.Context) error {
+	var errs []error
+	for _, sub := range s {
+		if err := sub.Drain(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
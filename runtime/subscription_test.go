@@ -0,0 +1,43 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingSubscription 记录取消订阅和排空的次数
+type countingSubscription struct {
+	unsubscribed, drained int
+	err                   error
+}
+
+func (s *countingSubscription) Unsubscribe() error {
+	s.unsubscribed++
+	return s.err
+}
+
+func (s *countingSubscription) Drain(ctx context.Context) error {
+	s.drained++
+	return s.err
+}
+
+// TestSubscriptions 测试订阅组关闭所有订阅并合并错误
+func TestSubscriptions(t *testing.T) {
+	failed := errors.New("boom")
+	first, second := &countingSubscription{err: failed}, &countingSubscription{}
+	subs := Subscriptions{first, second}
+
+	assert.ErrorIs(t, subs.Unsubscribe(), failed)
+	assert.Equal(t, 1, first.unsubscribed)
+	assert.Equal(t, 1, second.unsubscribed)
+
+	first.err = nil
+	assert.NoError(t, subs.Drain(context.Background()))
+	assert.Equal(t, 1, first.drained)
+	assert.Equal(t, 1, second.drained)
+
+	assert.NoError(t, Subscriptions(nil).Unsubscribe())
+}
@@ -0,0 +1,106 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Violation is a field rule a payload does not satisfy
+type Violation struct {
+	// Field is the path of the field, e.g. "order_id", "items[2]" or "customer.email"
+	Field string
+	// Rule is the violated rule: required, pattern, min, max, min_items, max_items or defined_only
+	Rule string
+	// Message describes the violation
+	Message string
+}
+
+// ValidationError is the error returned by generated Validate methods, listing every violation of the payload
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("validation failed: ")
+	for i, v := range e.Violations {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(v.Field)
+		b.WriteString(": ")
+		b.WriteString(v.Message)
+	}
+	return b.String()
+}
+
+// Violations collects the violations found by a generated Validate method
+type Violations []Violation
+
+// Add records a violation of rule by field
+func (v *Violations) Add(field, rule, message string) {
+	*v = append(*v, Violation{Field: field, Rule: rule, Message: message})
+}
+
+// Nested validates the message value of field if it has a Validate method,
+// recording its violations with their field path prefixed by field
+func (v *Violations) Nested(field string, msg any) {
+	val, ok := msg.(validator)
+	if !ok {
+		return
+	}
+	err := val.Validate()
+	if err == nil {
+		return
+	}
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		v.Add(field, "", err.Error())
+		return
+	}
+	for _, nested := range verr.Violations {
+		nested.Field = field + "." + nested.Field
+		*v = append(*v, nested)
+	}
+}
+
+// Err returns a *ValidationError listing the violations, nil if there are none
+func (v Violations) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: v}
+}
+
+// Index returns the path of the element i of the repeated field
+func Index(field string, i int) string {
+	return field + "[" + strconv.Itoa(i) + "]"
+}
+
+// Key returns the path of the value of key in the map field
+func Key(field string, key any) string {
+	return fmt.Sprintf("%s[%v]", field, key)
+}
+
+// EnumDefined reports whether e is a value declared by its enum
+func EnumDefined(e protoreflect.Enum) bool {
+	return e.Descriptor().Values().ByNumber(e.Number()) != nil
+}
+
+// validator is implemented by payloads with a generated Validate method
+type validator interface {
+	Validate() error
+}
+
+// validatePayload runs the generated Validate method of payload, if any
+func validatePayload(payload any) error {
+	if v, ok := payload.(validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
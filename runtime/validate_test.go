@@ -0,0 +1,94 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type validatedPayload struct {
+	Name  string            `json:"name"`
+	Child *validatedPayload `json:"child,omitempty"`
+}
+
+// Validate 模拟生成的 Validate 方法
+func (x *validatedPayload) Validate() error {
+	if x == nil {
+		return nil
+	}
+	var violations Violations
+	if x.Name == "" {
+		violations.Add("name", "required", "value is required")
+	}
+	violations.Nested("child", x.Child)
+	return violations.Err()
+}
+
+// TestViolations 测试违规收集与嵌套字段路径
+func TestViolations(t *testing.T) {
+	err := (&validatedPayload{Child: &validatedPayload{}}).Validate()
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []Violation{
+		{Field: "name", Rule: "required", Message: "value is required"},
+		{Field: "child.name", Rule: "required", Message: "value is required"},
+	}, verr.Violations)
+	assert.Equal(t, "validation failed: name: value is required; child.name: value is required", err.Error())
+
+	assert.NoError(t, (&validatedPayload{Name: "a"}).Validate())
+	assert.NoError(t, (*validatedPayload)(nil).Validate())
+
+	var violations Violations
+	violations.Nested(Index("items", 2), failingValidator{})
+	violations.Nested(Key("byid", "x"), "not a message")
+	assert.Equal(t, []Violation{{Field: "items[2]", Message: "boom"}}, []Violation(violations))
+}
+
+type failingValidator struct{}
+
+func (failingValidator) Validate() error { return errors.New("boom") }
+
+// TestEnumDefined 测试枚举值是否已定义
+func TestEnumDefined(t *testing.T) {
+	assert.True(t, EnumDefined(structpb.NullValue_NULL_VALUE))
+	assert.False(t, EnumDefined(structpb.NullValue(42)))
+}
+
+// TestBuildEvent_Validates 测试发布前校验载荷
+func TestBuildEvent_Validates(t *testing.T) {
+	desc := &Event[validatedPayload]{Type: "test.event.validated", Codec: JSON}
+	_, _, err := BuildEvent(desc, &validatedPayload{}, []PublishOption{WithSource("test/source")})
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Contains(t, err.Error(), "invalid payload for test.event.validated")
+}
+
+// TestSubscribe_Validates 测试解码后校验载荷
+func TestSubscribe_Validates(t *testing.T) {
+	ctx := context.Background()
+	bus := newFakeBus()
+	desc := &Event[validatedPayload]{Type: "test.event.validated", Codec: JSON}
+
+	called := false
+	_, err := Subscribe(ctx, bus, desc, desc.Type, func(ctx context.Context, payload *validatedPayload) error {
+		called = true
+		return nil
+	})
+	require.NoError(t, err)
+
+	event := cloudevents.NewEvent()
+	event.SetType(desc.Type)
+	event.SetSource("test/source")
+	require.NoError(t, event.SetData(cloudevents.ApplicationJSON, []byte(`{"child":{"name":""}}`)))
+
+	err = bus.Publish(ctx, desc.Type, &event)
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Len(t, verr.Violations, 2)
+	assert.False(t, called)
+}
@@ -10,6 +10,9 @@ PROJECT_ROOT="$(cd "${SCRIPT_DIR}/.." && pwd)"
 
 cd "${PROJECT_ROOT}"
 
+# 生成代码按 go_package 输出到模块内对应目录
+GO_MODULE="github.com/yafeiaa/protoc-gen-cloudevents-go"
+
 echo "🚀 开始生成 CloudEvents 代码..."
 
 # 默认生成所有示例
@@ -25,15 +28,13 @@ for PROTO_FILE in "${PROTO_FILES[@]}"; do
     echo ""
     echo "📦 处理: ${PROTO_FILE}"
     
-    OUTPUT_DIR="$(dirname "${PROTO_FILE}")"
-    
     # 生成 protobuf 基础代码
     echo "  └─ 生成 protobuf 消息定义..."
     protoc \
         -I . \
         -I ./proto \
-        --go_out="${OUTPUT_DIR}" \
-        --go_opt=paths=source_relative \
+        --go_out=. \
+        --go_opt=module="${GO_MODULE}" \
         "${PROTO_FILE}"
     
     # 生成 CloudEvents 代码
@@ -41,8 +42,8 @@ for PROTO_FILE in "${PROTO_FILES[@]}"; do
     protoc \
         -I . \
         -I ./proto \
-        --cloudevents_out="${OUTPUT_DIR}" \
-        --cloudevents_opt=paths=source_relative \
+        --cloudevents_out=. \
+        --cloudevents_opt=module="${GO_MODULE}",emit_test_recorder=true \
         "${PROTO_FILE}"
     
     echo "  ✅ 完成"
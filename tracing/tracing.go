@@ -0,0 +1,148 @@
+// Package tracing propagates OpenTelemetry trace context through the CloudEvents distributed tracing extension
+// and creates the producer and consumer spans of the transports
+// See https://github.com/cloudevents/spec/blob/main/cloudevents/extensions/distributed-tracing.md
+package tracing
+
+import (
+	"context"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ExtensionTraceParent is the CloudEvents extension carrying the W3C traceparent of the event
+	ExtensionTraceParent = "traceparent"
+	// ExtensionTraceState is the CloudEvents extension carrying the W3C tracestate of the event
+	ExtensionTraceState = "tracestate"
+)
+
+// ScopeName is the instrumentation scope of the spans created by the transports
+const ScopeName = "github.com/yafeiaa/protoc-gen-cloudevents-go/tracing"
+
+// propagator reads and writes the W3C trace context, the only format of the distributed tracing extension
+var propagator = propagation.TraceContext{}
+
+// Carrier adapts the distributed tracing extension of a CloudEvent to a propagation.TextMapCarrier
+type Carrier struct {
+	Event *cloudevents.Event
+}
+
+var _ propagation.TextMapCarrier = Carrier{}
+
+// Get returns the value of the extension key, or "" if the event does not carry it
+func (c Carrier) Get(key string) string {
+	v, ok := c.Event.Extensions()[key]
+	if !ok {
+		return ""
+	}
+	s, err := types.ToString(v)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// Set sets the extension key to value
+func (c Carrier) Set(key, value string) {
+	c.Event.SetExtension(key, value)
+}
+
+// Keys returns the trace context extensions carried by the event
+func (c Carrier) Keys() []string {
+	var keys []string
+	for _, key := range []string{ExtensionTraceParent, ExtensionTraceState} {
+		if _, ok := c.Event.Extensions()[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Inject writes the trace context of ctx into the traceparent and tracestate extensions of event
+// Event is left unchanged if ctx carries no valid span context
+func Inject(ctx context.Context, event *cloudevents.Event) {
+	propagator.Inject(ctx, Carrier{Event: event})
+}
+
+// Extract returns ctx with the remote span context carried by the traceparent and tracestate extensions of event
+func Extract(ctx context.Context, event *cloudevents.Event) context.Context {
+	return propagator.Extract(ctx, Carrier{Event: event})
+}
+
+// Tracer creates the producer and consumer spans of a transport
+type Tracer struct {
+	system string
+	tracer trace.Tracer
+}
+
+// NewTracer creates a Tracer for the messaging system (e.g. "nats") with the spans of provider,
+// or of the global tracer provider if provider is nil
+func NewTracer(system string, provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracer{system: system, tracer: provider.Tracer(ScopeName)}
+}
+
+// StartPublish starts the producer span of publishing event to subject. It returns event itself,
+// or a copy of it carrying the trace context of the span, which must be published instead
+func (t *Tracer) StartPublish(ctx context.Context, subject string,
+	event *cloudevents.Event) (context.Context, *cloudevents.Event, trace.Span) {
+	ctx, span := t.tracer.Start(ctx, "publish "+subject,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(t.attributes("publish", subject, "", event)...),
+		trace.WithAttributes(semconv.MessagingOperationTypeSend))
+	if span.SpanContext().IsValid() {
+		clone := event.Clone()
+		Inject(ctx, &clone)
+		event = &clone
+	}
+	return ctx, event, span
+}
+
+// StartProcess starts the consumer span of handling event received on subject by a subscriber of group,
+// empty in broadcast mode. The span is a child of the trace context carried by event
+func (t *Tracer) StartProcess(ctx context.Context, subject, group string,
+	event *cloudevents.Event) (context.Context, trace.Span) {
+	return t.tracer.Start(Extract(ctx, event), "process "+subject,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(t.attributes("process", subject, group, event)...),
+		trace.WithAttributes(semconv.MessagingOperationTypeProcess))
+}
+
+// attributes returns the messaging and CloudEvents attributes of a span
+func (t *Tracer) attributes(operation, subject, group string, event *cloudevents.Event) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKey.String(t.system),
+		semconv.MessagingOperationName(operation),
+		semconv.MessagingDestinationName(subject),
+		semconv.MessagingMessageID(event.ID()),
+		semconv.CloudeventsEventID(event.ID()),
+		semconv.CloudeventsEventSource(event.Source()),
+		semconv.CloudeventsEventType(event.Type()),
+		semconv.CloudeventsEventSpecVersion(event.SpecVersion()),
+	}
+	if event.Subject() != "" {
+		attrs = append(attrs, semconv.CloudeventsEventSubject(event.Subject()))
+	}
+	if group != "" {
+		attrs = append(attrs, semconv.MessagingConsumerGroupName(group))
+	}
+	return attrs
+}
+
+// End records err on span, if any, and ends span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
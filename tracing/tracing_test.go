@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestEvent() *cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID("event-1")
+	event.SetType("test.event.created")
+	event.SetSource("test/source")
+	return &event
+}
+
+// TestInjectExtract 测试 trace context 通过 traceparent/tracestate 扩展传递
+func TestInjectExtract(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	state, err := trace.ParseTraceState("vendor=value")
+	require.NoError(t, err)
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		TraceState: state,
+	}))
+
+	event := newTestEvent()
+	Inject(context.Background(), event)
+	assert.Empty(t, Carrier{Event: event}.Keys())
+
+	Inject(ctx, event)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", event.Extensions()[ExtensionTraceParent])
+	assert.Equal(t, "vendor=value", event.Extensions()[ExtensionTraceState])
+	assert.ElementsMatch(t, []string{ExtensionTraceParent, ExtensionTraceState}, Carrier{Event: event}.Keys())
+
+	// 经过 JSON 序列化后仍可提取
+	data, err := event.MarshalJSON()
+	require.NoError(t, err)
+	var received cloudevents.Event
+	require.NoError(t, received.UnmarshalJSON(data))
+	sc := trace.SpanContextFromContext(Extract(context.Background(), &received))
+	assert.True(t, sc.IsRemote())
+	assert.Equal(t, traceID, sc.TraceID())
+	assert.Equal(t, spanID, sc.SpanID())
+	assert.Equal(t, "vendor=value", sc.TraceState().String())
+}
+
+// TestTracer 测试生产者与消费者 span 的父子关系和属性
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := NewTracer("test", sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	event := newTestEvent()
+	ctx, published, span := tracer.StartPublish(context.Background(), "test.subject", event)
+	End(span, nil)
+	assert.NotSame(t, event, published)
+	assert.NotContains(t, event.Extensions(), ExtensionTraceParent)
+	assert.Contains(t, published.Extensions(), ExtensionTraceParent)
+	assert.Equal(t, span.SpanContext(), trace.SpanContextFromContext(ctx))
+
+	_, process := tracer.StartProcess(context.Background(), "test.subject", "workers", published)
+	End(process, assert.AnError)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	producer, consumer := spans[0], spans[1]
+	assert.Equal(t, "publish test.subject", producer.Name())
+	assert.Equal(t, trace.SpanKindProducer, producer.SpanKind())
+	assert.Equal(t, "process test.subject", consumer.Name())
+	assert.Equal(t, trace.SpanKindConsumer, consumer.SpanKind())
+	assert.Equal(t, producer.SpanContext().TraceID(), consumer.SpanContext().TraceID())
+	assert.Equal(t, producer.SpanContext().SpanID(), consumer.Parent().SpanID())
+	assert.Equal(t, "Error", consumer.Status().Code.String())
+
+	attrs := make(map[string]string)
+	for _, attr := range consumer.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	assert.Equal(t, "test", attrs["messaging.system"])
+	assert.Equal(t, "test.subject", attrs["messaging.destination.name"])
+	assert.Equal(t, "workers", attrs["messaging.consumer.group.name"])
+	assert.Equal(t, "process", attrs["messaging.operation.type"])
+	assert.Equal(t, "test.event.created", attrs["cloudevents.event_type"])
+	assert.Equal(t, "event-1", attrs["cloudevents.event_id"])
+}
@@ -4,50 +4,173 @@ package memory
 import (
 	"context"
 	"fmt"
-	"path/filepath"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/runtime"
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/tracing"
 )
 
-// EventHandler 事件处理函数
-type EventHandler func(context.Context, *cloudevents.Event) error
+var (
+	_ runtime.Publisher              = (*MemoryBus)(nil)
+	_ runtime.Subscriber             = (*MemoryBus)(nil)
+	_ runtime.HandlerGroupSubscriber = (*MemoryBus)(nil)
+	_ runtime.Requester              = (*MemoryBus)(nil)
+	_ runtime.Responder              = (*MemoryBus)(nil)
+)
 
 // MemoryBus is an in-memory event bus implementation
 type MemoryBus struct {
 	mu         sync.RWMutex
-	handlers   map[string][]EventHandler
-	groups     map[string]map[string][]EventHandler // subject -> group -> handlers
-	groupIndex map[string]map[string]int            // subject -> group -> current index
+	handlers   map[string][]*subscription
+	groups     map[string]map[string][]*subscription // subject -> group -> subscriptions
+	groupIndex map[string]map[string]int             // subject -> group -> current index
+	responders []*subscription
+	respIndex  map[string]int // group -> current responder index
+	tracer     *tracing.Tracer
 }
 
-// matchSubject checks if a subject matches a pattern with wildcards
-// Supports "*" wildcard matching (e.g., "app.*.created" matches "app.user.created")
-func matchSubject(pattern, subject string) bool {
-	// If pattern doesn't contain wildcards, use exact match
-	if !strings.Contains(pattern, "*") {
-		return pattern == subject
+// Option configures a MemoryBus
+type Option func(*memoryOptions)
+
+type memoryOptions struct {
+	tracerProvider trace.TracerProvider
+}
+
+// WithTracerProvider creates the producer and consumer spans of the bus with provider
+// instead of the global tracer provider
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *memoryOptions) {
+		o.tracerProvider = provider
+	}
+}
+
+// subscription is an event handler registered with Subscribe or SubscribeWithHandlerGroup,
+// or a request handler registered with Respond
+type subscription struct {
+	bus      *MemoryBus
+	ctx      context.Context
+	subject  string
+	group    string
+	handler  runtime.EventHandler
+	replier  runtime.RequestHandler // set for responders
+	closed   atomic.Bool
+	inFlight sync.WaitGroup
+	stop     func() bool // stops unsubscribing when ctx is done
+}
+
+// active reports whether events are still delivered to s
+func (s *subscription) active() bool {
+	return !s.closed.Load() && s.ctx.Err() == nil
+}
+
+// deliver invokes the handler of s with event published to subject, within a consumer span,
+// unless s was unsubscribed in the meantime
+func (s *subscription) deliver(ctx context.Context, subject string, event *cloudevents.Event) {
+	defer s.inFlight.Done()
+	if !s.active() {
+		return
+	}
+
+	ctx, span := s.bus.tracer.StartProcess(ctx, subject, s.group, event)
+	// Handle errors but continue processing other handlers
+	tracing.End(span, s.handler(ctx, event))
+}
+
+// cancel stops delivering events to s and removes it from the bus
+func (s *subscription) cancel() {
+	s.closed.Store(true)
+	s.bus.remove(s)
+}
+
+// Unsubscribe removes the subscription from the bus, dropping the events not yet handled
+func (s *subscription) Unsubscribe() error {
+	s.stop()
+	s.cancel()
+	return nil
+}
+
+// Drain removes the subscription from the bus and waits until the events already published to it are handled
+// or ctx is done
+func (s *subscription) Drain(ctx context.Context) error {
+	s.stop()
+	s.bus.remove(s)
+
+	handled := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(handled)
+	}()
+	select {
+	case <-handled:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("memory: drain subscription: %w", ctx.Err())
+	}
+}
+
+// respond invokes the request handler of s with the request event sent to subject, within a consumer span,
+// unless s was unsubscribed in the meantime, which is reported by ok being false
+func (s *subscription) respond(ctx context.Context, subject string,
+	event *cloudevents.Event) (reply *cloudevents.Event, ok bool, err error) {
+	defer s.inFlight.Done()
+	if !s.active() {
+		return nil, false, nil
 	}
 
-	// Convert NATS-style wildcards to filepath-style for filepath.Match
-	// NATS uses * for single-level matching and > for multi-level
-	filePattern := strings.ReplaceAll(pattern, "*", "*")
-	
-	// Use filepath.Match which supports * wildcards
-	matched, err := filepath.Match(filePattern, subject)
-	if err != nil {
-		return false
+	ctx, span := s.bus.tracer.StartProcess(ctx, subject, s.group, event)
+	reply, err = s.replier(ctx, event)
+	tracing.End(span, err)
+	return reply, true, err
+}
+
+// matchSubject checks if a subject matches a pattern with wildcards, token by token
+// Supports "*" matching exactly one token (e.g., "app.*.created" matches "app.user.created" but not
+// "app.user.eu.created") and a trailing ">" matching one or more tokens (e.g., "app.user.>" matches
+// "app.user.created.us"). Wildcards only apply to whole tokens, "app.user*" is matched literally
+func matchSubject(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, tok := range patternTokens {
+		if tok == ">" && i == len(patternTokens)-1 {
+			return len(subjectTokens) > i && subjectTokens[i] != ""
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if tok == "*" {
+			if subjectTokens[i] == "" {
+				return false
+			}
+			continue
+		}
+		if tok != subjectTokens[i] {
+			return false
+		}
 	}
-	return matched
+	return len(patternTokens) == len(subjectTokens)
 }
 
 // NewMemoryBus creates a new in-memory event bus
-func NewMemoryBus() *MemoryBus {
+// The bus propagates the trace context of published events to their handlers and creates producer and consumer
+// spans with the global tracer provider, unless configured otherwise with opts
+func NewMemoryBus(opts ...Option) *MemoryBus {
+	options := &memoryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
 	return &MemoryBus{
-		handlers:   make(map[string][]EventHandler),
-		groups:     make(map[string]map[string][]EventHandler),
+		handlers:   make(map[string][]*subscription),
+		groups:     make(map[string]map[string][]*subscription),
 		groupIndex: make(map[string]map[string]int),
+		respIndex:  make(map[string]int),
+		tracer:     tracing.NewTracer("memory", options.tracerProvider),
 	}
 }
 
@@ -61,81 +184,212 @@ func (b *MemoryBus) Publish(ctx context.Context, subject string, event *cloudeve
 		return fmt.Errorf("event is required")
 	}
 
-	b.mu.RLock()
-	defer b.mu.RUnlock()
+	ctx, event, span := b.tracer.StartPublish(ctx, subject, event)
+	defer span.End()
+
+	// Select the subscriptions under the lock, then invoke their handlers without holding it
+	// so that handlers may publish, subscribe and unsubscribe
+	b.mu.Lock()
+	var targets []*subscription
 
 	// Broadcast to all broadcast-mode subscribers with matching subjects
-	for pattern, handlers := range b.handlers {
+	for pattern, subs := range b.handlers {
 		if matchSubject(pattern, subject) {
-			for _, handler := range handlers {
-				// Handle errors but continue processing other handlers
-				_ = handler(ctx, event)
+			for _, s := range subs {
+				if s.active() {
+					targets = append(targets, s)
+				}
 			}
 		}
 	}
 
 	// Handler group mode (load balancing) with wildcard support
-	for pattern, groupHandlers := range b.groups {
+	for pattern, groupSubs := range b.groups {
 		if matchSubject(pattern, subject) {
-			for group, handlers := range groupHandlers {
-				if len(handlers) == 0 {
+			for group, subs := range groupSubs {
+				subs = slices.DeleteFunc(slices.Clone(subs), func(s *subscription) bool { return !s.active() })
+				if len(subs) == 0 {
 					continue
 				}
 
-				// Round-robin handler selection
-				index := b.groupIndex[pattern][group] % len(handlers)
-				handler := handlers[index]
-				b.groupIndex[pattern][group]++
-
-				// Handle errors but continue processing other handlers
-				_ = handler(ctx, event)
+				// Events with a partition key always go to the same handler, others are selected round-robin
+				var index int
+				if key, ok := runtime.PartitionKey(event); ok {
+					index = runtime.PartitionIndex(key, len(subs))
+				} else {
+					index = b.groupIndex[pattern][group] % len(subs)
+					b.groupIndex[pattern][group]++
+				}
+				targets = append(targets, subs[index])
 			}
 		}
 	}
 
+	for _, s := range targets {
+		s.inFlight.Add(1)
+	}
+	b.mu.Unlock()
+
+	for _, s := range targets {
+		s.deliver(ctx, subject, event)
+	}
+
 	return nil
 }
 
 // Subscribe subscribes to events (broadcast mode)
-func (b *MemoryBus) Subscribe(ctx context.Context, subject string, handler EventHandler) error {
+// The subscription ends when it is unsubscribed or ctx is done
+func (b *MemoryBus) Subscribe(ctx context.Context, subject string, handler runtime.EventHandler) (runtime.Subscription, error) {
 	if subject == "" {
-		return fmt.Errorf("subject is required")
+		return nil, fmt.Errorf("subject is required")
 	}
 	if handler == nil {
-		return fmt.Errorf("handler is required")
+		return nil, fmt.Errorf("handler is required")
 	}
 
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	b.handlers[subject] = append(b.handlers[subject], handler)
-	return nil
+	s := b.newSubscription(ctx, subject, "", handler)
+	b.handlers[subject] = append(b.handlers[subject], s)
+	return s, nil
 }
 
 // SubscribeWithHandlerGroup subscribes to events (handler group mode)
-func (b *MemoryBus) SubscribeWithHandlerGroup(ctx context.Context, subject, group string, handler EventHandler) error {
+// Events with the same partition key are delivered to the same handler of the group, in publish order,
+// as long as the group membership does not change
+// The subscription ends when it is unsubscribed or ctx is done
+func (b *MemoryBus) SubscribeWithHandlerGroup(ctx context.Context, subject, group string,
+	handler runtime.EventHandler) (runtime.Subscription, error) {
 	if subject == "" {
-		return fmt.Errorf("subject is required")
+		return nil, fmt.Errorf("subject is required")
 	}
 	if group == "" {
-		return fmt.Errorf("group is required")
+		return nil, fmt.Errorf("group is required")
 	}
 	if handler == nil {
-		return fmt.Errorf("handler is required")
+		return nil, fmt.Errorf("handler is required")
 	}
 
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	if b.groups[subject] == nil {
-		b.groups[subject] = make(map[string][]EventHandler)
+		b.groups[subject] = make(map[string][]*subscription)
 	}
 	if b.groupIndex[subject] == nil {
 		b.groupIndex[subject] = make(map[string]int)
 	}
 
-	b.groups[subject][group] = append(b.groups[subject][group], handler)
-	return nil
+	s := b.newSubscription(ctx, subject, group, handler)
+	b.groups[subject][group] = append(b.groups[subject][group], s)
+	return s, nil
+}
+
+// newSubscription creates a subscription unsubscribed once ctx is done
+// The bus lock must be held, so that ctx cannot remove the subscription before it is registered
+func (b *MemoryBus) newSubscription(ctx context.Context, subject, group string,
+	handler runtime.EventHandler) *subscription {
+	s := &subscription{bus: b, ctx: ctx, subject: subject, group: group, handler: handler}
+	s.stop = context.AfterFunc(ctx, s.cancel)
+	return s
+}
+
+// remove removes s from the bus. Removing a subscription twice is a no-op
+func (b *MemoryBus) remove(s *subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	isS := func(other *subscription) bool { return other == s }
+	if s.replier != nil {
+		b.responders = slices.DeleteFunc(b.responders, isS)
+		return
+	}
+	if s.group == "" {
+		if subs := slices.DeleteFunc(b.handlers[s.subject], isS); len(subs) > 0 {
+			b.handlers[s.subject] = subs
+		} else {
+			delete(b.handlers, s.subject)
+		}
+		return
+	}
+	if groups := b.groups[s.subject]; groups != nil {
+		if subs := slices.DeleteFunc(groups[s.group], isS); len(subs) > 0 {
+			groups[s.group] = subs
+		} else {
+			delete(groups, s.group)
+		}
+	}
+}
+
+// Request sends a request event to the responders with a matching subject and returns the first reply
+// Each group of responders receives the request once, its responders being selected round-robin,
+// and responders without a group all receive it. Responders are invoked synchronously, those without
+// a group first, in registration order, then the groups in name order
+func (b *MemoryBus) Request(ctx context.Context, subject string, event *cloudevents.Event) (*cloudevents.Event, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("subject is required")
+	}
+	if event == nil {
+		return nil, fmt.Errorf("event is required")
+	}
+
+	b.mu.Lock()
+	var targets []*subscription
+	members := make(map[string][]*subscription)
+	for _, r := range b.responders {
+		if !r.active() || !matchSubject(r.subject, subject) {
+			continue
+		}
+		if r.group == "" {
+			targets = append(targets, r)
+		} else {
+			members[r.group] = append(members[r.group], r)
+		}
+	}
+	for _, group := range slices.Sorted(maps.Keys(members)) {
+		targets = append(targets, members[group][b.respIndex[group]%len(members[group])])
+		b.respIndex[group]++
+	}
+	for _, r := range targets {
+		r.inFlight.Add(1)
+	}
+	b.mu.Unlock()
+
+	ctx, event, span := b.tracer.StartPublish(ctx, subject, event)
+	var (
+		reply    *cloudevents.Event
+		err      = runtime.ErrNoResponders
+		answered bool
+	)
+	for _, r := range targets {
+		resp, ok, respErr := r.respond(ctx, subject, event)
+		if ok && !answered {
+			reply, err, answered = resp, respErr, true
+		}
+	}
+	tracing.End(span, err)
+	return reply, err
+}
+
+// Respond registers handler to answer requests sent to subject
+// The responder is removed when it is unsubscribed or ctx is done
+func (b *MemoryBus) Respond(ctx context.Context, subject, group string,
+	handler runtime.RequestHandler) (runtime.Subscription, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("subject is required")
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("handler is required")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.newSubscription(ctx, subject, group, nil)
+	s.replier = handler
+	b.responders = append(b.responders, s)
+	return s, nil
 }
 
 // Close closes the event bus
@@ -143,8 +397,25 @@ func (b *MemoryBus) Close(ctx context.Context) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	b.handlers = make(map[string][]EventHandler)
-	b.groups = make(map[string]map[string][]EventHandler)
+	for _, subs := range b.handlers {
+		for _, s := range subs {
+			s.stop()
+		}
+	}
+	for _, groupSubs := range b.groups {
+		for _, subs := range groupSubs {
+			for _, s := range subs {
+				s.stop()
+			}
+		}
+	}
+	for _, s := range b.responders {
+		s.stop()
+	}
+	b.handlers = make(map[string][]*subscription)
+	b.groups = make(map[string]map[string][]*subscription)
 	b.groupIndex = make(map[string]map[string]int)
+	b.responders = nil
+	b.respIndex = make(map[string]int)
 	return nil
 }
@@ -3,12 +3,19 @@ package memory
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/runtime"
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/tracing"
 )
 
 // TestNewMemoryBus 测试创建内存总线
@@ -64,7 +71,7 @@ func TestSubscribe_SimpleHandler(t *testing.T) {
 		return nil
 	}
 
-	err := bus.Subscribe(ctx, "test.subject", handler)
+	_, err := bus.Subscribe(ctx, "test.subject", handler)
 	assert.NoError(t, err)
 
 	// 发布事件
@@ -105,9 +112,9 @@ func TestSubscribe_MultipleHandlers(t *testing.T) {
 	}
 
 	// 订阅同一主题
-	err := bus.Subscribe(ctx, "test.multi", handler1)
+	_, err := bus.Subscribe(ctx, "test.multi", handler1)
 	assert.NoError(t, err)
-	err = bus.Subscribe(ctx, "test.multi", handler2)
+	_, err = bus.Subscribe(ctx, "test.multi", handler2)
 	assert.NoError(t, err)
 
 	// 发布事件
@@ -147,7 +154,7 @@ func TestSubscribe_WildcardPattern(t *testing.T) {
 	}
 
 	// 订阅通配符主题
-	err := bus.Subscribe(ctx, "app.*.created", handler)
+	_, err := bus.Subscribe(ctx, "app.*.created", handler)
 	assert.NoError(t, err)
 
 	// 发布匹配的事件
@@ -190,7 +197,7 @@ func TestSubscribe_NilHandler(t *testing.T) {
 	bus := NewMemoryBus()
 	ctx := context.Background()
 
-	err := bus.Subscribe(ctx, "test.subject", nil)
+	_, err := bus.Subscribe(ctx, "test.subject", nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "handler is required")
 }
@@ -206,7 +213,7 @@ func TestClose_StopsDelivery(t *testing.T) {
 		return nil
 	}
 
-	err := bus.Subscribe(ctx, "test.close", handler)
+	_, err := bus.Subscribe(ctx, "test.close", handler)
 	require.NoError(t, err)
 
 	// 关闭总线
@@ -254,7 +261,7 @@ func TestConcurrentPublishSubscribe(t *testing.T) {
 			received <- event
 			return nil
 		}
-		err := bus.Subscribe(ctx, "concurrent.test", handler)
+		_, err := bus.Subscribe(ctx, "concurrent.test", handler)
 		require.NoError(t, err)
 	}
 
@@ -309,9 +316,9 @@ func TestHandlerError_DoesNotAffectOthers(t *testing.T) {
 		return nil
 	}
 
-	err := bus.Subscribe(ctx, "test.error", handler1)
+	_, err := bus.Subscribe(ctx, "test.error", handler1)
 	require.NoError(t, err)
-	err = bus.Subscribe(ctx, "test.error", handler2)
+	_, err = bus.Subscribe(ctx, "test.error", handler2)
 	require.NoError(t, err)
 
 	// 发布事件
@@ -355,7 +362,7 @@ func BenchmarkSubscribeAndPublish(b *testing.B) {
 		return nil
 	}
 
-	_ = bus.Subscribe(ctx, "bench.subject", handler)
+	_, _ = bus.Subscribe(ctx, "bench.subject", handler)
 
 	event := cloudevents.NewEvent()
 	event.SetID("bench-test")
@@ -366,3 +373,355 @@ func BenchmarkSubscribeAndPublish(b *testing.B) {
 		_ = bus.Publish(ctx, "bench.subject", &event)
 	}
 }
+
+// TestRequest_RoundRobin 测试请求在匹配的响应方之间轮询
+func TestRequest_RoundRobin(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx := context.Background()
+
+	event := cloudevents.NewEvent()
+	event.SetType("test.request")
+	event.SetSource("test/source")
+
+	_, err := bus.Request(ctx, "test.request", &event)
+	assert.ErrorIs(t, err, runtime.ErrNoResponders)
+
+	responder := func(name string) runtime.RequestHandler {
+		return func(ctx context.Context, event *cloudevents.Event) (*cloudevents.Event, error) {
+			reply := cloudevents.NewEvent()
+			reply.SetType("test.reply")
+			reply.SetSource(name)
+			return &reply, nil
+		}
+	}
+	for _, r := range []struct{ subject, name string }{
+		{"test.*", "first"}, {"test.request", "second"}, {"other.request", "other"},
+	} {
+		_, err := bus.Respond(ctx, r.subject, "workers", responder(r.name))
+		require.NoError(t, err)
+	}
+
+	var sources []string
+	for i := 0; i < 3; i++ {
+		reply, err := bus.Request(ctx, "test.request", &event)
+		require.NoError(t, err)
+		sources = append(sources, reply.Source())
+	}
+	assert.Equal(t, []string{"first", "second", "first"}, sources)
+
+	_, err = bus.Respond(ctx, "test.request", "", nil)
+	assert.Error(t, err)
+	_, err = bus.Request(ctx, "test.request", nil)
+	assert.Error(t, err)
+}
+
+// TestRequest_Groups 测试每个组收到一次请求、无组响应方都收到请求并返回第一个响应
+func TestRequest_Groups(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx := context.Background()
+
+	event := cloudevents.NewEvent()
+	event.SetType("test.request")
+	event.SetSource("test/source")
+
+	var calls []string
+	responder := func(name string) runtime.RequestHandler {
+		return func(ctx context.Context, event *cloudevents.Event) (*cloudevents.Event, error) {
+			calls = append(calls, name)
+			reply := cloudevents.NewEvent()
+			reply.SetType("test.reply")
+			reply.SetSource(name)
+			return &reply, nil
+		}
+	}
+	for _, r := range []struct{ group, name string }{
+		{"workers", "worker-1"}, {"workers", "worker-2"}, {"", "audit"}, {"billing", "billing-1"}, {"", "cache"},
+	} {
+		_, err := bus.Respond(ctx, "test.request", r.group, responder(r.name))
+		require.NoError(t, err)
+	}
+
+	reply, err := bus.Request(ctx, "test.request", &event)
+	require.NoError(t, err)
+	assert.Equal(t, "audit", reply.Source())
+	assert.Equal(t, []string{"audit", "cache", "billing-1", "worker-1"}, calls)
+
+	calls = nil
+	_, err = bus.Request(ctx, "test.request", &event)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"audit", "cache", "billing-1", "worker-2"}, calls)
+}
+
+// TestRespond_Unsubscribe 测试取消响应方或其 context 后不再响应请求
+func TestRespond_Unsubscribe(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx := context.Background()
+
+	event := cloudevents.NewEvent()
+	event.SetType("test.request")
+	event.SetSource("test/source")
+
+	handler := func(ctx context.Context, event *cloudevents.Event) (*cloudevents.Event, error) {
+		reply := cloudevents.NewEvent()
+		reply.SetType("test.reply")
+		reply.SetSource("test/responder")
+		return &reply, nil
+	}
+
+	respondCtx, cancel := context.WithCancel(ctx)
+	_, err := bus.Respond(respondCtx, "test.request", "", handler)
+	require.NoError(t, err)
+	_, err = bus.Request(ctx, "test.request", &event)
+	require.NoError(t, err)
+
+	cancel()
+	_, err = bus.Request(ctx, "test.request", &event)
+	assert.ErrorIs(t, err, runtime.ErrNoResponders)
+
+	sub, err := bus.Respond(ctx, "test.request", "workers", handler)
+	require.NoError(t, err)
+	_, err = bus.Request(ctx, "test.request", &event)
+	require.NoError(t, err)
+
+	require.NoError(t, sub.Unsubscribe())
+	require.NoError(t, sub.Unsubscribe())
+	_, err = bus.Request(ctx, "test.request", &event)
+	assert.ErrorIs(t, err, runtime.ErrNoResponders)
+	assert.Eventually(t, func() bool {
+		bus.mu.RLock()
+		defer bus.mu.RUnlock()
+		return len(bus.responders) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestHandlerGroup_PartitionKey 测试相同分区键的事件投递给同一组成员
+func TestHandlerGroup_PartitionKey(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx := context.Background()
+
+	received := make([][]string, 3)
+	for i := range received {
+		i := i
+		_, err := bus.SubscribeWithHandlerGroup(ctx, "test.event", "workers",
+			func(ctx context.Context, event *cloudevents.Event) error {
+				received[i] = append(received[i], event.ID())
+				return nil
+			})
+		require.NoError(t, err)
+	}
+
+	for _, key := range []string{"a", "b", "c", "a", "b", "c", "a"} {
+		event := cloudevents.NewEvent()
+		event.SetID(key)
+		event.SetType("test.event")
+		event.SetSource("test/source")
+		event.SetExtension(runtime.ExtensionPartitionKey, key)
+		require.NoError(t, bus.Publish(ctx, "test.event", &event))
+	}
+
+	members := make(map[string]int)
+	for i, ids := range received {
+		for _, id := range ids {
+			if member, ok := members[id]; ok {
+				assert.Equal(t, member, i, "events with key %s reached different members", id)
+			}
+			members[id] = i
+		}
+	}
+	assert.Len(t, members, 3)
+}
+
+// TestMatchSubject_FullWildcard 测试末尾 ">" 通配符匹配一个或多个 token
+func TestMatchSubject_FullWildcard(t *testing.T) {
+	tests := []struct {
+		pattern, subject string
+		want             bool
+	}{
+		{"app.user.>", "app.user.created", true},
+		{"app.user.>", "app.user.created.us", true},
+		{"app.user.>", "app.user", false},
+		{"app.user.>", "app.order.created", false},
+		{"app.*.>", "app.order.created.us", true},
+		{">", "app", true},
+		{"app.user>", "app.user.created", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchSubject(tt.pattern, tt.subject), "%s ~ %s", tt.pattern, tt.subject)
+	}
+}
+
+// TestSubscription_Unsubscribe 测试取消订阅后不再投递事件，且可在 handler 中取消
+func TestSubscription_Unsubscribe(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx := context.Background()
+
+	event := cloudevents.NewEvent()
+	event.SetID("event-1")
+	event.SetType("test.event")
+	event.SetSource("test/source")
+
+	var count, groupCount int
+	var sub runtime.Subscription
+	sub, err := bus.Subscribe(ctx, "test.event", func(ctx context.Context, event *cloudevents.Event) error {
+		count++
+		return sub.Unsubscribe()
+	})
+	require.NoError(t, err)
+	groupSub, err := bus.SubscribeWithHandlerGroup(ctx, "test.event", "workers",
+		func(ctx context.Context, event *cloudevents.Event) error {
+			groupCount++
+			return nil
+		})
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(ctx, "test.event", &event))
+	require.NoError(t, groupSub.Unsubscribe())
+	require.NoError(t, bus.Publish(ctx, "test.event", &event))
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1, groupCount)
+
+	// 重复取消订阅是无操作
+	assert.NoError(t, sub.Unsubscribe())
+	assert.NoError(t, groupSub.Drain(ctx))
+	assert.Empty(t, bus.handlers)
+	assert.Empty(t, bus.groups["test.event"])
+}
+
+// TestSubscription_ContextCancel 测试 ctx 取消后自动取消订阅
+func TestSubscription_ContextCancel(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	received := 0
+	_, err := bus.Subscribe(ctx, "test.event", func(ctx context.Context, event *cloudevents.Event) error {
+		received++
+		return nil
+	})
+	require.NoError(t, err)
+
+	event := cloudevents.NewEvent()
+	event.SetID("event-1")
+	event.SetType("test.event")
+	event.SetSource("test/source")
+
+	cancel()
+	require.NoError(t, bus.Publish(context.Background(), "test.event", &event))
+	assert.Equal(t, 0, received)
+	assert.Eventually(t, func() bool {
+		bus.mu.RLock()
+		defer bus.mu.RUnlock()
+		return len(bus.handlers) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestSubscription_Drain 测试 Drain 等待处理中的事件完成，或在 ctx 结束时返回
+func TestSubscription_Drain(t *testing.T) {
+	bus := NewMemoryBus()
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var handled atomic.Bool
+	sub, err := bus.SubscribeWithHandlerGroup(ctx, "test.event", "workers",
+		func(ctx context.Context, event *cloudevents.Event) error {
+			close(started)
+			<-release
+			handled.Store(true)
+			return nil
+		})
+	require.NoError(t, err)
+
+	event := cloudevents.NewEvent()
+	event.SetID("event-1")
+	event.SetType("test.event")
+	event.SetSource("test/source")
+	go func() { _ = bus.Publish(ctx, "test.event", &event) }()
+	<-started
+
+	// Drain 在 ctx 结束时放弃等待
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, sub.Drain(timeoutCtx), context.DeadlineExceeded)
+	assert.False(t, handled.Load())
+
+	drained := make(chan error)
+	go func() { drained <- sub.Drain(ctx) }()
+	select {
+	case <-drained:
+		t.Fatal("drain returned before the event was handled")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-drained)
+	assert.True(t, handled.Load())
+}
+
+// TestTracing 测试发布时注入 trace context，处理时提取并创建生产者/消费者 span
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	bus := NewMemoryBus(WithTracerProvider(provider))
+
+	var handlerSpan trace.SpanContext
+	var traceParent interface{}
+	_, err := bus.SubscribeWithHandlerGroup(context.Background(), "test.*", "workers",
+		func(ctx context.Context, event *cloudevents.Event) error {
+			handlerSpan = trace.SpanContextFromContext(ctx)
+			traceParent = event.Extensions()[tracing.ExtensionTraceParent]
+			return nil
+		})
+	require.NoError(t, err)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	event := cloudevents.NewEvent()
+	event.SetID("event-1")
+	event.SetType("test.event")
+	event.SetSource("test/source")
+	require.NoError(t, bus.Publish(ctx, "test.event", &event))
+	parent.End()
+
+	assert.NotContains(t, event.Extensions(), tracing.ExtensionTraceParent)
+	assert.NotNil(t, traceParent)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	consumer, producer := spans[0], spans[1]
+	assert.Equal(t, "process test.event", consumer.Name())
+	assert.Equal(t, "publish test.event", producer.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), producer.Parent().SpanID())
+	assert.Equal(t, producer.SpanContext().SpanID(), consumer.Parent().SpanID())
+	assert.Equal(t, parent.SpanContext().TraceID(), consumer.SpanContext().TraceID())
+	assert.Equal(t, consumer.SpanContext(), handlerSpan)
+}
+
+// TestMatchSubject_SingleWildcard 测试 "*" 通配符只匹配一个完整的 token
+func TestMatchSubject_SingleWildcard(t *testing.T) {
+	tests := []struct {
+		pattern, subject string
+		want             bool
+	}{
+		{"app.user.created", "app.user.created", true},
+		{"app.user.created", "app.user.deleted", false},
+		{"app.*.created", "app.user.created", true},
+		{"app.*.created", "app.user.eu.created", false},
+		{"app.*.created", "app.created", false},
+		{"app.*.created", "app..created", false},
+		{"app.*", "app.user", true},
+		{"app.*", "app.user.created", false},
+		{"app.*", "app", false},
+		{"*", "app", true},
+		{"*", "app.user", false},
+		{"*.*.created", "app.user.created", true},
+		{"*.*.created", "app.user.eu.created", false},
+		{"app.*.*.us", "app.user.created.us", true},
+		{"app.*.*.us", "app.user.created.eu.us", false},
+		{"app.user*", "app.user", false},
+		{"app.user*", "app.user*", true},
+		{"app.*.created.>", "app.user.created.eu.us", true},
+		{"app.*.created.>", "app.user.eu.created.us", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchSubject(tt.pattern, tt.subject), "%s ~ %s", tt.pattern, tt.subject)
+	}
+}
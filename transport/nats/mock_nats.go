@@ -6,6 +6,8 @@ import (
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/nats-io/nats.go"
+
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/runtime"
 )
 
 // MockConn 模拟 NATS 连接
@@ -181,6 +183,12 @@ func (s *MockSub) Unsubscribe() error {
 	return nil
 }
 
+var (
+	_ runtime.Publisher              = (*MockNATSBus)(nil)
+	_ runtime.Subscriber             = (*MockNATSBus)(nil)
+	_ runtime.HandlerGroupSubscriber = (*MockNATSBus)(nil)
+)
+
 // MockNATSBus 模拟 NATS 总线
 type MockNATSBus struct {
 	conn *MockConn
@@ -200,15 +208,15 @@ func (b *MockNATSBus) Publish(ctx context.Context, subject string, event *cloude
 }
 
 // Subscribe 订阅事件
-func (b *MockNATSBus) Subscribe(ctx context.Context, subject string, handler EventHandler) error {
-	// 对于模拟，我们简化实现
-	return nil
+func (b *MockNATSBus) Subscribe(ctx context.Context, subject string, handler runtime.EventHandler) (runtime.Subscription, error) {
+	// 对于模拟，我们简化实现，返回空的订阅句柄
+	return runtime.Subscriptions{}, nil
 }
 
 // SubscribeWithHandlerGroup 订阅事件（组模式）
-func (b *MockNATSBus) SubscribeWithHandlerGroup(ctx context.Context, subject, group string, handler EventHandler) error {
-	// 对于模拟，我们简化实现
-	return nil
+func (b *MockNATSBus) SubscribeWithHandlerGroup(ctx context.Context, subject, group string, handler runtime.EventHandler) (runtime.Subscription, error) {
+	// 对于模拟，我们简化实现，返回空的订阅句柄
+	return runtime.Subscriptions{}, nil
 }
 
 // Close 关闭总线
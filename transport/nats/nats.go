@@ -4,21 +4,33 @@ package nats
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/runtime"
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/tracing"
 )
 
-// EventHandler is the function signature for event handlers
-type EventHandler func(context.Context, *cloudevents.Event) error
+var (
+	_ runtime.Publisher              = (*NATSBus)(nil)
+	_ runtime.Subscriber             = (*NATSBus)(nil)
+	_ runtime.HandlerGroupSubscriber = (*NATSBus)(nil)
+	_ runtime.Requester              = (*NATSBus)(nil)
+	_ runtime.Responder              = (*NATSBus)(nil)
+)
 
 // NATSBus implements an event bus using NATS messaging system
 type NATSBus struct {
 	conn          *nats.Conn
 	subscriptions []*nats.Subscription
 	mu            sync.Mutex
+	tracer        *tracing.Tracer
 }
 
 // Config holds the configuration for NATS connection
@@ -28,6 +40,10 @@ type Config struct {
 
 	// Options allows customizing the NATS connection
 	Options []nats.Option
+
+	// TracerProvider creates the producer and consumer spans of the bus (defaults to the global tracer provider)
+	// The trace context of published events is propagated to their handlers in any case
+	TracerProvider trace.TracerProvider
 }
 
 // NewNATSBus creates a new NATS event bus with the given configuration
@@ -44,6 +60,7 @@ func NewNATSBus(cfg Config) (*NATSBus, error) {
 	return &NATSBus{
 		conn:          conn,
 		subscriptions: make([]*nats.Subscription, 0),
+		tracer:        tracing.NewTracer("nats", cfg.TracerProvider),
 	}, nil
 }
 
@@ -53,6 +70,13 @@ func (b *NATSBus) Publish(ctx context.Context, subject string, event *cloudevent
 		return fmt.Errorf("nats: connection is closed")
 	}
 
+	_, event, span := b.tracer.StartPublish(ctx, subject, event)
+	err := b.publish(subject, event)
+	tracing.End(span, err)
+	return err
+}
+
+func (b *NATSBus) publish(subject string, event *cloudevents.Event) error {
 	// Serialize CloudEvents to JSON
 	data, err := json.Marshal(event)
 	if err != nil {
@@ -69,66 +93,201 @@ func (b *NATSBus) Publish(ctx context.Context, subject string, event *cloudevent
 
 // Subscribe subscribes to events on a subject (broadcast mode)
 // All subscribers with the same subject will receive all messages
-func (b *NATSBus) Subscribe(ctx context.Context, subject string, handler EventHandler) error {
+// The subscription is unsubscribed when ctx is done
+func (b *NATSBus) Subscribe(ctx context.Context, subject string, handler runtime.EventHandler) (runtime.Subscription, error) {
 	if b.conn == nil || b.conn.IsClosed() {
-		return fmt.Errorf("nats: connection is closed")
+		return nil, fmt.Errorf("nats: connection is closed")
 	}
 
-	sub, err := b.conn.Subscribe(subject, func(msg *nats.Msg) {
+	sub, err := b.conn.Subscribe(subject, b.eventCallback(ctx, "", handler))
+	if err != nil {
+		return nil, fmt.Errorf("nats: failed to subscribe: %w", err)
+	}
+
+	return b.track(ctx, sub), nil
+}
+
+// SubscribeWithHandlerGroup subscribes to events using a queue group (handler group mode)
+// Messages are load-balanced across subscribers in the same group
+// Core NATS queue groups do not honour partition keys: events with the same key may reach different members
+// The subscription is unsubscribed when ctx is done
+func (b *NATSBus) SubscribeWithHandlerGroup(ctx context.Context, subject, group string,
+	handler runtime.EventHandler) (runtime.Subscription, error) {
+	if b.conn == nil || b.conn.IsClosed() {
+		return nil, fmt.Errorf("nats: connection is closed")
+	}
+
+	if group == "" {
+		return nil, fmt.Errorf("nats: group name is required")
+	}
+
+	sub, err := b.conn.QueueSubscribe(subject, group, b.eventCallback(ctx, group, handler))
+	if err != nil {
+		return nil, fmt.Errorf("nats: failed to queue subscribe: %w", err)
+	}
+
+	return b.track(ctx, sub), nil
+}
+
+// eventCallback returns the NATS callback decoding each message into an event and invoking handler
+// within a consumer span
+func (b *NATSBus) eventCallback(ctx context.Context, group string, handler runtime.EventHandler) nats.MsgHandler {
+	return func(msg *nats.Msg) {
 		var event cloudevents.Event
 		if err := json.Unmarshal(msg.Data, &event); err != nil {
 			// Log error but don't stop processing
 			return
 		}
 
-		if err := handler(ctx, &event); err != nil {
-			// Log error but don't stop processing
-			return
-		}
-	})
-	if err != nil {
-		return fmt.Errorf("nats: failed to subscribe: %w", err)
+		// Errors are recorded on the span but don't stop processing
+		ctx, span := b.tracer.StartProcess(ctx, msg.Subject, group, &event)
+		tracing.End(span, handler(ctx, &event))
 	}
+}
+
+// subscription is the handle of a NATS subscription, unsubscribed when the context of the subscribe call is done
+type subscription struct {
+	bus  *NATSBus
+	sub  *nats.Subscription
+	stop func() bool // stops unsubscribing when ctx is done
+}
 
+// track registers sub to be closed with the bus and returns its handle, unsubscribed once ctx is done
+func (b *NATSBus) track(ctx context.Context, sub *nats.Subscription) *subscription {
 	b.mu.Lock()
 	b.subscriptions = append(b.subscriptions, sub)
 	b.mu.Unlock()
 
+	s := &subscription{bus: b, sub: sub}
+	s.stop = context.AfterFunc(ctx, func() { _ = s.unsubscribe() })
+	return s
+}
+
+// untrack forgets sub once it is closed
+func (b *NATSBus) untrack(sub *nats.Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscriptions = slices.DeleteFunc(b.subscriptions, func(other *nats.Subscription) bool { return other == sub })
+}
+
+// Unsubscribe removes interest in the subject, dropping the messages not yet handled
+func (s *subscription) Unsubscribe() error {
+	s.stop()
+	return s.unsubscribe()
+}
+
+func (s *subscription) unsubscribe() error {
+	s.bus.untrack(s.sub)
+	if !s.sub.IsValid() {
+		return nil
+	}
+	if err := s.sub.Unsubscribe(); err != nil && !errors.Is(err, nats.ErrBadSubscription) {
+		return fmt.Errorf("nats: failed to unsubscribe: %w", err)
+	}
 	return nil
 }
 
-// SubscribeWithHandlerGroup subscribes to events using a queue group (handler group mode)
-// Messages are load-balanced across subscribers in the same group
-func (b *NATSBus) SubscribeWithHandlerGroup(ctx context.Context, subject, group string, handler EventHandler) error {
+// Drain removes interest in the subject and waits until the messages already received are handled or ctx is done
+func (s *subscription) Drain(ctx context.Context) error {
+	s.stop()
+	s.bus.untrack(s.sub)
+	if !s.sub.IsValid() {
+		return nil
+	}
+	closed := s.sub.StatusChanged(nats.SubscriptionClosed)
+	if err := s.sub.Drain(); err != nil {
+		if errors.Is(err, nats.ErrBadSubscription) {
+			return nil
+		}
+		return fmt.Errorf("nats: failed to drain subscription: %w", err)
+	}
+	select {
+	case <-closed:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("nats: drain subscription: %w", ctx.Err())
+	}
+}
+
+// Request publishes a request event to NATS and waits for the reply until ctx is done
+// Returns runtime.ErrNoResponders if no subscriber listens on the subject
+func (b *NATSBus) Request(ctx context.Context, subject string, event *cloudevents.Event) (*cloudevents.Event, error) {
 	if b.conn == nil || b.conn.IsClosed() {
-		return fmt.Errorf("nats: connection is closed")
+		return nil, fmt.Errorf("nats: connection is closed")
 	}
 
-	if group == "" {
-		return fmt.Errorf("nats: group name is required")
+	ctx, event, span := b.tracer.StartPublish(ctx, subject, event)
+	reply, err := b.request(ctx, subject, event)
+	tracing.End(span, err)
+	return reply, err
+}
+
+func (b *NATSBus) request(ctx context.Context, subject string, event *cloudevents.Event) (*cloudevents.Event, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("nats: failed to marshal event: %w", err)
+	}
+
+	msg, err := b.conn.RequestWithContext(ctx, subject, data)
+	if err != nil {
+		if errors.Is(err, nats.ErrNoResponders) {
+			return nil, runtime.ErrNoResponders
+		}
+		return nil, fmt.Errorf("nats: request failed: %w", err)
+	}
+
+	var reply cloudevents.Event
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		return nil, fmt.Errorf("nats: failed to unmarshal reply: %w", err)
+	}
+	return &reply, nil
+}
+
+// Respond subscribes handler to requests on a subject and sends its reply through msg.Respond
+// With a group, requests are load-balanced across responders of the group (queue group)
+// The responder is unsubscribed when ctx is done
+func (b *NATSBus) Respond(ctx context.Context, subject, group string,
+	handler runtime.RequestHandler) (runtime.Subscription, error) {
+	if b.conn == nil || b.conn.IsClosed() {
+		return nil, fmt.Errorf("nats: connection is closed")
 	}
 
-	sub, err := b.conn.QueueSubscribe(subject, group, func(msg *nats.Msg) {
+	cb := func(msg *nats.Msg) {
 		var event cloudevents.Event
 		if err := json.Unmarshal(msg.Data, &event); err != nil {
 			// Log error but don't stop processing
 			return
 		}
 
-		if err := handler(ctx, &event); err != nil {
-			// Log error but don't stop processing
+		ctx, span := b.tracer.StartProcess(ctx, msg.Subject, group, &event)
+		reply, err := handler(ctx, &event)
+		tracing.End(span, err)
+		if err != nil || reply == nil {
+			// The requester times out
 			return
 		}
-	})
-	if err != nil {
-		return fmt.Errorf("nats: failed to queue subscribe: %w", err)
+		data, err := json.Marshal(reply)
+		if err != nil {
+			return
+		}
+		_ = msg.Respond(data)
 	}
 
-	b.mu.Lock()
-	b.subscriptions = append(b.subscriptions, sub)
-	b.mu.Unlock()
+	var (
+		sub *nats.Subscription
+		err error
+	)
+	if group == "" {
+		sub, err = b.conn.Subscribe(subject, cb)
+	} else {
+		sub, err = b.conn.QueueSubscribe(subject, group, cb)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("nats: failed to subscribe responder: %w", err)
+	}
 
-	return nil
+	return b.track(ctx, sub), nil
 }
 
 // Close closes all subscriptions and the NATS connection
@@ -1,11 +1,14 @@
 package nats
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/nats-io/nats.go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestMockConnBasic 测试 MockConn 的基本功能
@@ -116,6 +119,15 @@ func TestMockNATSBusBasic(t *testing.T) {
 	bus := NewMockNATSBus()
 	assert.NotNil(t, bus)
 	assert.False(t, bus.conn.IsClosed())
+
+	// 订阅返回可取消的订阅句柄
+	handler := func(ctx context.Context, event *cloudevents.Event) error { return nil }
+	sub, err := bus.Subscribe(context.Background(), "test.subject", handler)
+	require.NoError(t, err)
+	assert.NoError(t, sub.Unsubscribe())
+	sub, err = bus.SubscribeWithHandlerGroup(context.Background(), "test.subject", "workers", handler)
+	require.NoError(t, err)
+	assert.NoError(t, sub.Drain(context.Background()))
 }
 
 // TestMockNATSBusDrain 测试模拟总线的排空功能
@@ -9,6 +9,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/yafeiaa/protoc-gen-cloudevents-go/runtime"
 )
 
 // Note: These tests require a running NATS server
@@ -58,8 +63,10 @@ func TestPublishSubscribe_BroadcastMode(t *testing.T) {
 		return nil
 	}
 
-	require.NoError(t, bus.Subscribe(ctx, subject, handler))
-	require.NoError(t, bus.Subscribe(ctx, subject, handler))
+	_, err = bus.Subscribe(ctx, subject, handler)
+	require.NoError(t, err)
+	_, err = bus.Subscribe(ctx, subject, handler)
+	require.NoError(t, err)
 
 	// Give subscriptions time to be ready
 	time.Sleep(100 * time.Millisecond)
@@ -108,8 +115,10 @@ func TestPublishSubscribe_HandlerGroupMode(t *testing.T) {
 		return nil
 	}
 
-	require.NoError(t, bus.SubscribeWithHandlerGroup(ctx, subject, group, handler))
-	require.NoError(t, bus.SubscribeWithHandlerGroup(ctx, subject, group, handler))
+	_, err = bus.SubscribeWithHandlerGroup(ctx, subject, group, handler)
+	require.NoError(t, err)
+	_, err = bus.SubscribeWithHandlerGroup(ctx, subject, group, handler)
+	require.NoError(t, err)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -161,8 +170,10 @@ func TestPublishSubscribe_MultipleGroups(t *testing.T) {
 		return nil
 	}
 
-	require.NoError(t, bus.SubscribeWithHandlerGroup(ctx, subject, "group1", handler1))
-	require.NoError(t, bus.SubscribeWithHandlerGroup(ctx, subject, "group2", handler2))
+	_, err = bus.SubscribeWithHandlerGroup(ctx, subject, "group1", handler1)
+	require.NoError(t, err)
+	_, err = bus.SubscribeWithHandlerGroup(ctx, subject, "group2", handler2)
+	require.NoError(t, err)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -206,7 +217,8 @@ func TestClose(t *testing.T) {
 		return nil
 	}
 
-	require.NoError(t, bus.Subscribe(ctx, subject, handler))
+	_, err = bus.Subscribe(ctx, subject, handler)
+	require.NoError(t, err)
 	time.Sleep(100 * time.Millisecond)
 
 	// Close the bus
@@ -233,11 +245,168 @@ func TestSubscribe_EmptyGroup(t *testing.T) {
 		return nil
 	}
 
-	err = bus.SubscribeWithHandlerGroup(ctx, "test.subject", "", handler)
+	_, err = bus.SubscribeWithHandlerGroup(ctx, "test.subject", "", handler)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "group name is required")
 }
 
+func TestSubscription_UnsubscribeAndCancel(t *testing.T) {
+	bus, err := NewNATSBus(Config{URL: testNATSURL})
+	if err != nil {
+		t.Skipf("NATS server not available: %v", err)
+		return
+	}
+	defer bus.Close(context.Background())
+
+	subject := "test.unsubscribe." + uuid.New().String()
+	receivedCh := make(chan *cloudevents.Event, 2)
+	handler := func(ctx context.Context, event *cloudevents.Event) error {
+		receivedCh <- event
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = bus.Subscribe(ctx, subject, handler)
+	require.NoError(t, err)
+	sub, err := bus.SubscribeWithHandlerGroup(context.Background(), subject, "workers", handler)
+	require.NoError(t, err)
+
+	cancel()
+	require.NoError(t, sub.Unsubscribe())
+	assert.NoError(t, sub.Unsubscribe())
+	assert.Eventually(t, func() bool {
+		bus.mu.Lock()
+		defer bus.mu.Unlock()
+		return len(bus.subscriptions) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	event := cloudevents.NewEvent()
+	event.SetID(uuid.New().String())
+	event.SetType("test.event")
+	event.SetSource("test")
+	require.NoError(t, bus.Publish(context.Background(), subject, &event))
+
+	select {
+	case <-receivedCh:
+		t.Fatal("received an event after unsubscribing")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSubscription_DrainDeadline(t *testing.T) {
+	bus, err := NewNATSBus(Config{URL: testNATSURL})
+	if err != nil {
+		t.Skipf("NATS server not available: %v", err)
+		return
+	}
+	defer bus.Close(context.Background())
+
+	subject := "test.drain." + uuid.New().String()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	sub, err := bus.SubscribeWithHandlerGroup(context.Background(), subject, "workers",
+		func(ctx context.Context, event *cloudevents.Event) error {
+			close(started)
+			<-release
+			return nil
+		})
+	require.NoError(t, err)
+
+	event := cloudevents.NewEvent()
+	event.SetID(uuid.New().String())
+	event.SetType("test.event")
+	event.SetSource("test")
+	require.NoError(t, bus.Publish(context.Background(), subject, &event))
+	<-started
+
+	// Drain gives up once ctx is done while the handler is still running
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, sub.Drain(ctx), context.DeadlineExceeded)
+
+	close(release)
+}
+
+func TestRespond_Unsubscribe(t *testing.T) {
+	bus, err := NewNATSBus(Config{URL: testNATSURL})
+	if err != nil {
+		t.Skipf("NATS server not available: %v", err)
+		return
+	}
+	defer bus.Close(context.Background())
+
+	subject := "test.respond." + uuid.New().String()
+	handler := func(ctx context.Context, event *cloudevents.Event) (*cloudevents.Event, error) {
+		reply := cloudevents.NewEvent()
+		reply.SetID(uuid.New().String())
+		reply.SetType("test.reply")
+		reply.SetSource("test")
+		return &reply, nil
+	}
+
+	respondCtx, cancel := context.WithCancel(context.Background())
+	_, err = bus.Respond(respondCtx, subject, "", handler)
+	require.NoError(t, err)
+	sub, err := bus.Respond(context.Background(), subject, "workers", handler)
+	require.NoError(t, err)
+
+	event := cloudevents.NewEvent()
+	event.SetID(uuid.New().String())
+	event.SetType("test.request")
+	event.SetSource("test")
+	requestCtx, requestCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer requestCancel()
+	_, err = bus.Request(requestCtx, subject, &event)
+	require.NoError(t, err)
+
+	cancel()
+	require.NoError(t, sub.Unsubscribe())
+	assert.Eventually(t, func() bool {
+		bus.mu.Lock()
+		defer bus.mu.Unlock()
+		return len(bus.subscriptions) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = bus.Request(requestCtx, subject, &event)
+	assert.ErrorIs(t, err, runtime.ErrNoResponders)
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	bus, err := NewNATSBus(Config{URL: testNATSURL, TracerProvider: provider})
+	if err != nil {
+		t.Skipf("NATS server not available: %v", err)
+		return
+	}
+	defer bus.Close(context.Background())
+
+	subject := "test.tracing." + uuid.New().String()
+	spanCh := make(chan trace.SpanContext, 1)
+	_, err = bus.Subscribe(context.Background(), subject, func(ctx context.Context, event *cloudevents.Event) error {
+		spanCh <- trace.SpanContextFromContext(ctx)
+		return nil
+	})
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	event := cloudevents.NewEvent()
+	event.SetID(uuid.New().String())
+	event.SetType("test.event")
+	event.SetSource("test")
+	require.NoError(t, bus.Publish(ctx, subject, &event))
+	parent.End()
+
+	select {
+	case sc := <-spanCh:
+		assert.Equal(t, parent.SpanContext().TraceID(), sc.TraceID())
+		assert.NotEqual(t, parent.SpanContext().SpanID(), sc.SpanID())
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+}
+
 func TestDrain(t *testing.T) {
 	ctx := context.Background()
 	bus, err := NewNATSBus(Config{URL: testNATSURL})